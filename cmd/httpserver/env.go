@@ -3,8 +3,19 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/excel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/locale"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/recipient"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/source"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	ticketdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/ticket/downloader"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
 )
 
 // Config contains all the configuration variables
@@ -16,10 +27,29 @@ type Config struct {
 	HTTPExternalLocationAddress  string
 	HTTPShutdownTimeoutInSeconds int
 
-	// Assertion token - to get the auth token for calls to external services
-	AssertionToken         string
-	AssertionTokenEndpoint string
-	AssertionTokenOrg      string
+	// Transport of all outbound clients (ITSM, token service, Postmark)
+	OutboundTransport client.TransportConfig
+
+	// Exporter of the OpenTelemetry spans - none, otlp or stdout
+	TracesExporter tracing.Exporter
+
+	// Mode of the ITSM clients - live, record (calls ITSM and records the exchanges) or replay (no calls to ITSM)
+	ITSMClientMode client.Mode
+	// Dir of the fixture files of the recorded ITSM exchanges
+	ITSMFixturesDir string
+
+	// Auth provider of the calls to external services with its configuration
+	Auth client.AuthConfig
+
+	// Source of the channels, users and tickets - itsm, file (export files) or rest (generic REST API)
+	Source source.Kind
+	// Dir of the export files of the file source
+	SourceFileDir string
+	// Endpoints of the REST source
+	SourceREST source.RESTConfig
+	// Mapping of the channel and user records and the channel of the ticket records of the file and REST sources,
+	// ticket records are mapped by TicketFieldMapping
+	SourceMapping source.Mapping
 
 	// Postmark Server config
 	PostmarkServerURL     string
@@ -30,23 +60,75 @@ type Config struct {
 	// SQL database connection URL string
 	DBConnectionString string
 
+	// How long are ticket snapshots of the jobs kept in the database (0 = forever)
+	TicketSnapshotRetentionDays int
+
+	// How long are downloaded users kept across the jobs (0 = users are downloaded by each job)
+	UserCacheTTLMinutes int
+
+	// Decides which field engineers receive the reports by their user type and email domain
+	RecipientFilter recipient.Filter
+
+	// Channels without configuration are processed by the jobs (false = only enabled configured channels are processed)
+	ChannelsEnabledByDefault bool
+
+	// Secret key signing the links to the preferences page in the emails (empty = links and page disabled)
+	PreferencesLinkSecret string
+	// Links to the preferences page expire after the max age (0 = never)
+	PreferencesLinkMaxAge time.Duration
+
 	// list of email addresses of SD agents
 	SDAgentEmails []string
 
+	// Timezone and date format of the dates in the reports, with overrides for the recipients
+	DateSettings locale.Config
+
 	// ITSM server address, for example "http://localhost:8081"
 	ITSMServerURI string
 
+	// Limits of the requests to the ITSM server shared by all ITSM clients (0 = unlimited)
+	ITSMRequestsPerSecond   float64
+	ITSMMaxInFlightRequests int
+
+	// Circuit breakers of the ITSM endpoints open after the number of consecutive failures for the number of seconds
+	ITSMCircuitBreakerFailures    int
+	ITSMCircuitBreakerOpenSeconds int
+
+	// Page size and safety cap of the number of pages of the paginated ITSM queries
+	ITSMPageConfig client.PageConfig
+
 	// Channel endpoint returns info about existing channels
 	ChannelEndpointPath string
 
 	// User endpoint returns info about existing users
 	UserEndpointPath string
 
-	// Incident endpoint returns info about existing incidents
-	IncidentEndpointPath string
+	// Record types downloaded as tickets (incidents, requests, problems...) with their endpoints
+	RecordTypes []RecordTypeEndpoint
+
+	// Mapping of the ticket data to the fields of ITSM records
+	TicketFieldMapping ticket.FieldMapping
+
+	// Columns, grouping and sort order of the reports
+	ReportLayouts excel.Layouts
+
+	// Configured state models of the record types (names of the states and which states are open)
+	StateCatalogue ticket.StateCatalogue
+
+	// State catalogue endpoint returns state models of the record types; if not set, configured catalogue is used
+	StateCatalogueEndpointPath string
 
-	// Requests endpoint returns info about existing requests
-	RequestEndpointPath string
+	// Templates of the links to the tickets in the ITSM UI
+	TicketURLTemplates ticket.URLTemplates
+
+	// Incremental download of the tickets updated since the last successful job
+	IncrementalTicketDownload ticketdownloader.IncrementalConfig
+}
+
+// RecordTypeEndpoint is the record type downloaded as tickets with the endpoint that returns info about existing records
+type RecordTypeEndpoint struct {
+	RecordType   ticket.RecordType
+	EndpointPath string
 }
 
 // loadEnvConfig creates Config object initialized from environment variables
@@ -54,6 +136,7 @@ func loadEnvConfig() (*Config, error) {
 	c := &Config{}
 
 	var ok bool
+	var err error
 
 	// HTTP server
 	if c.HTTPBindAddress, ok = os.LookupEnv("HTTP_BIND_ADDRESS"); !ok {
@@ -74,17 +157,144 @@ func loadEnvConfig() (*Config, error) {
 		c.HTTPShutdownTimeoutInSeconds = int(shTime)
 	}
 
-	// Assertion token - to get the auth token for calls to external services
-	if c.AssertionToken, ok = os.LookupEnv("ASSERTION_TOKEN"); !ok {
-		return c, fmt.Errorf("env var %s not set", "ASSERTION_TOKEN")
+	// Exporter of the OpenTelemetry spans, the OTLP exporter is configured by the standard OTEL_EXPORTER_OTLP_* env vars
+	c.TracesExporter = tracing.ExporterNone // default value
+	if exporterStr, ok := os.LookupEnv("OTEL_TRACES_EXPORTER"); ok {
+		switch exporter := tracing.Exporter(exporterStr); exporter {
+		case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+			c.TracesExporter = exporter
+		default:
+			return c, fmt.Errorf("env var %s must be one of %s, %s, %s",
+				"OTEL_TRACES_EXPORTER", tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout)
+		}
+	}
+
+	// Outbound transport - CA certificates, client certificate for mTLS and proxy of the calls to external services
+	c.OutboundTransport.CAFile = os.Getenv("OUTBOUND_CA_FILE")
+	c.OutboundTransport.ClientCertFile = os.Getenv("OUTBOUND_CLIENT_CERT_FILE")
+	c.OutboundTransport.ClientKeyFile = os.Getenv("OUTBOUND_CLIENT_KEY_FILE")
+	c.OutboundTransport.ProxyURL = os.Getenv("OUTBOUND_PROXY_URL")
+
+	c.OutboundTransport.ConnectTimeout = 30 * time.Second // default value
+	if timeoutStr, ok := os.LookupEnv("OUTBOUND_CONNECT_TIMEOUT_SECONDS"); ok {
+		timeout, err := strconv.ParseInt(timeoutStr, 10, 64)
+		if err != nil || timeout < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "OUTBOUND_CONNECT_TIMEOUT_SECONDS")
+		}
+
+		c.OutboundTransport.ConnectTimeout = time.Duration(timeout) * time.Second
+	}
+
+	c.OutboundTransport.TLSHandshakeTimeout = 10 * time.Second // default value
+	if timeoutStr, ok := os.LookupEnv("OUTBOUND_TLS_HANDSHAKE_TIMEOUT_SECONDS"); ok {
+		timeout, err := strconv.ParseInt(timeoutStr, 10, 64)
+		if err != nil || timeout < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "OUTBOUND_TLS_HANDSHAKE_TIMEOUT_SECONDS")
+		}
+
+		c.OutboundTransport.TLSHandshakeTimeout = time.Duration(timeout) * time.Second
+	}
+
+	c.OutboundTransport.MaxIdleConns = 100 // default value
+	if maxIdleStr, ok := os.LookupEnv("OUTBOUND_MAX_IDLE_CONNECTIONS"); ok {
+		maxIdle, err := strconv.ParseInt(maxIdleStr, 10, 64)
+		if err != nil || maxIdle < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "OUTBOUND_MAX_IDLE_CONNECTIONS")
+		}
+
+		c.OutboundTransport.MaxIdleConns = int(maxIdle)
+	}
+
+	// Mode of the ITSM clients, the replay mode runs without ITSM server and credentials
+	c.ITSMClientMode = client.ModeLive // default value
+	if modeStr, ok := os.LookupEnv("ITSM_CLIENT_MODE"); ok {
+		switch mode := client.Mode(modeStr); mode {
+		case client.ModeLive, client.ModeRecord, client.ModeReplay:
+			c.ITSMClientMode = mode
+		default:
+			return c, fmt.Errorf("env var %s must be one of %s, %s, %s",
+				"ITSM_CLIENT_MODE", client.ModeLive, client.ModeRecord, client.ModeReplay)
+		}
 	}
 
-	if c.AssertionTokenEndpoint, ok = os.LookupEnv("ASSERTION_TOKEN_ENDPOINT"); !ok {
-		return c, fmt.Errorf("env var %s not set", "ASSERTION_TOKEN_ENDPOINT")
+	if c.ITSMFixturesDir, ok = os.LookupEnv("ITSM_FIXTURES_DIR"); !ok {
+		c.ITSMFixturesDir = "testdata/itsm" // default value
+	}
+	// the dir must not depend on the working dir, the Excel generator changes it
+	if c.ITSMFixturesDir, err = filepath.Abs(c.ITSMFixturesDir); err != nil {
+		return c, fmt.Errorf("could not resolve env var %s: %v", "ITSM_FIXTURES_DIR", err)
 	}
 
-	if c.AssertionTokenOrg, ok = os.LookupEnv("ASSERTION_TOKEN_ORG"); !ok {
-		return c, fmt.Errorf("env var %s not set", "ASSERTION_TOKEN_ORG")
+	// Auth provider - assertion (default), static, client_credentials or file; not needed for the replay mode
+	c.Auth.Provider = client.AuthAssertion // default value
+	if providerStr, ok := os.LookupEnv("AUTH_PROVIDER"); ok {
+		switch provider := client.AuthProvider(providerStr); provider {
+		case client.AuthAssertion, client.AuthStatic, client.AuthClientCredentials, client.AuthFile:
+			c.Auth.Provider = provider
+		default:
+			return c, fmt.Errorf("env var %s must be one of %s, %s, %s, %s", "AUTH_PROVIDER",
+				client.AuthAssertion, client.AuthStatic, client.AuthClientCredentials, client.AuthFile)
+		}
+	}
+
+	// Source of the channels, users and tickets, the file and REST sources map their records by the field mappings
+	c.Source = source.KindITSM // default value
+	if sourceStr, ok := os.LookupEnv("SOURCE"); ok {
+		kind, err := source.ParseKind(sourceStr)
+		if err != nil {
+			return c, fmt.Errorf("could not parse env var %s: %v", "SOURCE", err)
+		}
+
+		c.Source = kind
+	}
+
+	switch c.Source {
+	case source.KindFile:
+		// Dir with channels, users and <record_type> export files in JSON or CSV
+		if c.SourceFileDir, ok = os.LookupEnv("SOURCE_FILE_DIR"); !ok {
+			return c, fmt.Errorf("env var %s not set", "SOURCE_FILE_DIR")
+		}
+		if c.SourceFileDir, err = filepath.Abs(c.SourceFileDir); err != nil {
+			return c, fmt.Errorf("could not resolve env var %s: %v", "SOURCE_FILE_DIR", err)
+		}
+	case source.KindREST:
+		// Endpoints of the REST API, URLs can contain {channel} and {record_type} placeholders
+		// (e.g. "https://api.test/projects/{channel}/{record_type}")
+		if c.SourceREST.ChannelsURL, ok = os.LookupEnv("SOURCE_REST_CHANNELS_URL"); !ok {
+			return c, fmt.Errorf("env var %s not set", "SOURCE_REST_CHANNELS_URL")
+		}
+		if c.SourceREST.UsersURL, ok = os.LookupEnv("SOURCE_REST_USERS_URL"); !ok {
+			return c, fmt.Errorf("env var %s not set", "SOURCE_REST_USERS_URL")
+		}
+		if c.SourceREST.TicketsURL, ok = os.LookupEnv("SOURCE_REST_TICKETS_URL"); !ok {
+			return c, fmt.Errorf("env var %s not set", "SOURCE_REST_TICKETS_URL")
+		}
+		// dot separated path of the array of records in the responses (e.g. "data.items")
+		c.SourceREST.ResultPath = os.Getenv("SOURCE_REST_RESULT_PATH")
+	}
+
+	// Channel and user field mappings, comma separated "key=path" pairs (id=key,name=title)
+	channelMapping, err := source.ParseChannelMapping(os.Getenv("SOURCE_CHANNEL_FIELD_MAPPING"))
+	if err != nil {
+		return c, fmt.Errorf("could not parse env var %s: %v", "SOURCE_CHANNEL_FIELD_MAPPING", err)
+	}
+	c.SourceMapping.Channel = channelMapping
+
+	userMapping, err := source.ParseUserMapping(os.Getenv("SOURCE_USER_FIELD_MAPPING"))
+	if err != nil {
+		return c, fmt.Errorf("could not parse env var %s: %v", "SOURCE_USER_FIELD_MAPPING", err)
+	}
+	c.SourceMapping.User = userMapping
+
+	if c.SourceMapping.TicketChannelID, ok = os.LookupEnv("SOURCE_TICKET_CHANNEL_FIELD"); !ok {
+		c.SourceMapping.TicketChannelID = source.DefaultTicketChannelID // default value
+	}
+
+	// the file source and ITSM in the replay mode are read without the auth token
+	if c.Source == source.KindREST || (c.Source == source.KindITSM && c.ITSMClientMode != client.ModeReplay) {
+		if err := loadAuthConfig(&c.Auth); err != nil {
+			return c, err
+		}
 	}
 
 	// Postmark Server = email sending service
@@ -109,17 +319,141 @@ func loadEnvConfig() (*Config, error) {
 		return c, fmt.Errorf("env var %s not set", "DB_CONNECTION_STRING")
 	}
 
+	c.TicketSnapshotRetentionDays = 30 // default value
+	if retentionStr, ok := os.LookupEnv("TICKET_SNAPSHOT_RETENTION_DAYS"); ok {
+		retention, err := strconv.ParseInt(retentionStr, 10, 64)
+		if err != nil || retention < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "TICKET_SNAPSHOT_RETENTION_DAYS")
+		}
+
+		c.TicketSnapshotRetentionDays = int(retention)
+	}
+
+	if ttlStr, ok := os.LookupEnv("USER_CACHE_TTL_MINUTES"); ok {
+		ttl, err := strconv.ParseInt(ttlStr, 10, 64)
+		if err != nil || ttl < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "USER_CACHE_TTL_MINUTES")
+		}
+
+		c.UserCacheTTLMinutes = int(ttl)
+	}
+
+	c.ChannelsEnabledByDefault = true // default value
+	if enabledStr, ok := os.LookupEnv("CHANNELS_ENABLED_BY_DEFAULT"); ok {
+		enabled, err := strconv.ParseBool(enabledStr)
+		if err != nil {
+			return c, fmt.Errorf("could not parse env var %s as bool", "CHANNELS_ENABLED_BY_DEFAULT")
+		}
+
+		c.ChannelsEnabledByDefault = enabled
+	}
+
+	c.PreferencesLinkSecret = os.Getenv("PREFERENCES_LINK_SECRET")
+
+	if maxAgeStr, ok := os.LookupEnv("PREFERENCES_LINK_MAX_AGE_DAYS"); ok {
+		maxAge, err := strconv.ParseInt(maxAgeStr, 10, 64)
+		if err != nil || maxAge < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "PREFERENCES_LINK_MAX_AGE_DAYS")
+		}
+
+		c.PreferencesLinkMaxAge = time.Duration(maxAge) * 24 * time.Hour
+	}
+
+	// user types and email domains of field engineers receiving the reports, separated by comma (engineer,employee)
+	c.RecipientFilter = recipient.ParseFilter(
+		os.Getenv("FE_RECIPIENT_ALLOWED_USER_TYPES"),
+		os.Getenv("FE_RECIPIENT_DENIED_USER_TYPES"),
+		os.Getenv("FE_RECIPIENT_ALLOWED_EMAIL_DOMAINS"),
+		os.Getenv("FE_RECIPIENT_DENIED_EMAIL_DOMAINS"),
+	)
+
 	// email addresses of SD agents, separated by comma (one@test.com,two@test.com)
 	SDAgentEmails := os.Getenv("SD_AGENT_EMAILS")
 	if SDAgentEmails != "" {
 		c.SDAgentEmails = strings.Split(SDAgentEmails, ",")
 	}
 
-	// ITSM server address, for example "http://localhost:8081"
-	if c.ITSMServerURI, ok = os.LookupEnv("ITSM_SERVER_URI"); !ok {
+	// Timezone (e.g. "Europe/Prague") and date format of the dates in the reports; date format is a locale name
+	// (e.g. "de-DE") or a format using d, dd, m, mm, yy and yyyy (e.g. "dd.mm.yyyy")
+	dateSettings, err := locale.ParseSettings(os.Getenv("REPORT_TIMEZONE"), os.Getenv("REPORT_DATE_FORMAT"), locale.DefaultSettings())
+	if err != nil {
+		return c, fmt.Errorf("could not parse env vars %s and %s: %v", "REPORT_TIMEZONE", "REPORT_DATE_FORMAT", err)
+	}
+	c.DateSettings.Default = dateSettings
+
+	// Recipient specific date settings, comma separated "email=timezone[|format]" list
+	// (joe@test.com=America/New_York|en-US,jan@test.com=Europe/Prague)
+	if c.DateSettings.Recipients, err = locale.ParseRecipientSettings(os.Getenv("RECIPIENT_DATE_SETTINGS"), dateSettings); err != nil {
+		return c, fmt.Errorf("could not parse env var %s: %v", "RECIPIENT_DATE_SETTINGS", err)
+	}
+
+	// ITSM server address, for example "http://localhost:8081"; it is not needed by the other sources
+	if c.ITSMServerURI, ok = os.LookupEnv("ITSM_SERVER_URI"); !ok && c.Source == source.KindITSM {
 		return c, fmt.Errorf("env var %s not set", "ITSM_SERVER_URI")
 	}
 
+	// Requests per second to the ITSM server, the clients slow down further when the server responds with 429
+	if rpsStr, ok := os.LookupEnv("ITSM_REQUESTS_PER_SECOND"); ok {
+		rps, err := strconv.ParseFloat(rpsStr, 64)
+		if err != nil || rps < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative number", "ITSM_REQUESTS_PER_SECOND")
+		}
+
+		c.ITSMRequestsPerSecond = rps
+	}
+
+	// Maximum number of requests to the ITSM server processed at the same time
+	if inFlightStr, ok := os.LookupEnv("ITSM_MAX_IN_FLIGHT_REQUESTS"); ok {
+		inFlight, err := strconv.ParseInt(inFlightStr, 10, 64)
+		if err != nil || inFlight < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "ITSM_MAX_IN_FLIGHT_REQUESTS")
+		}
+
+		c.ITSMMaxInFlightRequests = int(inFlight)
+	}
+
+	// Consecutive failed requests to the ITSM endpoint opening its circuit breaker (0 = circuit breakers disabled)
+	c.ITSMCircuitBreakerFailures = 5 // default value
+	if failuresStr, ok := os.LookupEnv("ITSM_CIRCUIT_BREAKER_FAILURES"); ok {
+		failures, err := strconv.ParseInt(failuresStr, 10, 64)
+		if err != nil || failures < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "ITSM_CIRCUIT_BREAKER_FAILURES")
+		}
+
+		c.ITSMCircuitBreakerFailures = int(failures)
+	}
+
+	// How long the open circuit breaker fails the requests fast before it lets a trial request through
+	c.ITSMCircuitBreakerOpenSeconds = 60 // default value
+	if openStr, ok := os.LookupEnv("ITSM_CIRCUIT_BREAKER_OPEN_SECONDS"); ok {
+		open, err := strconv.ParseInt(openStr, 10, 64)
+		if err != nil || open < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "ITSM_CIRCUIT_BREAKER_OPEN_SECONDS")
+		}
+
+		c.ITSMCircuitBreakerOpenSeconds = int(open)
+	}
+
+	// Number of records requested per page of the user and ticket queries (0 = page size of the ITSM server)
+	if pageSizeStr, ok := os.LookupEnv("ITSM_PAGE_SIZE"); ok {
+		pageSize, err := strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil || pageSize < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "ITSM_PAGE_SIZE")
+		}
+
+		c.ITSMPageConfig.PageSize = int(pageSize)
+	}
+
+	// Maximum number of pages of one query, the query fails when the ITSM server returns more (0 = default cap)
+	if maxPagesStr, ok := os.LookupEnv("ITSM_MAX_PAGES"); ok {
+		maxPages, err := strconv.ParseInt(maxPagesStr, 10, 64)
+		if err != nil || maxPages < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "ITSM_MAX_PAGES")
+		}
+
+		c.ITSMPageConfig.MaxPages = int(maxPages)
+	}
+
 	// Channel endpoint returns info about existing channels
 	if c.ChannelEndpointPath, ok = os.LookupEnv("CHANNEL_ENDPOINT_PATH"); !ok {
 		c.ChannelEndpointPath = c.ITSMServerURI + "/api/v1/sub-spaces-by-app?appName=itsm" // default value
@@ -130,15 +464,222 @@ func loadEnvConfig() (*Config, error) {
 		c.UserEndpointPath = c.ITSMServerURI + "/api/v1/assets/user" // default value
 	}
 
-	// Incident endpoint returns info about existing incidents
-	if c.IncidentEndpointPath, ok = os.LookupEnv("INCIDENT_ENDPOINT_PATH"); !ok {
-		c.IncidentEndpointPath = c.ITSMServerURI + "/api/v1/assets/incident?resolve=true" // default value
+	// Record types downloaded as tickets, comma separated "name[=Display name]" list (incident=Incident,k_request=Request,problem=Problem);
+	// the order of the list is the order of the record types in the reports
+	recordTypesDef, ok := os.LookupEnv("RECORD_TYPES")
+	if !ok {
+		recordTypesDef = "incident,k_request" // default value
+	}
+
+	recordTypes, err := ticket.ParseRecordTypes(recordTypesDef)
+	if err != nil {
+		return c, fmt.Errorf("could not parse env var %s: %v", "RECORD_TYPES", err)
 	}
 
-	// Requests endpoint returns info about existing requests (i.e. k_requests)
-	if c.RequestEndpointPath, ok = os.LookupEnv("REQUEST_ENDPOINT_PATH"); !ok {
-		c.RequestEndpointPath = c.ITSMServerURI + "/api/v1/assets/k_request?resolve=true" // default value
+	for _, rt := range recordTypes {
+		// Record type endpoint returns info about existing records of that type,
+		// it can be set by <NAME>_ENDPOINT_PATH env var, e.g. PROBLEM_ENDPOINT_PATH
+		endpointPath, ok := os.LookupEnv(recordTypeEnvVar(rt.Name, "ENDPOINT_PATH"))
+		if !ok && rt.Name == "k_request" {
+			// Requests endpoint was configured by REQUEST_ENDPOINT_PATH before
+			endpointPath, ok = os.LookupEnv("REQUEST_ENDPOINT_PATH")
+		}
+		if !ok {
+			endpointPath = c.ITSMServerURI + "/api/v1/assets/" + rt.Name + "?resolve=true" // default value
+		}
+
+		c.RecordTypes = append(c.RecordTypes, RecordTypeEndpoint{
+			RecordType:   rt,
+			EndpointPath: endpointPath,
+		})
+	}
+
+	// Ticket states, comma separated "id=Name[:closed]" list (0=New,2=In progress,4=Resolved:closed)
+	c.StateCatalogue = ticket.DefaultStateCatalogue()
+	if statesDef, ok := os.LookupEnv("TICKET_STATES"); ok {
+		if c.StateCatalogue.Default, err = ticket.ParseStateModel(statesDef); err != nil {
+			return c, fmt.Errorf("could not parse env var %s: %v", "TICKET_STATES", err)
+		}
+	}
+
+	// Record type specific ticket states, e.g. PROBLEM_TICKET_STATES
+	for _, rt := range recordTypes {
+		envVar := recordTypeEnvVar(rt.Name, "TICKET_STATES")
+		statesDef, ok := os.LookupEnv(envVar)
+		if !ok {
+			continue
+		}
+
+		model, err := ticket.ParseStateModel(statesDef)
+		if err != nil {
+			return c, fmt.Errorf("could not parse env var %s: %v", envVar, err)
+		}
+
+		if c.StateCatalogue.RecordTypes == nil {
+			c.StateCatalogue.RecordTypes = make(map[string]ticket.StateModel)
+		}
+		c.StateCatalogue.RecordTypes[rt.Name] = model
+	}
+
+	// State catalogue endpoint returns state models of the record types (optional)
+	c.StateCatalogueEndpointPath = os.Getenv("STATE_CATALOGUE_ENDPOINT_PATH")
+
+	// Ticket field mapping, comma separated "key=path" pairs (priority=priority,assignment_group=assignment_group.name)
+	fieldMapping, err := ticket.ParseFieldMapping(os.Getenv("TICKET_FIELD_MAPPING"))
+	if err != nil {
+		return c, fmt.Errorf("could not parse env var %s: %v", "TICKET_FIELD_MAPPING", err)
+	}
+	c.TicketFieldMapping = fieldMapping
+
+	// Report layouts, the columns are comma separated "field[=Label][:width]" items (ticket_type,number,title=Summary:60),
+	// the rows are grouped by the field (empty = no grouping) and sorted by comma separated "field[:desc]" items
+	c.ReportLayouts = excel.DefaultLayouts(fieldMapping.Extra)
+	for _, l := range []struct {
+		prefix string
+		layout *excel.Layout
+	}{
+		{"FE_REPORT", &c.ReportLayouts.FieldEngineer},
+		{"SD_REPORT", &c.ReportLayouts.AllTickets},
+	} {
+		groupBy, ok := os.LookupEnv(l.prefix + "_GROUP_BY")
+		if !ok {
+			groupBy = l.layout.GroupBy // default value
+		}
+
+		layout, err := excel.ParseLayout(
+			*l.layout, os.Getenv(l.prefix+"_COLUMNS"), groupBy, os.Getenv(l.prefix+"_SORT_BY"), fieldMapping.Extra,
+		)
+		if err != nil {
+			return c, fmt.Errorf("could not parse env vars %s_COLUMNS, %s_GROUP_BY and %s_SORT_BY: %v",
+				l.prefix, l.prefix, l.prefix, err)
+		}
+		*l.layout = layout
+	}
+
+	// Row highlights of the reports, comma separated "state[>days]=#RRGGBB" list, state "*" matches any state
+	// (New>2=#FFC7CE,*>30=#D9D9D9); the first matching highlight colors the row of the ticket
+	highlightsDef, ok := os.LookupEnv("REPORT_ROW_HIGHLIGHTS")
+	if !ok {
+		highlightsDef = "New>2=#FFC7CE" // default value
+	}
+
+	highlights, err := excel.ParseHighlights(highlightsDef)
+	if err != nil {
+		return c, fmt.Errorf("could not parse env var %s: %v", "REPORT_ROW_HIGHLIGHTS", err)
+	}
+	c.ReportLayouts.FieldEngineer.Highlights = highlights
+	c.ReportLayouts.AllTickets.Highlights = highlights
+
+	// Charts of the tickets by channel and by record type in the summary sheet of the service desk workbook
+	if chartsStr, ok := os.LookupEnv("SD_REPORT_SUMMARY_CHARTS"); ok {
+		charts, err := strconv.ParseBool(chartsStr)
+		if err != nil {
+			return c, fmt.Errorf("could not parse env var %s as bool", "SD_REPORT_SUMMARY_CHARTS")
+		}
+
+		c.ReportLayouts.SummaryCharts = charts
+	}
+
+	// Links to the tickets in the ITSM UI, template placeholders are {base}, {channel}, {record_type}, {uuid} and {number}
+	// (e.g. "{base}/{channel}/incident/{uuid}"); if no template is set, ticket numbers are not linked
+	if c.TicketURLTemplates.BaseURL, ok = os.LookupEnv("TICKET_URL_BASE"); !ok {
+		c.TicketURLTemplates.BaseURL = c.ITSMServerURI // default value
+	}
+
+	c.TicketURLTemplates.Default = os.Getenv("TICKET_URL_TEMPLATE")
+
+	// Record type specific templates, e.g. PROBLEM_TICKET_URL_TEMPLATE
+	for _, rt := range recordTypes {
+		if template, ok := os.LookupEnv(recordTypeEnvVar(rt.Name, "TICKET_URL_TEMPLATE")); ok {
+			if c.TicketURLTemplates.RecordTypes == nil {
+				c.TicketURLTemplates.RecordTypes = make(map[string]string)
+			}
+			c.TicketURLTemplates.RecordTypes[rt.Name] = template
+		}
+	}
+
+	// Channel specific templates, comma separated "channelID=template" pairs
+	if c.TicketURLTemplates.Channels, err = ticket.ParseChannelURLTemplates(os.Getenv("CHANNEL_TICKET_URL_TEMPLATES")); err != nil {
+		return c, fmt.Errorf("could not parse env var %s: %v", "CHANNEL_TICKET_URL_TEMPLATES", err)
+	}
+
+	// Incremental ticket download, each job downloads only tickets updated since the last successful job
+	// and merges them with the ticket snapshot of that job
+	if incrementalStr, ok := os.LookupEnv("INCREMENTAL_TICKET_DOWNLOAD"); ok {
+		incremental, err := strconv.ParseBool(incrementalStr)
+		if err != nil {
+			return c, fmt.Errorf("could not parse env var %s as bool", "INCREMENTAL_TICKET_DOWNLOAD")
+		}
+
+		c.IncrementalTicketDownload.Enabled = incremental
+	}
+
+	// How often are all open tickets downloaded again when incremental download is enabled (0 = only on request)
+	c.IncrementalTicketDownload.FullResyncInterval = 24 * time.Hour // default value
+	if intervalStr, ok := os.LookupEnv("FULL_RESYNC_INTERVAL_HOURS"); ok {
+		interval, err := strconv.ParseInt(intervalStr, 10, 64)
+		if err != nil || interval < 0 {
+			return c, fmt.Errorf("could not parse env var %s as non-negative int", "FULL_RESYNC_INTERVAL_HOURS")
+		}
+
+		c.IncrementalTicketDownload.FullResyncInterval = time.Duration(interval) * time.Hour
 	}
 
 	return c, nil
 }
+
+// loadAuthConfig loads the configuration of the selected auth provider from environment variables
+func loadAuthConfig(c *client.AuthConfig) error {
+	required := func(name string, value *string) error {
+		var ok bool
+		if *value, ok = os.LookupEnv(name); !ok {
+			return fmt.Errorf("env var %s not set", name)
+		}
+		return nil
+	}
+
+	switch c.Provider {
+	case client.AuthAssertion:
+		// Assertion token - to get the auth token for calls to external services
+		if err := required("ASSERTION_TOKEN", &c.Assertion.AssertionToken); err != nil {
+			return err
+		}
+		if err := required("ASSERTION_TOKEN_ENDPOINT", &c.Assertion.AssertionTokenEndpoint); err != nil {
+			return err
+		}
+		if err := required("ASSERTION_TOKEN_ORG", &c.Assertion.AssertionTokenOrg); err != nil {
+			return err
+		}
+	case client.AuthStatic:
+		// Static token, e.g. for local setups
+		if err := required("AUTH_STATIC_TOKEN", &c.StaticToken); err != nil {
+			return err
+		}
+	case client.AuthClientCredentials:
+		// OAuth2 client credentials grant, scopes are separated by comma or space
+		if err := required("OAUTH2_TOKEN_URL", &c.OAuth2.TokenURL); err != nil {
+			return err
+		}
+		if err := required("OAUTH2_CLIENT_ID", &c.OAuth2.ClientID); err != nil {
+			return err
+		}
+		if err := required("OAUTH2_CLIENT_SECRET", &c.OAuth2.ClientSecret); err != nil {
+			return err
+		}
+		c.OAuth2.Scopes = strings.FieldsFunc(os.Getenv("OAUTH2_SCOPES"), func(r rune) bool { return r == ',' || r == ' ' })
+		c.OAuth2.Audience = os.Getenv("OAUTH2_AUDIENCE")
+	case client.AuthFile:
+		// Token file, e.g. mounted secret, re-read when it changes
+		if err := required("AUTH_TOKEN_FILE", &c.TokenFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordTypeEnvVar returns name of the record type specific env var (k_request, ENDPOINT_PATH => K_REQUEST_ENDPOINT_PATH)
+func recordTypeEnvVar(recordTypeName, suffix string) string {
+	name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(recordTypeName))
+	return name + "_" + suffix
+}
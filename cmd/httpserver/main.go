@@ -9,17 +9,22 @@ import (
 	"time"
 
 	chandownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/channel/downloader"
+	chansvc "github.com/KompiTech/itsm-reporting-service/internal/domain/channel/service"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/email"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/excel"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/job/processor"
 	jobsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/job/service"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/source"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
 	ticketdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/ticket/downloader"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/types"
 	userdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/user/downloader"
 	"github.com/KompiTech/itsm-reporting-service/internal/http/rest"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository/sql"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
 	"go.uber.org/zap"
 )
 
@@ -46,6 +51,11 @@ func main() {
 		logger.Fatalw("Error loading configuration", "error", err)
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background(), config.TracesExporter)
+	if err != nil {
+		logger.Fatalw("Error initializing tracing", "error", err)
+	}
+
 	clock := realClock{}
 	db, err := sql.OpenDB(config.DBConnectionString)
 	if err != nil {
@@ -58,42 +68,158 @@ func main() {
 	}
 	jobService := jobsvc.NewJobService(jobRepository)
 
-	tokenSvcClient, err := client.NewTokenSvcClient(client.Config{
-		AssertionToken:         config.AssertionToken,
-		AssertionTokenEndpoint: config.AssertionTokenEndpoint,
-		AssertionTokenOrg:      config.AssertionTokenOrg,
-	})
+	// all outbound clients share the transport (CA, client certificate, proxy, timeouts, idle connections),
+	// the client is passed to each of them
+	outboundClient, err := client.NewOutboundHTTPClient(config.OutboundTransport)
 	if err != nil {
-		logger.Fatalw("Error creating tokenSvcClient", "error", err)
+		logger.Fatalw("Error creating outbound HTTP client", "error", err)
+	}
+
+	// the replay mode does not call ITSM and the file source reads local files, so they do not need the auth token
+	var tokenSvcClient client.TokenSvcClient
+	if config.Source == source.KindREST || (config.Source == source.KindITSM && config.ITSMClientMode != client.ModeReplay) {
+		if config.Auth.Provider == client.AuthAssertion {
+			// the assertion token refresher cannot be given a client and always uses http.DefaultTransport,
+			// this is the only client relying on the default transport
+			http.DefaultTransport = outboundClient.Transport
+		}
+
+		tokenSvcClient, err = client.NewAuthTokenSvcClient(config.Auth, outboundClient)
+		if err != nil {
+			logger.Fatalw("Error creating tokenSvcClient", "provider", config.Auth.Provider, "error", err)
+		}
+	}
+
+	// all ITSM clients share the limits of the requests to the ITSM server, each endpoint has its circuit breaker
+	itsmLimiter := client.NewLimiter("itsm", config.ITSMRequestsPerSecond, config.ITSMMaxInFlightRequests)
+	var circuitBreakers []*client.CircuitBreaker
+	newITSMClient := func(url string) client.Client {
+		if config.ITSMClientMode == client.ModeReplay {
+			return client.NewReplayClient(url, config.ITSMFixturesDir)
+		}
+
+		c := client.NewHTTPClient(url, logger, tokenSvcClient)
+		c.Client = outboundClient
+		c.Limiter = itsmLimiter
+		if config.ITSMCircuitBreakerFailures > 0 {
+			c.Breaker = client.NewCircuitBreaker(
+				url, config.ITSMCircuitBreakerFailures, time.Duration(config.ITSMCircuitBreakerOpenSeconds)*time.Second,
+			)
+			circuitBreakers = append(circuitBreakers, c.Breaker)
+		}
+
+		if config.ITSMClientMode == client.ModeRecord {
+			return client.NewRecordingClient(c, url, config.ITSMFixturesDir)
+		}
+		return c
+	}
+
+	channelConfigRepository, err := sql.NewChannelConfigRepositorySQL(clock, db)
+	if err != nil {
+		logger.Fatalw("Error creating channelConfigRepositorySQL", "error", err)
+	}
+	channelConfigService := chansvc.NewChannelConfigService(channelConfigRepository)
+
+	// channels, users and tickets are downloaded from ITSM, or read from the other source by the field mappings
+	var channelClient chandownloader.ChannelClient
+	var userClient userdownloader.UserClient
+	var ticketClient ticketdownloader.TicketClient
+	stateClient := ticketdownloader.NewStaticStateClient(config.StateCatalogue)
+
+	var recordTypes []ticket.RecordType
+	for _, rt := range config.RecordTypes {
+		recordTypes = append(recordTypes, rt.RecordType)
+	}
+	sourceMapping := config.SourceMapping
+	sourceMapping.Ticket = config.TicketFieldMapping
+	sourceMapping.URLTemplates = config.TicketURLTemplates
+
+	switch config.Source {
+	case source.KindFile:
+		src := source.NewFileSource(config.SourceFileDir, recordTypes, sourceMapping)
+		channelClient, userClient, ticketClient = src.ChannelClient, src.UserClient, src.TicketClient
+	case source.KindREST:
+		src := source.NewRESTSource(config.SourceREST, recordTypes, sourceMapping, newITSMClient)
+		channelClient, userClient, ticketClient = src.ChannelClient, src.UserClient, src.TicketClient
+	default:
+		channelClient = chandownloader.NewChannelClient(newITSMClient(config.ChannelEndpointPath))
+		userClient = userdownloader.NewUserClient(newITSMClient(config.UserEndpointPath), config.ITSMPageConfig)
+
+		var recordTypeClients []ticketdownloader.RecordTypeClient
+		for _, rt := range config.RecordTypes {
+			recordTypeClients = append(recordTypeClients, ticketdownloader.RecordTypeClient{
+				RecordType: rt.RecordType,
+				Client:     newITSMClient(rt.EndpointPath),
+			})
+		}
+		ticketClient = ticketdownloader.NewTicketClient(
+			recordTypeClients, config.TicketFieldMapping, config.TicketURLTemplates, config.ITSMPageConfig,
+		)
+
+		if config.StateCatalogueEndpointPath != "" {
+			stateClient = ticketdownloader.NewStateClient(
+				newITSMClient(config.StateCatalogueEndpointPath),
+				config.StateCatalogue,
+			)
+		}
 	}
 
 	channelRepository := memory.NewChannelRepositoryMemory()
-	channelClient := chandownloader.NewChannelClient(client.NewHTTPClient(config.ChannelEndpointPath, logger, tokenSvcClient))
-	channelDownloader := chandownloader.NewChannelDownloader(channelRepository, channelClient)
+	channelDownloader := chandownloader.NewChannelDownloader(
+		channelRepository, channelConfigRepository, channelClient, config.ChannelsEnabledByDefault,
+	)
 
 	userRepository := memory.NewUserRepositoryMemory()
-	userClient := userdownloader.NewUserClient(client.NewHTTPClient(config.UserEndpointPath, logger, tokenSvcClient))
-	userDownloader := userdownloader.NewUserDownloader(logger, channelRepository, userRepository, userClient)
+	userDownloader := userdownloader.NewUserDownloader(
+		logger, clock, channelRepository, userRepository, userClient, time.Duration(config.UserCacheTTLMinutes)*time.Minute,
+	)
 
 	ticketRepository := memory.NewTicketRepositoryMemory()
-	ticketClient := ticketdownloader.NewTicketClient(
-		client.NewHTTPClient(config.IncidentEndpointPath, logger, tokenSvcClient),
-		client.NewHTTPClient(config.RequestEndpointPath, logger, tokenSvcClient),
+	ticketSnapshotRepository, err := sql.NewTicketSnapshotRepositorySQL(
+		clock, db, time.Duration(config.TicketSnapshotRetentionDays)*24*time.Hour,
 	)
-	ticketDownloader := ticketdownloader.NewTicketDownloader(logger, channelRepository, userRepository, ticketRepository, ticketClient)
+	if err != nil {
+		logger.Fatalw("Error creating ticketSnapshotRepositorySQL", "error", err)
+	}
 
-	excelGen := excel.NewExcelGenerator(logger, ticketRepository, config.SDAgentEmails)
+	ticketDownloader := ticketdownloader.NewTicketDownloader(
+		logger, clock, channelRepository, userRepository, ticketRepository, ticketSnapshotRepository, jobRepository,
+		ticketClient, stateClient, config.IncrementalTicketDownload,
+	)
+
+	preferencesRepository, err := sql.NewPreferencesRepositorySQL(clock, db)
+	if err != nil {
+		logger.Fatalw("Error creating preferencesRepositorySQL", "error", err)
+	}
+
+	preferencesService := prefsvc.NewPreferencesService(
+		clock, preferencesRepository, channelRepository, recordTypes,
+		config.PreferencesLinkSecret, config.PreferencesLinkMaxAge, config.HTTPExternalLocationAddress,
+	)
+
+	excelGen := excel.NewExcelGenerator(
+		logger, clock, channelRepository, ticketRepository, jobRepository, ticketSnapshotRepository, config.SDAgentEmails,
+		config.ReportLayouts, config.TicketFieldMapping.Extra, config.DateSettings, preferencesService, config.RecipientFilter,
+	)
 
 	emailSender := email.NewEmailSender(
 		logger,
+		outboundClient,
 		config.PostmarkServerURL,
 		config.PostmarkServerToken,
 		config.PostmarkMessageStream,
 		config.FromEmailAddress,
 		excelGen.FEDirPath(),
 		excelGen.SDDirPath(),
+		excelGen.ChannelDirPath(),
+		channelRepository,
 		ticketRepository,
 		config.SDAgentEmails,
+		config.ReportLayouts,
+		config.TicketFieldMapping.Extra,
+		config.DateSettings,
+		preferencesService,
+		config.RecipientFilter,
 	)
 
 	jobProcessor := jobprocessor.NewJobProcessor(
@@ -113,6 +239,10 @@ func main() {
 		Logger:                  logger,
 		JobsService:             jobService,
 		JobsProcessor:           jobProcessor,
+		UserDownloader:          userDownloader,
+		PreferencesService:      preferencesService,
+		ChannelConfigService:    channelConfigService,
+		CircuitBreakers:         circuitBreakers,
 		ExternalLocationAddress: config.HTTPExternalLocationAddress,
 	})
 
@@ -160,6 +290,12 @@ func main() {
 			logger.Error("error closing TicketDownloader client", zap.Error(err))
 		}
 
+		// Export the remaining spans
+		logger.Info("Shutting down tracing")
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("error shutting down tracing", zap.Error(err))
+		}
+
 		close(idleConnsClosed)
 	}()
 
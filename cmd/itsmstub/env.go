@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config contains all the configuration variables of the stub server
+type Config struct {
+	// Local server bind address
+	HTTPBindAddress string
+
+	// Dir with the fixture files served by the stub, the fixtures are generated if the dir does not contain them
+	FixturesDir string
+	// Sizes of the generated fixtures
+	Sizes Sizes
+
+	// Page size of the queries that do not specify the limit
+	DefaultPageSize int
+
+	// Dir where the email batches sent to the stub are saved
+	EmailDir string
+}
+
+// loadEnvConfig creates Config object initialized from environment variables
+func loadEnvConfig() (*Config, error) {
+	c := &Config{}
+
+	var ok bool
+
+	if c.HTTPBindAddress, ok = os.LookupEnv("STUB_BIND_ADDRESS"); !ok {
+		c.HTTPBindAddress = "localhost:8081" // default value
+	}
+
+	if c.FixturesDir, ok = os.LookupEnv("STUB_FIXTURES_DIR"); !ok {
+		c.FixturesDir = "testdata/itsmstub" // default value
+	}
+
+	if c.EmailDir, ok = os.LookupEnv("STUB_EMAIL_DIR"); !ok {
+		c.EmailDir = "build/emails" // default value
+	}
+
+	// Sizes of the generated data
+	c.Sizes = Sizes{
+		Channels:          3,
+		UsersPerChannel:   20,
+		TicketsPerChannel: 100,
+		RecordTypes:       []string{"incident", "k_request"},
+		Seed:              1,
+	}
+
+	intVars := []struct {
+		name  string
+		value *int
+	}{
+		{"STUB_CHANNELS", &c.Sizes.Channels},
+		{"STUB_USERS_PER_CHANNEL", &c.Sizes.UsersPerChannel},
+		{"STUB_TICKETS_PER_CHANNEL", &c.Sizes.TicketsPerChannel},
+	}
+	for _, v := range intVars {
+		if str, ok := os.LookupEnv(v.name); ok {
+			n, err := strconv.ParseInt(str, 10, 64)
+			if err != nil || n < 0 {
+				return c, fmt.Errorf("could not parse env var %s as non-negative int", v.name)
+			}
+
+			*v.value = int(n)
+		}
+	}
+
+	// Record types of the generated tickets, comma separated (incident,k_request,problem)
+	if recordTypesStr, ok := os.LookupEnv("STUB_RECORD_TYPES"); ok {
+		c.Sizes.RecordTypes = nil
+		for _, rt := range strings.Split(recordTypesStr, ",") {
+			if rt = strings.TrimSpace(rt); rt != "" {
+				c.Sizes.RecordTypes = append(c.Sizes.RecordTypes, rt)
+			}
+		}
+	}
+
+	// Seed of the generator, the same seed generates the same data except the dates, which are relative to the time
+	// of the generation
+	if seedStr, ok := os.LookupEnv("STUB_SEED"); ok {
+		seed, err := strconv.ParseInt(seedStr, 10, 64)
+		if err != nil {
+			return c, fmt.Errorf("could not parse env var %s as int", "STUB_SEED")
+		}
+
+		c.Sizes.Seed = seed
+	}
+
+	c.DefaultPageSize = 100 // default value
+	if pageSizeStr, ok := os.LookupEnv("STUB_PAGE_SIZE"); ok {
+		pageSize, err := strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil || pageSize <= 0 {
+			return c, fmt.Errorf("could not parse env var %s as positive int", "STUB_PAGE_SIZE")
+		}
+
+		c.DefaultPageSize = int(pageSize)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Record is one record of the ITSM asset (user, incident, ...)
+type Record map[string]interface{}
+
+// Space is the channel returned by the sub-spaces endpoint
+type Space struct {
+	ID   string `json:"space"`
+	Name string `json:"name"`
+}
+
+// Fixtures are the data served by the stub
+type Fixtures struct {
+	Spaces []Space
+	// Assets are the records by the asset name (user, incident, k_request...) and the channel ID
+	Assets map[string]map[string][]Record
+}
+
+// Sizes configure the generated fixtures
+type Sizes struct {
+	Channels          int
+	UsersPerChannel   int
+	TicketsPerChannel int // per record type
+	RecordTypes       []string
+	Seed              int64
+}
+
+const (
+	spacesFile = "spaces.json"
+	userAsset  = "user"
+)
+
+var (
+	firstNames   = []string{"Alice", "Bob", "Carol", "David", "Eve", "Frank", "Grace", "Heidi", "Ivan", "Judy", "Mallory", "Oscar"}
+	lastNames    = []string{"Novak", "Svoboda", "Dvorak", "Cerny", "Prochazka", "Kucera", "Vesely", "Horak", "Marek", "Pokorny"}
+	userTypes    = []string{"engineer", "engineer", "engineer", "employee", "contractor"}
+	emailDomains = []string{"example.com", "example.com", "example.org"}
+	cities       = []string{"Prague", "Brno", "Ostrava", "Plzen", "Liberec", "Olomouc"}
+	streets      = []string{"Main street", "Station road", "Park avenue", "Market square", "River street"}
+	descriptions = []string{
+		"Printer is not working", "Cannot log in to the workstation", "Replace broken monitor", "Network outage on the floor",
+		"Install new software", "Laptop does not start", "Access to the shared drive", "Phone line is dead",
+	}
+)
+
+// GenerateFixtures generates the fixtures of the sizes with the ticket dates in the 90 days before now. The same sizes
+// and now always generate the same data; with other now only the dates are shifted.
+func GenerateFixtures(sizes Sizes, now time.Time) Fixtures {
+	r := rand.New(rand.NewSource(sizes.Seed))
+
+	f := Fixtures{
+		Assets: map[string]map[string][]Record{userAsset: {}},
+	}
+
+	for _, rt := range sizes.RecordTypes {
+		f.Assets[rt] = make(map[string][]Record)
+	}
+
+	numbers := make(map[string]int)
+	for c := 0; c < sizes.Channels; c++ {
+		space := Space{ID: randomUUID(r), Name: fmt.Sprintf("Channel %d", c+1)}
+		f.Spaces = append(f.Spaces, space)
+
+		users := make([]Record, 0, sizes.UsersPerChannel)
+		for u := 0; u < sizes.UsersPerChannel; u++ {
+			first, last := pick(r, firstNames), pick(r, lastNames)
+			users = append(users, Record{
+				"uuid":             randomUUID(r),
+				"full_name":        first + " " + last,
+				"email":            fmt.Sprintf("%s.%s.%d@%s", strings.ToLower(first), strings.ToLower(last), u+1, pick(r, emailDomains)),
+				"type":             pick(r, userTypes),
+				"org_display_name": space.Name + " organization",
+			})
+		}
+		f.Assets[userAsset][space.ID] = users
+
+		for _, rt := range sizes.RecordTypes {
+			tickets := make([]Record, 0, sizes.TicketsPerChannel)
+			for t := 0; t < sizes.TicketsPerChannel; t++ {
+				numbers[rt]++
+
+				createdAt := now.Add(-time.Duration(r.Intn(90*24)) * time.Hour)
+				updatedAt := createdAt.Add(time.Duration(r.Int63n(int64(now.Sub(createdAt)) + 1)))
+
+				record := Record{
+					"uuid":              randomUUID(r),
+					"number":            fmt.Sprintf("%s%07d", numberPrefix(rt), numbers[rt]),
+					"short_description": pick(r, descriptions),
+					"state_id":          r.Intn(7),
+					"location":          map[string]interface{}{"full_location": fmt.Sprintf("%s, %s %d", pick(r, cities), pick(r, streets), r.Intn(100)+1)},
+					"created_at":        createdAt.UTC().Format(time.RFC3339),
+					"updated_at":        updatedAt.UTC().Format(time.RFC3339),
+					"docType":           rt,
+				}
+
+				// some tickets are not assigned
+				if len(users) > 0 && r.Intn(10) > 0 {
+					assignee := users[r.Intn(len(users))]
+					record["assigned_to"] = map[string]interface{}{"uuid": assignee["uuid"], "full_name": assignee["full_name"]}
+				}
+
+				tickets = append(tickets, record)
+			}
+			f.Assets[rt][space.ID] = tickets
+		}
+	}
+
+	return f
+}
+
+// LoadFixtures loads the fixtures from the dir: spaces.json with the channels and <asset>/<channel ID>.json
+// with the records of the asset in the channel. It returns false if the dir does not contain the fixtures.
+func LoadFixtures(dir string) (Fixtures, bool, error) {
+	f := Fixtures{Assets: make(map[string]map[string][]Record)}
+
+	if err := readJSON(filepath.Join(dir, spacesFile), &f.Spaces); err != nil {
+		if os.IsNotExist(err) {
+			return f, false, nil
+		}
+		return f, false, err
+	}
+
+	assetDirs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return f, false, err
+	}
+
+	for _, assetDir := range assetDirs {
+		if !assetDir.IsDir() {
+			continue
+		}
+
+		asset := assetDir.Name()
+		f.Assets[asset] = make(map[string][]Record)
+
+		files, err := ioutil.ReadDir(filepath.Join(dir, asset))
+		if err != nil {
+			return f, false, err
+		}
+
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+				continue
+			}
+
+			var records []Record
+			if err := readJSON(filepath.Join(dir, asset, file.Name()), &records); err != nil {
+				return f, false, err
+			}
+			f.Assets[asset][strings.TrimSuffix(file.Name(), ".json")] = records
+		}
+	}
+
+	return f, true, nil
+}
+
+// Save writes the fixtures to the dir in the format read by LoadFixtures
+func (f Fixtures) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if err := writeJSON(filepath.Join(dir, spacesFile), f.Spaces); err != nil {
+		return err
+	}
+
+	for asset, channels := range f.Assets {
+		if err := os.MkdirAll(filepath.Join(dir, asset), 0755); err != nil {
+			return err
+		}
+
+		for channelID, records := range channels {
+			if err := writeJSON(filepath.Join(dir, asset, channelID+".json"), records); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// numberPrefix returns prefix of the ticket numbers of the record type, e.g. INC for incident
+func numberPrefix(recordType string) string {
+	switch recordType {
+	case "incident":
+		return "INC"
+	case "k_request":
+		return "REQ"
+	}
+
+	prefix := strings.ToUpper(strings.TrimPrefix(recordType, "k_"))
+	if len(prefix) > 3 {
+		prefix = prefix[:3]
+	}
+	return prefix
+}
+
+func randomUUID(r *rand.Rand) string {
+	b := make([]byte, 16)
+	_, _ = r.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+func readJSON(filename string, v interface{}) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("could not decode %s: %v", filename, err)
+	}
+
+	return nil
+}
+
+func writeJSON(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, data, 0644)
+}
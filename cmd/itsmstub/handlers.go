@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/email"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// channelHeader is the header with the channel of the ITSM asset queries
+const channelHeader = "grpc-metadata-space"
+
+type stubServer struct {
+	logger          *zap.SugaredLogger
+	fixtures        Fixtures
+	defaultPageSize int
+	emailDir        string
+	batches         int64
+}
+
+func (s *stubServer) routes() http.Handler {
+	r := httprouter.New()
+
+	r.GET("/api/v1/sub-spaces-by-app", s.getSpaces)
+	r.Handle(http.MethodOptions, "/api/v1/assets/:asset", s.queryAssets)
+	r.POST("/token", s.issueToken)
+	r.POST("/email/batch", s.sendEmailBatch)
+
+	return r
+}
+
+// getSpaces returns all channels
+func (s *stubServer) getSpaces(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"spaces": s.fixtures.Spaces})
+}
+
+// queryAssets returns the page of the records of the asset in the channel matching the selector of the query
+func (s *stubServer) queryAssets(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	asset := params.ByName("asset")
+	channels, ok := s.fixtures.Assets[asset]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("unknown asset %s", asset))
+		return
+	}
+
+	var query struct {
+		Selector map[string]interface{} `json:"selector"`
+		Limit    int                    `json:"limit"`
+		Bookmark string                 `json:"bookmark"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("could not decode query: %v", err))
+		return
+	}
+
+	var matching []Record
+	for _, record := range channels[r.Header.Get(channelHeader)] {
+		if matches(record, query.Selector) {
+			matching = append(matching, record)
+		}
+	}
+
+	// bookmark is the offset of the next page
+	offset := 0
+	if query.Bookmark != "" {
+		var err error
+		if offset, err = strconv.Atoi(query.Bookmark); err != nil || offset < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid bookmark %s", query.Bookmark))
+			return
+		}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = s.defaultPageSize
+	}
+
+	result := []Record{}
+	bookmark := ""
+	if offset < len(matching) {
+		end := offset + limit
+		if end < len(matching) {
+			bookmark = strconv.Itoa(end)
+		} else {
+			end = len(matching)
+		}
+		result = matching[offset:end]
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"result": result, "bookmark": bookmark})
+}
+
+// issueToken issues dummy token for any assertion token
+func (s *stubServer) issueToken(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	token := fmt.Sprintf("stub-token-%d", time.Now().UnixNano())
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"token":        token,
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+// sendEmailBatch saves the Postmark email batch to the email dir instead of sending it
+func (s *stubServer) sendEmailBatch(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("could not read email batch: %v", err))
+		return
+	}
+
+	var emails []email.Email
+	if err := json.Unmarshal(body, &emails); err != nil {
+		s.writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("could not decode email batch: %v", err))
+		return
+	}
+
+	if err := os.MkdirAll(s.emailDir, 0755); err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	batch := atomic.AddInt64(&s.batches, 1)
+	filename := filepath.Join(s.emailDir, fmt.Sprintf("batch-%s-%04d.json", time.Now().Format("20060102-150405"), batch))
+	if err := ioutil.WriteFile(filename, body, 0644); err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.logger.Infow("Email batch saved", "file", filename, "emails", len(emails))
+
+	responses := make([]email.Response, 0, len(emails))
+	for i, e := range emails {
+		responses = append(responses, email.Response{
+			To:          e.To,
+			SubmittedAt: time.Now(),
+			MessageID:   fmt.Sprintf("stub-%d-%d", batch, i+1),
+			Message:     "OK",
+		})
+	}
+
+	s.writeJSON(w, http.StatusOK, responses)
+}
+
+func (s *stubServer) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Errorw("Could not write response", "error", err)
+	}
+}
+
+func (s *stubServer) writeError(w http.ResponseWriter, status int, message string) {
+	s.logger.Warnw("Request failed", "status", status, "error", message)
+	s.writeJSON(w, status, map[string]interface{}{"ErrorCode": status, "Message": message})
+}
+
+// matches returns true if the record matches the Mango-like selector of the query. The stub supports $and,
+// $or and the comparison operators $eq, $ne, $gt, $gte, $lt and $lte of the fields addressed by dot separated paths.
+func matches(record Record, selector map[string]interface{}) bool {
+	for key, condition := range selector {
+		switch key {
+		case "$and", "$or":
+			subSelectors, _ := condition.([]interface{})
+			matched := 0
+			for _, sub := range subSelectors {
+				if subSelector, ok := sub.(map[string]interface{}); ok && matches(record, subSelector) {
+					matched++
+				}
+			}
+
+			if key == "$and" && matched != len(subSelectors) || key == "$or" && matched == 0 {
+				return false
+			}
+		default:
+			if !matchesField(lookup(record, key), condition) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func matchesField(value, condition interface{}) bool {
+	operators, ok := condition.(map[string]interface{})
+	if !ok {
+		return compare(value, condition) == 0
+	}
+
+	for op, operand := range operators {
+		c := compare(value, operand)
+		var result bool
+		switch op {
+		case "$eq":
+			result = c == 0
+		case "$ne":
+			result = c != 0
+		case "$gt":
+			result = c > 0 && c != incomparable
+		case "$gte":
+			result = c >= 0 && c != incomparable
+		case "$lt":
+			result = c < 0
+		case "$lte":
+			result = c <= 0
+		}
+
+		if !result {
+			return false
+		}
+	}
+
+	return true
+}
+
+// incomparable is the result of the comparison of the values of different types
+const incomparable = 2
+
+// compare compares numbers as numbers and other values as strings
+func compare(a, b interface{}) int {
+	if a == nil || b == nil {
+		if a == b {
+			return 0
+		}
+		return incomparable
+	}
+
+	fa, aNum := toFloat(a)
+	fb, bNum := toFloat(b)
+	if aNum && bNum {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// lookup returns the value addressed by dot separated path in the record
+func lookup(record Record, path string) interface{} {
+	var value interface{} = map[string]interface{}(record)
+
+	for _, name := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[name]
+	}
+
+	return value
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatches(t *testing.T) {
+	record := Record{
+		"number":   "INC0000001",
+		"state_id": float64(2),
+		"location": map[string]interface{}{"full_location": "Prague"},
+	}
+
+	cases := map[string]struct {
+		selector string
+		expected bool
+	}{
+		"empty selector":            {`{}`, true},
+		"equal value":               {`{"number":"INC0000001"}`, true},
+		"different value":           {`{"number":"INC0000002"}`, false},
+		"nested field":              {`{"location.full_location":"Prague"}`, true},
+		"missing field":             {`{"assigned_to.uuid":"u1"}`, false},
+		"$ne":                       {`{"state_id":{"$ne":4}}`, true},
+		"$gt and $lte":              {`{"state_id":{"$gt":1,"$lte":2}}`, true},
+		"$lt":                       {`{"state_id":{"$lt":2}}`, false},
+		"$gte of the missing field": {`{"updated_at":{"$gte":"2021-04-01T00:00:00Z"}}`, false},
+		"$and":                      {`{"$and":[{"state_id":{"$ne":4}},{"state_id":{"$ne":5}}]}`, true},
+		"$and with failing item":    {`{"$and":[{"state_id":{"$ne":4}},{"state_id":{"$ne":2}}]}`, false},
+		"$or":                       {`{"$or":[{"state_id":4},{"number":"INC0000001"}]}`, true},
+		"$or without matching item": {`{"$or":[{"state_id":4},{"state_id":5}]}`, false},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var selector map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(c.selector), &selector))
+
+			assert.Equal(t, c.expected, matches(record, selector))
+		})
+	}
+}
+
+func TestQueryAssets_Paging(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+
+	var incidents []Record
+	for _, number := range []string{"INC1", "INC2", "INC3", "INC4", "INC5"} {
+		incidents = append(incidents, Record{"number": number, "state_id": float64(0)})
+	}
+	incidents = append(incidents, Record{"number": "INC6", "state_id": float64(4)})
+
+	s := &stubServer{
+		logger:          logger,
+		fixtures:        Fixtures{Assets: map[string]map[string][]Record{"incident": {"ch1": incidents}}},
+		defaultPageSize: 3,
+	}
+
+	cases := map[string]struct {
+		query            string
+		expectedNumbers  []string
+		expectedBookmark string
+	}{
+		"first page of the default size": {`{"selector":{"state_id":0}}`, []string{"INC1", "INC2", "INC3"}, "3"},
+		"last page":                      {`{"selector":{"state_id":0},"bookmark":"3"}`, []string{"INC4", "INC5"}, ""},
+		"page of the limit":              {`{"selector":{"state_id":0},"limit":2,"bookmark":"2"}`, []string{"INC3", "INC4"}, "4"},
+		"limit of all records":           {`{"selector":{"state_id":0},"limit":5}`, []string{"INC1", "INC2", "INC3", "INC4", "INC5"}, ""},
+		"bookmark after the end":         {`{"selector":{"state_id":0},"bookmark":"10"}`, nil, ""},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/api/v1/assets/incident", strings.NewReader(c.query))
+			req.Header.Set(channelHeader, "ch1")
+
+			w := httptest.NewRecorder()
+			s.routes().ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var page struct {
+				Result   []Record `json:"result"`
+				Bookmark string   `json:"bookmark"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+			var numbers []string
+			for _, r := range page.Result {
+				numbers = append(numbers, r["number"].(string))
+			}
+			assert.Equal(t, c.expectedNumbers, numbers)
+			assert.Equal(t, c.expectedBookmark, page.Bookmark)
+		})
+	}
+
+	t.Run("invalid bookmark", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/assets/incident", strings.NewReader(`{"bookmark":"x"}`))
+
+		w := httptest.NewRecorder()
+		s.routes().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,47 @@
+// Command itsmstub serves stub ITSM endpoints (sub-spaces, users and tickets), dummy tokens of the assertion token
+// endpoint and the Postmark batch endpoint, so that the reporting service can run locally without external services.
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	l, _ := zap.NewProduction()
+	defer func(l *zap.Logger) {
+		_ = l.Sync()
+	}(l)
+
+	logger := l.Sugar()
+
+	config, err := loadEnvConfig()
+	if err != nil {
+		logger.Fatalw("Error loading configuration", "error", err)
+	}
+
+	fixtures, ok, err := LoadFixtures(config.FixturesDir)
+	if err != nil {
+		logger.Fatalw("Error loading fixtures", "dir", config.FixturesDir, "error", err)
+	}
+
+	if !ok {
+		fixtures = GenerateFixtures(config.Sizes, time.Now())
+		if err := fixtures.Save(config.FixturesDir); err != nil {
+			logger.Fatalw("Error saving generated fixtures", "dir", config.FixturesDir, "error", err)
+		}
+		logger.Infow("Fixtures generated", "dir", config.FixturesDir, "sizes", config.Sizes)
+	}
+
+	s := &stubServer{
+		logger:          logger,
+		fixtures:        fixtures,
+		defaultPageSize: config.DefaultPageSize,
+		emailDir:        config.EmailDir,
+	}
+
+	logger.Infof("Starting ITSM stub server at %s", config.HTTPBindAddress)
+	logger.Fatal(http.ListenAndServe(config.HTTPBindAddress, s.routes()))
+}
@@ -4,6 +4,10 @@ package channel
 type Channel struct {
 	ChannelID string
 	Name      string
+	// Timezone of the dates in the channel report, empty means the timezone of the recipient
+	Timezone string
+	// OwnerEmails receive the channel report with all open tickets of the channel
+	OwnerEmails []string
 }
 
 // List of channels
@@ -0,0 +1,75 @@
+package channel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config of the channel managed through the API, it is applied to the channel downloaded from the ITSM service
+type Config struct {
+	ChannelID string
+	// Enabled channels are processed by the jobs, disabled channels are skipped
+	Enabled bool
+	// DisplayName is shown in the reports instead of the name from ITSM, if not empty
+	DisplayName string
+	// Timezone of the dates in the channel report (e.g. "Europe/Prague"), empty means the timezone of the recipient
+	Timezone string
+	// OwnerEmails receive the channel report with all open tickets of the channel
+	OwnerEmails []string
+}
+
+// Validate returns error if the configuration contains invalid values
+func (c Config) Validate() error {
+	if c.ChannelID == "" {
+		return fmt.Errorf("channel ID must not be empty")
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone '%s'", c.Timezone)
+		}
+	}
+
+	for _, email := range c.OwnerEmails {
+		if !strings.Contains(email, "@") {
+			return fmt.Errorf("invalid owner email '%s'", email)
+		}
+	}
+
+	return nil
+}
+
+// Apply returns channels with the configurations applied: disabled channels are removed, display names, timezones and
+// owners are set. Channels without configuration are kept only if enabledByDefault is true.
+func Apply(channelList List, configs []Config, enabledByDefault bool) List {
+	byID := make(map[string]Config, len(configs))
+	for _, c := range configs {
+		byID[c.ChannelID] = c
+	}
+
+	var applied List
+	for _, ch := range channelList {
+		c, ok := byID[ch.ChannelID]
+		if !ok {
+			if enabledByDefault {
+				applied = append(applied, ch)
+			}
+			continue
+		}
+
+		if !c.Enabled {
+			continue
+		}
+
+		if c.DisplayName != "" {
+			ch.Name = c.DisplayName
+		}
+		ch.Timezone = c.Timezone
+		ch.OwnerEmails = c.OwnerEmails
+
+		applied = append(applied, ch)
+	}
+
+	return applied
+}
@@ -0,0 +1,48 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	ch1 := Channel{ChannelID: "ch1", Name: "First channel"}
+	ch2 := Channel{ChannelID: "ch2", Name: "Second channel"}
+	ch3 := Channel{ChannelID: "ch3", Name: "Third channel"}
+	channelList := List{ch1, ch2, ch3}
+
+	configs := []Config{
+		{
+			ChannelID:   "ch1",
+			Enabled:     true,
+			DisplayName: "First",
+			Timezone:    "Europe/Prague",
+			OwnerEmails: []string{"owner@email.test"},
+		},
+		{ChannelID: "ch2", Enabled: false},
+	}
+
+	first := Channel{ChannelID: "ch1", Name: "First", Timezone: "Europe/Prague", OwnerEmails: []string{"owner@email.test"}}
+
+	assert.Equal(t, List{first, ch3}, Apply(channelList, configs, true), "denylist: disabled channels are skipped")
+	assert.Equal(t, List{first}, Apply(channelList, configs, false), "allowlist: only enabled channels are processed")
+	assert.Equal(t, channelList, Apply(channelList, nil, true))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := Config{ChannelID: "ch1", Enabled: true, Timezone: "Europe/Prague", OwnerEmails: []string{"owner@email.test"}}
+	assert.NoError(t, c.Validate())
+
+	invalid := c
+	invalid.Timezone = "Mars/Olympus"
+	assert.Error(t, invalid.Validate())
+
+	invalid = c
+	invalid.OwnerEmails = []string{"owner"}
+	assert.Error(t, invalid.Validate())
+
+	invalid = c
+	invalid.ChannelID = ""
+	assert.Error(t, invalid.Validate())
+}
@@ -3,6 +3,7 @@ package channeldownloader
 import (
 	"context"
 
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository"
 )
 
@@ -15,16 +16,27 @@ type ChannelDownloader interface {
 	Close() error
 }
 
-func NewChannelDownloader(channelRepository repository.ChannelRepository, client ChannelClient) ChannelDownloader {
+// NewChannelDownloader creates channel downloader. Channel configurations are applied to the downloaded channels,
+// channels without configuration are processed only if enabledByDefault is true.
+func NewChannelDownloader(
+	channelRepository repository.ChannelRepository,
+	channelConfigRepository repository.ChannelConfigRepository,
+	client ChannelClient,
+	enabledByDefault bool,
+) ChannelDownloader {
 	return &channelDownloader{
-		client:            client,
-		channelRepository: channelRepository,
+		client:                  client,
+		channelRepository:       channelRepository,
+		channelConfigRepository: channelConfigRepository,
+		enabledByDefault:        enabledByDefault,
 	}
 }
 
 type channelDownloader struct {
-	client            ChannelClient
-	channelRepository repository.ChannelRepository
+	client                  ChannelClient
+	channelRepository       repository.ChannelRepository
+	channelConfigRepository repository.ChannelConfigRepository
+	enabledByDefault        bool
 }
 
 func (d *channelDownloader) DownloadChannelList(ctx context.Context) error {
@@ -33,6 +45,13 @@ func (d *channelDownloader) DownloadChannelList(ctx context.Context) error {
 		return err
 	}
 
+	configs, err := d.channelConfigRepository.ListChannelConfigs(ctx)
+	if err != nil {
+		return err
+	}
+
+	channelList = channel.Apply(channelList, configs, d.enabledByDefault)
+
 	if err := d.channelRepository.StoreChannelList(ctx, channelList); err != nil {
 		return err
 	}
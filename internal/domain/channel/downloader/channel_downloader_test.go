@@ -0,0 +1,65 @@
+package channeldownloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelDownloader_AppliesConfigs(t *testing.T) {
+	ctx := context.Background()
+
+	ch1 := channel.Channel{ChannelID: "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01", Name: "First channel"}
+	ch2 := channel.Channel{ChannelID: "5a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c02", Name: "Second channel"}
+	ch3 := channel.Channel{ChannelID: "7c9e6679-7425-40de-944b-e07fc1f90a03", Name: "Third channel"}
+
+	channelConfigRepository := memory.NewChannelConfigRepositoryMemory()
+	require.NoError(t, channelConfigRepository.SaveChannelConfig(ctx, channel.Config{
+		ChannelID:   ch1.ChannelID,
+		Enabled:     true,
+		DisplayName: "Support",
+		Timezone:    "Europe/Prague",
+		OwnerEmails: []string{"owner@email.test"},
+	}))
+	require.NoError(t, channelConfigRepository.SaveChannelConfig(ctx, channel.Config{ChannelID: ch2.ChannelID}))
+
+	for _, tt := range []struct {
+		name             string
+		enabledByDefault bool
+		expected         channel.List
+	}{
+		{
+			name:             "unconfigured channels enabled",
+			enabledByDefault: true,
+			expected: channel.List{
+				{ChannelID: ch1.ChannelID, Name: "Support", Timezone: "Europe/Prague", OwnerEmails: []string{"owner@email.test"}},
+				ch3,
+			},
+		},
+		{
+			name:             "unconfigured channels disabled",
+			enabledByDefault: false,
+			expected: channel.List{
+				{ChannelID: ch1.ChannelID, Name: "Support", Timezone: "Europe/Prague", OwnerEmails: []string{"owner@email.test"}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			channelClient := new(mocks.ChannelClientMock)
+			channelClient.On("GetChannels").Return(channel.List{ch1, ch2, ch3}, nil).Once()
+
+			channelRepository := memory.NewChannelRepositoryMemory()
+			d := NewChannelDownloader(channelRepository, channelConfigRepository, channelClient, tt.enabledByDefault)
+			require.NoError(t, d.DownloadChannelList(ctx))
+
+			channelList, err := channelRepository.GetChannelList(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, channelList)
+		})
+	}
+}
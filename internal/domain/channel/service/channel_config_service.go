@@ -0,0 +1,72 @@
+package chansvc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// NewChannelConfigService creates the channel configuration service
+func NewChannelConfigService(channelConfigRepository repository.ChannelConfigRepository) ChannelConfigService {
+	return &channelConfigService{
+		repo: channelConfigRepository,
+	}
+}
+
+type channelConfigService struct {
+	repo repository.ChannelConfigRepository
+}
+
+func (s channelConfigService) ListChannelConfigs(ctx context.Context) ([]channel.Config, error) {
+	return s.repo.ListChannelConfigs(ctx)
+}
+
+func (s channelConfigService) GetChannelConfig(ctx context.Context, channelID string) (channel.Config, error) {
+	c, err := s.repo.GetChannelConfig(ctx, channelID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return c, domain.WrapErrorf(err, domain.ErrorCodeNotFound, "channel '%s' is not configured", channelID)
+	}
+
+	return c, err
+}
+
+func (s channelConfigService) SaveChannelConfig(
+	ctx context.Context, channelID string, params api.SaveChannelConfigParams,
+) (channel.Config, error) {
+	c := channel.Config{
+		ChannelID:   channelID,
+		Enabled:     params.Enabled == nil || *params.Enabled,
+		DisplayName: strings.TrimSpace(params.DisplayName),
+		Timezone:    strings.TrimSpace(params.Timezone),
+	}
+
+	for _, email := range params.OwnerEmails {
+		if email = strings.TrimSpace(email); email != "" {
+			c.OwnerEmails = append(c.OwnerEmails, email)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return c, domain.WrapErrorf(err, domain.ErrorCodeInvalidArgument, "invalid configuration of channel '%s'", channelID)
+	}
+
+	if err := s.repo.SaveChannelConfig(ctx, c); err != nil {
+		return c, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not save configuration of channel '%s'", channelID)
+	}
+
+	return c, nil
+}
+
+func (s channelConfigService) DeleteChannelConfig(ctx context.Context, channelID string) error {
+	err := s.repo.DeleteChannelConfig(ctx, channelID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.WrapErrorf(err, domain.ErrorCodeNotFound, "channel '%s' is not configured", channelID)
+	}
+
+	return err
+}
@@ -0,0 +1,23 @@
+package chansvc
+
+import (
+	"context"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+)
+
+// ChannelConfigService provides operations with the channel configurations
+type ChannelConfigService interface {
+	// ListChannelConfigs returns configurations of all configured channels
+	ListChannelConfigs(ctx context.Context) ([]channel.Config, error)
+
+	// GetChannelConfig returns configuration of the channel
+	GetChannelConfig(ctx context.Context, channelID string) (channel.Config, error)
+
+	// SaveChannelConfig creates or replaces configuration of the channel
+	SaveChannelConfig(ctx context.Context, channelID string, params api.SaveChannelConfigParams) (channel.Config, error)
+
+	// DeleteChannelConfig removes configuration of the channel, the channel is then processed by default
+	DeleteChannelConfig(ctx context.Context, channelID string) error
+}
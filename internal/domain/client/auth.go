@@ -0,0 +1,261 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+)
+
+// AuthProvider selects the TokenSvcClient implementation
+type AuthProvider string
+
+// AuthProvider values
+const (
+	// AuthAssertion exchanges the assertion token for auth tokens (tokget)
+	AuthAssertion AuthProvider = "assertion"
+	// AuthStatic uses the configured token
+	AuthStatic AuthProvider = "static"
+	// AuthClientCredentials gets the tokens by OAuth2 client credentials grant
+	AuthClientCredentials AuthProvider = "client_credentials"
+	// AuthFile reads the token from the file, e.g. the mounted secret, and re-reads it when the file changes
+	AuthFile AuthProvider = "file"
+)
+
+// AuthConfig configures the auth provider of the calls to external services
+type AuthConfig struct {
+	Provider AuthProvider
+
+	// Assertion is the config of the assertion provider
+	Assertion Config
+	// StaticToken is the token of the static provider
+	StaticToken string
+	// OAuth2 is the config of the client credentials provider
+	OAuth2 OAuth2Config
+	// TokenFile is the file of the file provider
+	TokenFile string
+}
+
+// OAuth2Config configures the OAuth2 client credentials grant
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Audience is sent as the audience parameter if set (required by some authorization servers)
+	Audience string
+}
+
+// NewAuthTokenSvcClient returns token client of the configured provider. The client credentials provider requests
+// the tokens with httpClient (nil = http.DefaultClient).
+func NewAuthTokenSvcClient(cfg AuthConfig, httpClient *http.Client) (TokenSvcClient, error) {
+	switch cfg.Provider {
+	case AuthAssertion, "":
+		return NewTokenSvcClient(cfg.Assertion)
+	case AuthStatic:
+		return NewStaticTokenSvcClient(cfg.StaticToken)
+	case AuthClientCredentials:
+		return NewClientCredentialsTokenSvcClient(cfg.OAuth2, httpClient)
+	case AuthFile:
+		return NewFileTokenSvcClient(cfg.TokenFile)
+	}
+
+	return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "unknown auth provider '%s'", cfg.Provider)
+}
+
+// bearer returns the value of the authorization header with the token, the token may already contain the scheme
+func bearer(token string) string {
+	if strings.Contains(token, " ") {
+		return token
+	}
+
+	return "Bearer " + token
+}
+
+// NewStaticTokenSvcClient returns token client that always returns the token
+func NewStaticTokenSvcClient(token string) (TokenSvcClient, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "static token is empty")
+	}
+
+	return &staticTokenSvcClient{
+		token: bearer(token),
+	}, nil
+}
+
+type staticTokenSvcClient struct {
+	token string
+}
+
+func (c staticTokenSvcClient) GetToken() (string, error) {
+	return c.token, nil
+}
+
+const (
+	// defaultTokenLifetime is the cache lifetime of the OAuth2 tokens without expires_in
+	defaultTokenLifetime = 5 * time.Minute
+	// tokenExpiryMargin is how long before its expiry the OAuth2 token is refreshed
+	tokenExpiryMargin = 30 * time.Second
+)
+
+// NewClientCredentialsTokenSvcClient returns token client getting the tokens by OAuth2 client credentials grant.
+// The token is cached and refreshed shortly before it expires.
+func NewClientCredentialsTokenSvcClient(cfg OAuth2Config, httpClient *http.Client) (TokenSvcClient, error) {
+	if cfg.TokenURL == "" || cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "token URL, client ID and client secret must be set")
+	}
+
+	if _, err := url.ParseRequestURI(cfg.TokenURL); err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeInvalidArgument, "invalid token URL")
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &clientCredentialsTokenSvcClient{
+		cfg:    cfg,
+		client: httpClient,
+	}, nil
+}
+
+type clientCredentialsTokenSvcClient struct {
+	cfg    OAuth2Config
+	client *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (c *clientCredentialsTokenSvcClient) GetToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		return c.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	}
+	if c.cfg.Audience != "" {
+		form.Set("audience", c.cfg.Audience)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not create token request")
+	}
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get token")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read token response")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", domain.NewErrorf(domain.ErrorCodeUnknown, "token endpoint responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode token response")
+	}
+
+	if payload.AccessToken == "" {
+		return "", domain.NewErrorf(domain.ErrorCodeUnknown, "token response contains no access token")
+	}
+
+	lifetime := defaultTokenLifetime
+	if payload.ExpiresIn > 0 {
+		lifetime = time.Duration(payload.ExpiresIn) * time.Second
+	}
+	if lifetime > 2*tokenExpiryMargin {
+		lifetime -= tokenExpiryMargin
+	} else {
+		lifetime /= 2
+	}
+
+	c.token = "Bearer " + payload.AccessToken
+	if payload.TokenType != "" && !strings.EqualFold(payload.TokenType, "bearer") {
+		c.token = payload.TokenType + " " + payload.AccessToken
+	}
+	c.expiresAt = time.Now().Add(lifetime)
+
+	return c.token, nil
+}
+
+// NewFileTokenSvcClient returns token client reading the token from the file. The file is re-read when its
+// modification time or size changes, so that the rotated token is used without restart.
+func NewFileTokenSvcClient(filename string) (TokenSvcClient, error) {
+	c := &fileTokenSvcClient{
+		filename: filename,
+	}
+
+	// the file must be readable at startup
+	if _, err := c.GetToken(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+type fileTokenSvcClient struct {
+	filename string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func (c *fileTokenSvcClient) GetToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(c.filename)
+	if err != nil {
+		return "", domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read token file")
+	}
+
+	if c.token != "" && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return c.token, nil
+	}
+
+	data, err := ioutil.ReadFile(c.filename)
+	if err != nil {
+		return "", domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read token file")
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", domain.NewErrorf(domain.ErrorCodeUnknown, "token file %s is empty", c.filename)
+	}
+
+	c.token = bearer(token)
+	c.modTime = info.ModTime()
+	c.size = info.Size()
+
+	return c.token, nil
+}
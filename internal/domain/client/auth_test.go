@@ -0,0 +1,144 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+)
+
+func TestNewAuthTokenSvcClient_Static(t *testing.T) {
+	c, err := client.NewAuthTokenSvcClient(client.AuthConfig{Provider: client.AuthStatic, StaticToken: " abc\n"}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	token, err := c.GetToken()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if token != "Bearer abc" {
+		t.Fatalf("expected bearer token, got %q", token)
+	}
+
+	if _, err := client.NewAuthTokenSvcClient(client.AuthConfig{Provider: client.AuthStatic}, nil); err == nil {
+		t.Fatal("expected error for empty static token")
+	}
+	if _, err := client.NewAuthTokenSvcClient(client.AuthConfig{Provider: "kerberos"}, nil); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+}
+
+func TestNewAuthTokenSvcClient_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	filename := filepath.Join(dir, "token")
+
+	if _, err := client.NewAuthTokenSvcClient(client.AuthConfig{Provider: client.AuthFile, TokenFile: filename}, nil); err == nil {
+		t.Fatal("expected error for missing token file")
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("first\n"), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	c, err := client.NewAuthTokenSvcClient(client.AuthConfig{Provider: client.AuthFile, TokenFile: filename}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if token, _ := c.GetToken(); token != "Bearer first" {
+		t.Fatalf("expected first token, got %q", token)
+	}
+
+	// rotated token is read again
+	if err := ioutil.WriteFile(filename, []byte("Bearer second"), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if token, _ := c.GetToken(); token != "Bearer second" {
+		t.Fatalf("expected second token, got %q", token)
+	}
+}
+
+func TestNewAuthTokenSvcClient_ClientCredentials(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "reporting" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "itsm.read users.read" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token-1","token_type":"bearer","expires_in":1}`))
+	}))
+	defer ts.Close()
+
+	cfg := client.AuthConfig{
+		Provider: client.AuthClientCredentials,
+		OAuth2: client.OAuth2Config{
+			TokenURL:     ts.URL,
+			ClientID:     "reporting",
+			ClientSecret: "s3cret",
+			Scopes:       []string{"itsm.read", "users.read"},
+		},
+	}
+
+	c, err := client.NewAuthTokenSvcClient(cfg, ts.Client())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		token, err := c.GetToken()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if token != "Bearer token-1" {
+			t.Fatalf("expected bearer token, got %q", token)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected cached token, got %d token requests", requests)
+	}
+
+	// the token is refreshed before it expires
+	time.Sleep(600 * time.Millisecond)
+	if _, err := c.GetToken(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected refreshed token, got %d token requests", requests)
+	}
+
+	// invalid credentials
+	cfg.OAuth2.ClientSecret = "wrong"
+	c, err = client.NewAuthTokenSvcClient(cfg, ts.Client())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := c.GetToken(); err == nil {
+		t.Fatal("expected error for invalid credentials")
+	}
+}
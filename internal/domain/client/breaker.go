@@ -0,0 +1,182 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrCircuitOpen is returned without calling the external service while its circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open, external service is unavailable")
+
+// BreakerState is the state of the circuit breaker
+type BreakerState string
+
+// BreakerState values
+const (
+	// BreakerClosed lets all requests through
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen fails all requests fast without calling the external service
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets one trial request through to find out if the external service is available again
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// breakerStateValues are the values of the circuit breaker state metric
+var breakerStateValues = map[BreakerState]float64{
+	BreakerClosed:   0,
+	BreakerHalfOpen: 1,
+	BreakerOpen:     2,
+}
+
+// CircuitBreaker stops calling the external service endpoint after failureThreshold consecutive failures
+// (connection errors, 429 and 5xx responses). While the breaker is open, requests fail fast with ErrCircuitOpen.
+// After openTimeout one trial request is let through, its success closes the breaker, its failure opens it again.
+type CircuitBreaker struct {
+	endpoint         string
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int       // consecutive failures
+	openedAt time.Time // when the breaker was opened
+	trial    bool      // trial request of the half-open breaker is in progress
+
+	stateGauge prometheus.Gauge
+	openCount  prometheus.Counter
+}
+
+// NewCircuitBreaker creates closed circuit breaker of the external service endpoint. The endpoint labels the breaker's
+// metrics.
+func NewCircuitBreaker(endpoint string, failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	b := &CircuitBreaker{
+		endpoint:         endpoint,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		state:            BreakerClosed,
+		stateGauge:       breakerStateGauge.WithLabelValues(endpoint),
+		openCount:        breakerOpenCounter.WithLabelValues(endpoint),
+	}
+
+	b.stateGauge.Set(breakerStateValues[BreakerClosed])
+
+	return b
+}
+
+// Endpoint returns the endpoint guarded by the breaker
+func (b *CircuitBreaker) Endpoint() string {
+	return b.endpoint
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openTimeout {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// Allow returns ErrCircuitOpen if the request must not be sent. Nil breaker allows all requests.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.setState(BreakerHalfOpen)
+		b.trial = true
+		return nil
+	case BreakerHalfOpen:
+		if b.trial {
+			return ErrCircuitOpen
+		}
+		b.trial = true
+		return nil
+	}
+
+	return nil
+}
+
+// Success records the successful request and closes the breaker
+func (b *CircuitBreaker) Success() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+	b.setState(BreakerClosed)
+}
+
+// Failure records the failed request, the breaker opens after too many consecutive failures or a failed trial request
+func (b *CircuitBreaker) Failure() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		if b.state != BreakerOpen {
+			b.openCount.Inc()
+		}
+		b.openedAt = time.Now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// Cancel records the allowed request that was not finished (e.g. its context was cancelled), neither success nor
+// failure is counted and the trial request of the half-open breaker may be sent again
+func (b *CircuitBreaker) Cancel() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+func (b *CircuitBreaker) setState(state BreakerState) {
+	b.state = state
+	b.stateGauge.Set(breakerStateValues[state])
+}
+
+// Circuit breaker metrics labeled by the endpoint
+var (
+	breakerStateGauge = registerGaugeVec(prometheus.GaugeOpts{
+		Name: "reporting_service_client_circuit_breaker_state",
+		Help: "The state of the circuit breaker of the external service endpoint (0 = closed, 1 = half-open, 2 = open)",
+	}, "endpoint")
+
+	breakerOpenCounter = registerCounterVec(prometheus.CounterOpts{
+		Name: "reporting_service_client_circuit_breaker_opened_total",
+		Help: "The total number of times the circuit breaker of the external service endpoint opened",
+	}, "endpoint")
+)
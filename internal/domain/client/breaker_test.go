@@ -0,0 +1,147 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := client.NewCircuitBreaker("test-breaker", 2, 50*time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	b.Failure()
+	if b.State() != client.BreakerClosed {
+		t.Fatalf("expected closed breaker after one failure, got %s", b.State())
+	}
+
+	b.Failure()
+	if b.State() != client.BreakerOpen {
+		t.Fatalf("expected open breaker after two failures, got %s", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// after the timeout one trial request is let through
+	time.Sleep(60 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen during the trial request, got %v", err)
+	}
+
+	// failed trial opens the breaker again
+	b.Failure()
+	if b.State() != client.BreakerOpen {
+		t.Fatalf("expected open breaker after failed trial, got %s", b.State())
+	}
+
+	// successful trial closes the breaker
+	time.Sleep(60 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	b.Success()
+	if b.State() != client.BreakerClosed {
+		t.Fatalf("expected closed breaker after successful trial, got %s", b.State())
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cl := client.NewHTTPClient(ts.URL, logger, new(tokenSvcClientMock))
+	cl.RetryWaitMin = time.Millisecond
+	cl.RetryWaitMax = time.Millisecond
+	cl.RetryMax = 10
+	cl.Breaker = client.NewCircuitBreaker(ts.URL, 3, time.Minute)
+
+	// the breaker opens after 3 failures and stops the retries
+	if _, err := cl.Get(context.Background(), ""); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 requests before the breaker opened, got %d", requests)
+	}
+
+	// the next call fails fast
+	if _, err := cl.Get(context.Background(), ""); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if requests != 3 {
+		t.Fatalf("expected no request while the breaker is open, got %d", requests)
+	}
+}
+
+func TestClient_CircuitBreakerCancelledRequests(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	var block int32 = 1
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&block) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	cl := client.NewHTTPClient(ts.URL, logger, new(tokenSvcClientMock))
+	cl.RetryMax = 0
+	cl.Limiter = client.NewLimiter("test-breaker-cancelled", 0, 1)
+	cl.Breaker = client.NewCircuitBreaker(ts.URL, 1, 10*time.Millisecond)
+
+	// open the breaker and let the timeout pass, the next request is the trial of the half-open breaker
+	cl.Breaker.Failure()
+	time.Sleep(20 * time.Millisecond)
+
+	// the request cancelled while waiting for the limiter does not take the trial
+	if err := cl.Limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	if _, err := cl.Get(ctx, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	cancel()
+	cl.Limiter.Release()
+
+	// the trial request cancelled while in flight is neither success nor failure
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	if _, err := cl.Get(ctx, ""); errors.Is(err, client.ErrCircuitOpen) || err == nil {
+		t.Fatalf("expected cancelled request, got %v", err)
+	}
+	cancel()
+	if state := cl.Breaker.State(); state != client.BreakerHalfOpen {
+		t.Fatalf("expected half-open breaker after cancelled trial, got %s", state)
+	}
+
+	// the next trial request is let through and closes the breaker
+	atomic.StoreInt32(&block, 0)
+	if _, err := cl.Get(context.Background(), ""); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if state := cl.Breaker.State(); state != client.BreakerClosed {
+		t.Fatalf("expected closed breaker after successful trial, got %s", state)
+	}
+}
@@ -7,9 +7,13 @@ import (
 	"io/ioutil"
 	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +25,9 @@ type Client interface {
 	// Query gets data from external service using OPTIONS method
 	Query(ctx context.Context, channelID string, body io.ReadSeeker) (*http.Response, error)
 
+	// URL returns the URL of the external service endpoint
+	URL() string
+
 	// Close closes client connections
 	Close() error
 }
@@ -66,6 +73,13 @@ type HTTPClient struct {
 
 	// Backoff specifies the policy for how long to wait between retries
 	Backoff Backoff
+
+	// Limiter limits the rate and the number of in-flight requests, it may be shared with other clients (nil = no limits).
+	// The request holds its in-flight slot until the response body is read to the end or closed.
+	Limiter *Limiter
+
+	// Breaker fails the requests fast while the endpoint is unavailable (nil = no circuit breaker)
+	Breaker *CircuitBreaker
 }
 
 func (c HTTPClient) Get(ctx context.Context, channelID string) (*http.Response, error) {
@@ -86,13 +100,19 @@ func (c HTTPClient) Query(ctx context.Context, channelID string, body io.ReadSee
 	return c.doRequest(channelID, req)
 }
 
+func (c HTTPClient) URL() string {
+	return c.url
+}
+
 func (c *HTTPClient) Close() error {
 	c.CloseIdleConnections()
 	return nil
 }
 
 func (c HTTPClient) doRequest(channelID string, req *Request) (*http.Response, error) {
+	_, span := tracing.Start(req.Context(), "auth token")
 	authToken, err := c.tokenSvcClient.GetToken()
+	tracing.End(span, err)
 	if err != nil {
 		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get auth token")
 	}
@@ -140,8 +160,61 @@ func (c *HTTPClient) Do(req *Request) (*http.Response, error) {
 			}
 		}
 
-		// attempt the request
+		// each attempt is traced, including the wait for the limiter
+		ctx, span := tracing.Start(req.Context(), "HTTP "+req.Method,
+			semconv.HTTPMethodKey.String(req.Method),
+			semconv.HTTPURLKey.String(req.URL.String()),
+			tracing.ChannelIDKey.String(req.Header.Get("grpc-metadata-space")),
+			tracing.AttemptKey.Int(attempt),
+		)
+		tracing.Inject(ctx, req.Header)
+
+		// attempt the request when the limiter allows it
+		if err := c.Limiter.Wait(req.Context()); err != nil {
+			tracing.End(span, err)
+			return nil, err
+		}
+
+		// fail fast while the endpoint is unavailable, the breaker is asked only when the request is really sent
+		if err := c.Breaker.Allow(); err != nil {
+			c.Limiter.Release()
+			tracing.End(span, err)
+			return nil, fmt.Errorf("%s %s giving up after %d attempt(s): %w", req.Method, req.URL, attempt-1, err)
+		}
+
+		// the request is in flight until its response body is consumed or closed
 		resp, doErr = c.Client.Do(req.Request)
+		if resp != nil {
+			resp.Body = &releasingBody{ReadCloser: resp.Body, limiter: c.Limiter}
+		} else {
+			c.Limiter.Release()
+		}
+
+		if resp != nil {
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+		}
+		tracing.End(span, doErr)
+
+		switch {
+		case doErr != nil && req.Context().Err() != nil:
+			// cancelled request says nothing about the availability of the endpoint
+			c.Breaker.Cancel()
+		case doErr != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+			c.Breaker.Failure()
+		default:
+			c.Breaker.Success()
+		}
+
+		if doErr == nil {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				c.Limiter.Throttle()
+				c.logger.Warnf("HTTPClient request %s %s throttled, slowing down to %.2f requests per second",
+					req.Method, req.URL, c.Limiter.RequestsPerSecond())
+			} else if resp.StatusCode == http.StatusOK {
+				c.Limiter.Recover()
+			}
+		}
 
 		// check if we should continue with retries
 		shouldRetry, checkErr = c.CheckRetry(context.Background(), resp, doErr)
@@ -223,6 +296,31 @@ func (c *HTTPClient) drainBody(body io.ReadCloser) {
 	}
 }
 
+// releasingBody is the response body releasing the limiter slot of the request once the body is read to the end
+// or closed
+type releasingBody struct {
+	io.ReadCloser
+	limiter *Limiter
+	once    sync.Once
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+func (b *releasingBody) release() {
+	b.once.Do(b.limiter.Release)
+}
+
 // Request wraps the metadata needed to create HTTP requests.
 type Request struct {
 	// body is a seekable reader over the request body payload. This is
@@ -289,7 +387,8 @@ func NewRequestWithContext(ctx context.Context, method, url string, body io.Read
 // response body before returning.
 type CheckRetry func(ctx context.Context, resp *http.Response, err error) (bool, error)
 
-// DefaultRetryPolicy provides a default callback for client.CheckRetry, which will retry on connection errors and server errors
+// DefaultRetryPolicy provides a default callback for client.CheckRetry, which will retry on connection errors, server errors
+// and 408 or 429 responses. Other 4xx responses are permanent errors and are not retried.
 func DefaultRetryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
 	// do not retry on context.Canceled or context.DeadlineExceeded
 	if ctx.Err() != nil {
@@ -300,8 +399,17 @@ func DefaultRetryPolicy(ctx context.Context, resp *http.Response, err error) (bo
 		return true, nil
 	}
 
+	if resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests {
+		return true, nil
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		// the request is wrong (bad request, forbidden...), repeating it would not help
+		return false, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		// we assume that anything except 200 Ok is an error => retry
+		// we assume that anything else except 200 Ok is an error => retry
 		return true, nil
 	}
 
@@ -314,7 +422,17 @@ type Backoff func(min, max time.Duration, attemptNum int, resp *http.Response) t
 
 // DefaultBackoff provides a default callback for client.Backoff which will perform exponential backoff
 // based on the attempt number and limited by the provided minimum and maximum durations.
-func DefaultBackoff(min, max time.Duration, attemptNum int, _ *http.Response) time.Duration {
+// If the 429 or 503 response contains Retry-After header, it waits as long as the header says, at most max.
+func DefaultBackoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if wait > max {
+				wait = max
+			}
+			return wait
+		}
+	}
+
 	mult := math.Pow(2, float64(attemptNum)) * float64(min)
 	sleep := time.Duration(mult)
 	if float64(sleep) != mult || sleep > max {
@@ -322,3 +440,28 @@ func DefaultBackoff(min, max time.Duration, attemptNum int, _ *http.Response) ti
 	}
 	return sleep
 }
+
+// retryAfter returns how long to wait according to the Retry-After header value, which is either the number of seconds
+// or HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
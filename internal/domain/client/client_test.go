@@ -188,3 +188,60 @@ func TestBackoff(t *testing.T) {
 		}
 	}
 }
+
+func TestBackoff_RetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+
+	resp.Header.Set("Retry-After", "7")
+	if v := client.DefaultBackoff(time.Second, 30*time.Second, 0, resp); v != 7*time.Second {
+		t.Fatalf("expected 7s from Retry-After seconds, got %s", v)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if v := client.DefaultBackoff(time.Second, 5*time.Minute, 0, resp); v < 58*time.Second || v > time.Minute {
+		t.Fatalf("expected about 1m from Retry-After date, got %s", v)
+	}
+
+	resp.Header.Set("Retry-After", "86400")
+	if v := client.DefaultBackoff(time.Second, 5*time.Second, 0, resp); v != 5*time.Second {
+		t.Fatalf("expected Retry-After limited by the maximum wait, got %s", v)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if v := client.DefaultBackoff(time.Second, 5*time.Second, 0, resp); v != 5*time.Second {
+		t.Fatalf("expected Retry-After date limited by the maximum wait, got %s", v)
+	}
+
+	resp.Header.Set("Retry-After", "soon")
+	if v := client.DefaultBackoff(time.Second, 5*time.Second, 1, resp); v != 2*time.Second {
+		t.Fatalf("expected exponential backoff for invalid Retry-After, got %s", v)
+	}
+
+	resp = &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{"Retry-After": {"7"}}}
+	if v := client.DefaultBackoff(time.Second, 5*time.Second, 0, resp); v != time.Second {
+		t.Fatalf("expected Retry-After to be ignored for 500, got %s", v)
+	}
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	cases := map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusBadRequest:          false,
+		http.StatusForbidden:           false,
+		http.StatusNotFound:            false,
+		http.StatusRequestTimeout:      true,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+	}
+
+	for code, expected := range cases {
+		retry, err := client.DefaultRetryPolicy(context.Background(), &http.Response{StatusCode: code}, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if retry != expected {
+			t.Fatalf("status %d: expected retry %v, got %v", code, expected, retry)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// throttledRequestsPerSecond is the rate the unlimited limiter starts slowing down from when it sees 429s
+	throttledRequestsPerSecond = 10
+	// minRequestsPerSecond is the lowest rate the limiter slows down to
+	minRequestsPerSecond = 0.5
+)
+
+// Limiter limits the rate and the number of in-flight requests to the external service. One limiter may be shared
+// by several clients calling the same service, then the limits apply to all of them together.
+// The limiter halves the rate when the service responds with 429 Too Many Requests and gradually returns
+// to the configured rate with the successful responses.
+type Limiter struct {
+	maxRate float64       // configured requests per second, 0 = unlimited
+	slots   chan struct{} // in-flight requests, nil = unlimited
+
+	mu   sync.Mutex
+	rate float64   // current requests per second, 0 = unlimited
+	next time.Time // time when the next request may start
+
+	rateGauge      prometheus.Gauge
+	inFlightGauge  prometheus.Gauge
+	throttledCount prometheus.Counter
+}
+
+// NewLimiter creates limiter allowing requestsPerSecond requests per second with at most maxInFlight requests
+// at the same time; zero means no limit. The name labels the limiter's metrics.
+func NewLimiter(name string, requestsPerSecond float64, maxInFlight int) *Limiter {
+	l := &Limiter{
+		maxRate:        requestsPerSecond,
+		rate:           requestsPerSecond,
+		rateGauge:      limiterRateGauge.WithLabelValues(name),
+		inFlightGauge:  limiterInFlightGauge.WithLabelValues(name),
+		throttledCount: limiterThrottledCounter.WithLabelValues(name),
+	}
+
+	if maxInFlight > 0 {
+		l.slots = make(chan struct{}, maxInFlight)
+	}
+
+	l.rateGauge.Set(requestsPerSecond)
+	limiterMaxInFlightGauge.WithLabelValues(name).Set(float64(maxInFlight))
+
+	return l
+}
+
+// Wait blocks until the request may be sent or ctx is done. Release must be called when the request has finished.
+// Nil limiter does not limit anything.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	l.inFlightGauge.Inc()
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		l.Release()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Release frees the in-flight request slot taken by Wait
+func (l *Limiter) Release() {
+	if l == nil {
+		return
+	}
+
+	if l.slots != nil {
+		<-l.slots
+	}
+	l.inFlightGauge.Dec()
+}
+
+// Throttle slows the limiter down after the service responded with 429 Too Many Requests
+func (l *Limiter) Throttle() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate == 0 {
+		l.rate = throttledRequestsPerSecond
+	}
+
+	l.rate /= 2
+	if l.rate < minRequestsPerSecond {
+		l.rate = minRequestsPerSecond
+	}
+
+	l.throttledCount.Inc()
+	l.rateGauge.Set(l.rate)
+}
+
+// Recover speeds the throttled limiter up after a successful response, up to the configured rate
+func (l *Limiter) Recover() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate == l.maxRate {
+		return
+	}
+
+	if l.maxRate == 0 {
+		l.rate++
+		if l.rate >= throttledRequestsPerSecond {
+			l.rate = 0
+		}
+	} else {
+		l.rate += l.maxRate / 10
+		if l.rate > l.maxRate {
+			l.rate = l.maxRate
+		}
+	}
+
+	l.rateGauge.Set(l.rate)
+}
+
+// RequestsPerSecond returns the current rate limit, 0 = unlimited
+func (l *Limiter) RequestsPerSecond() float64 {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
+
+// reserve reserves the time slot for the next request and returns how long to wait for it
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(float64(time.Second) / l.rate))
+
+	return delay
+}
+
+// Limiter metrics labeled by the limiter name
+var (
+	limiterRateGauge = registerGaugeVec(prometheus.GaugeOpts{
+		Name: "reporting_service_client_requests_per_second_limit",
+		Help: "The current limit of requests per second to the external service (0 = unlimited)",
+	}, "limiter")
+
+	limiterMaxInFlightGauge = registerGaugeVec(prometheus.GaugeOpts{
+		Name: "reporting_service_client_max_in_flight_requests",
+		Help: "The maximum number of in-flight requests to the external service (0 = unlimited)",
+	}, "limiter")
+
+	limiterInFlightGauge = registerGaugeVec(prometheus.GaugeOpts{
+		Name: "reporting_service_client_in_flight_requests",
+		Help: "The number of in-flight requests to the external service",
+	}, "limiter")
+
+	limiterThrottledCounter = registerCounterVec(prometheus.CounterOpts{
+		Name: "reporting_service_client_throttled_total",
+		Help: "The total number of 429 Too Many Requests responses slowing the client down",
+	}, "limiter")
+)
+
+func registerGaugeVec(opts prometheus.GaugeOpts, label string) *prometheus.GaugeVec {
+	gauge := prometheus.NewGaugeVec(opts, []string{label})
+
+	if err := prometheus.Register(gauge); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.GaugeVec)
+		}
+		panic(err)
+	}
+
+	return gauge
+}
+
+func registerCounterVec(opts prometheus.CounterOpts, label string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(opts, []string{label})
+
+	if err := prometheus.Register(counter); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.CounterVec)
+		}
+		panic(err)
+	}
+
+	return counter
+}
@@ -0,0 +1,181 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+)
+
+func TestLimiter_RequestsPerSecond(t *testing.T) {
+	l := client.NewLimiter("test-rate", 50, 0)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		l.Release()
+	}
+
+	// the first request starts immediately, the next ones every 20ms
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("6 requests at 50 rps took %s, expected at least 100ms", elapsed)
+	}
+}
+
+func TestLimiter_MaxInFlight(t *testing.T) {
+	l := client.NewLimiter("test-in-flight", 0, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the third request waits for a free slot
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got: %v", err)
+	}
+
+	l.Release()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestLimiter_ThrottleAndRecover(t *testing.T) {
+	l := client.NewLimiter("test-throttle", 8, 0)
+
+	l.Throttle()
+	l.Throttle()
+	if rps := l.RequestsPerSecond(); rps != 2 {
+		t.Fatalf("expected 2 rps after two 429s, got %v", rps)
+	}
+
+	for i := 0; i < 20; i++ {
+		l.Recover()
+	}
+	if rps := l.RequestsPerSecond(); rps != 8 {
+		t.Fatalf("expected configured 8 rps after recovery, got %v", rps)
+	}
+
+	// unlimited limiter slows down as well and becomes unlimited again
+	unlimited := client.NewLimiter("test-throttle-unlimited", 0, 0)
+	unlimited.Throttle()
+	if rps := unlimited.RequestsPerSecond(); rps == 0 {
+		t.Fatal("expected unlimited limiter to be limited after 429")
+	}
+
+	for i := 0; i < 20; i++ {
+		unlimited.Recover()
+	}
+	if rps := unlimited.RequestsPerSecond(); rps != 0 {
+		t.Fatalf("expected unlimited limiter after recovery, got %v", rps)
+	}
+}
+
+func TestClient_SharedLimiter(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	var inFlight, maxInFlight, requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		// the first request is rejected, the client slows down and retries
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	limiter := client.NewLimiter("test-shared", 0, 1)
+
+	var clients []*client.HTTPClient
+	for i := 0; i < 2; i++ {
+		cl := client.NewHTTPClient(ts.URL, logger, new(tokenSvcClientMock))
+		cl.RetryWaitMin = 10 * time.Millisecond
+		cl.RetryWaitMax = 50 * time.Millisecond
+		cl.Limiter = limiter
+		clients = append(clients, cl)
+	}
+
+	var wg sync.WaitGroup
+	for _, cl := range clients {
+		wg.Add(1)
+		go func(cl *client.HTTPClient) {
+			defer wg.Done()
+			resp, err := cl.Get(context.Background(), "")
+			if err != nil {
+				t.Errorf("err: %v", err)
+				return
+			}
+			_ = resp.Body.Close()
+		}(cl)
+	}
+	wg.Wait()
+
+	if maxInFlight != 1 {
+		t.Fatalf("expected at most 1 in-flight request for both clients, got %d", maxInFlight)
+	}
+	if limiter.RequestsPerSecond() == 0 {
+		t.Fatal("expected the limiter to slow down after 429")
+	}
+}
+
+func TestClient_LimiterReleasedWithResponseBody(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer ts.Close()
+
+	cl := client.NewHTTPClient(ts.URL, logger, new(tokenSvcClientMock))
+	cl.Limiter = client.NewLimiter("test-release", 0, 1)
+
+	resp, err := cl.Get(context.Background(), "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the request is in flight until its body is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cl.Limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded while the body is open, got: %v", err)
+	}
+
+	_ = resp.Body.Close()
+	_ = resp.Body.Close() // closing again does not release other request's slot
+
+	if err := cl.Limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	cl.Limiter.Release()
+}
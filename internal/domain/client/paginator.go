@@ -0,0 +1,101 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+)
+
+// DefaultMaxPages is the safety cap of the number of pages of one query
+const DefaultMaxPages = 10000
+
+// PageConfig configures queries of the bookmark paginated endpoints
+type PageConfig struct {
+	// PageSize is the number of records requested per page (limit), 0 = page size of the external service
+	PageSize int
+	// MaxPages fails the query when the endpoint returns more pages, 0 = DefaultMaxPages
+	MaxPages int
+}
+
+// PageHandler is called with the records of each page, the query stops if it returns error
+type PageHandler func(records []json.RawMessage) error
+
+// Paginator queries all pages of the bookmark paginated endpoint
+type Paginator interface {
+	// Query sends the query (JSON object with selector, fields...) page by page, each page continues where
+	// the bookmark of the previous page ended. The records are passed to the handler page by page as they arrive.
+	// The query ends with an empty page or an empty or repeated bookmark.
+	Query(ctx context.Context, channelID string, query map[string]interface{}, handler PageHandler) error
+}
+
+// NewPaginator returns paginator querying the endpoint of the client
+func NewPaginator(client Client, cfg PageConfig) Paginator {
+	if cfg.MaxPages <= 0 {
+		cfg.MaxPages = DefaultMaxPages
+	}
+
+	return &paginator{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+type paginator struct {
+	client Client
+	cfg    PageConfig
+}
+
+func (p paginator) Query(ctx context.Context, channelID string, query map[string]interface{}, handler PageHandler) error {
+	payload := make(map[string]interface{}, len(query)+2)
+	for k, v := range query {
+		payload[k] = v
+	}
+
+	if p.cfg.PageSize > 0 {
+		payload["limit"] = p.cfg.PageSize
+	}
+
+	var bookmark string
+	for pages := 0; ; pages++ {
+		if pages >= p.cfg.MaxPages {
+			return domain.NewErrorf(domain.ErrorCodeUnknown,
+				"query of '%s' in channel '%s' stopped after %d pages, the endpoint keeps returning more pages",
+				p.client.URL(), channelID, pages)
+		}
+
+		payload["bookmark"] = bookmark
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not prepare query")
+		}
+
+		resp, err := p.client.Query(ctx, channelID, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			Bookmark string            `json:"bookmark"`
+			Result   []json.RawMessage `json:"result"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode page %d of the query result", pages+1)
+		}
+
+		if len(page.Result) > 0 {
+			if err := handler(page.Result); err != nil {
+				return err
+			}
+		}
+
+		if len(page.Result) == 0 || page.Bookmark == "" || page.Bookmark == bookmark {
+			return nil
+		}
+
+		bookmark = page.Bookmark
+	}
+}
@@ -0,0 +1,141 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+)
+
+// pagesClientMock returns the pages of the query result by the bookmark in the query
+type pagesClientMock struct {
+	pages   map[string]string
+	queries []map[string]interface{}
+}
+
+func (m *pagesClientMock) Get(_ context.Context, _ string) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *pagesClientMock) Query(_ context.Context, _ string, body io.ReadSeeker) (*http.Response, error) {
+	var query map[string]interface{}
+	if err := json.NewDecoder(body).Decode(&query); err != nil {
+		return nil, err
+	}
+	m.queries = append(m.queries, query)
+
+	bookmark, _ := query["bookmark"].(string)
+	page, ok := m.pages[bookmark]
+	if !ok {
+		return nil, errors.New("unexpected bookmark " + bookmark)
+	}
+
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(page))}, nil
+}
+
+func (m *pagesClientMock) URL() string { return "http://itsm.test/incident" }
+
+func (m *pagesClientMock) Close() error { return nil }
+
+func TestPaginator_Query(t *testing.T) {
+	cases := map[string]struct {
+		pages    map[string]string
+		expected []string
+	}{
+		"ends with empty bookmark": {
+			pages: map[string]string{
+				"":   `{"bookmark":"b1","result":["a","b"]}`,
+				"b1": `{"bookmark":"","result":["c"]}`,
+			},
+			expected: []string{`"a"`, `"b"`, `"c"`},
+		},
+		"ends with repeated bookmark": {
+			pages: map[string]string{
+				"":   `{"bookmark":"b1","result":["a","b"]}`,
+				"b1": `{"bookmark":"b1","result":["c","d"]}`,
+			},
+			expected: []string{`"a"`, `"b"`, `"c"`, `"d"`},
+		},
+		"ends with empty page": {
+			pages: map[string]string{
+				"":   `{"bookmark":"b1","result":["a","b"]}`,
+				"b1": `{"bookmark":"b2","result":[]}`,
+			},
+			expected: []string{`"a"`, `"b"`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &pagesClientMock{pages: tc.pages}
+			p := client.NewPaginator(m, client.PageConfig{PageSize: 2})
+
+			var records []string
+			err := p.Query(context.Background(), "channel", map[string]interface{}{"fields": []string{"uuid"}},
+				func(page []json.RawMessage) error {
+					for _, r := range page {
+						records = append(records, string(r))
+					}
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			if !reflect.DeepEqual(records, tc.expected) {
+				t.Fatalf("expected records %v, got %v", tc.expected, records)
+			}
+
+			for _, q := range m.queries {
+				if q["limit"] != float64(2) {
+					t.Fatalf("expected limit 2 in the query, got %v", q["limit"])
+				}
+				if !reflect.DeepEqual(q["fields"], []interface{}{"uuid"}) {
+					t.Fatalf("expected fields of the query, got %v", q["fields"])
+				}
+			}
+		})
+	}
+}
+
+func TestPaginator_QueryLimits(t *testing.T) {
+	// endless pages
+	m := &pagesClientMock{pages: map[string]string{
+		"":   `{"bookmark":"b1","result":["a"]}`,
+		"b1": `{"bookmark":"b2","result":["b"]}`,
+		"b2": `{"bookmark":"b1","result":["c"]}`,
+	}}
+
+	p := client.NewPaginator(m, client.PageConfig{MaxPages: 5})
+	err := p.Query(context.Background(), "channel", nil, func([]json.RawMessage) error { return nil })
+	if err == nil {
+		t.Fatal("expected error after max pages")
+	}
+	if !strings.Contains(err.Error(), "http://itsm.test/incident") || !strings.Contains(err.Error(), "'channel'") {
+		t.Fatalf("expected error naming the endpoint and the channel, got %v", err)
+	}
+	if len(m.queries) != 5 {
+		t.Fatalf("expected 5 queries, got %d", len(m.queries))
+	}
+	if _, ok := m.queries[0]["limit"]; ok {
+		t.Fatal("expected no limit in the query without page size")
+	}
+
+	// handler error stops the query
+	m.queries = nil
+	handlerErr := errors.New("handler failed")
+	err = p.Query(context.Background(), "channel", nil, func([]json.RawMessage) error { return handlerErr })
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+	if len(m.queries) != 1 {
+		t.Fatalf("expected 1 query, got %d", len(m.queries))
+	}
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+)
+
+// Mode is the mode of the ITSM clients
+type Mode string
+
+// Mode values
+const (
+	// ModeLive calls the external service
+	ModeLive Mode = "live"
+	// ModeRecord calls the external service and records the exchanges to fixture files
+	ModeRecord Mode = "record"
+	// ModeReplay replays the recorded exchanges from fixture files without calling the external service
+	ModeReplay Mode = "replay"
+)
+
+// Exchange is the request and response recorded in the fixture file
+type Exchange struct {
+	Method    string `json:"method"`
+	URL       string `json:"url"`
+	ChannelID string `json:"channel_id"`
+	// RequestBody is the body of the query, empty for GET requests
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+
+	StatusCode int `json:"status_code"`
+	// ResponseBody is the JSON body of the response, ResponseText is used for the body that is not valid JSON
+	ResponseBody json.RawMessage `json:"response_body,omitempty"`
+	ResponseText string          `json:"response_text,omitempty"`
+}
+
+// fixtureName returns the name of the fixture file of the request. The name is derived from the method, URL,
+// channel and the body of the request, so the same request always replays the same fixture.
+func fixtureName(method, url, channelID string, body []byte) string {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(method), []byte(url), []byte(channelID), body} {
+		_, _ = h.Write(part)
+		_, _ = h.Write([]byte{0})
+	}
+
+	return strings.ToLower(method) + "-" + hex.EncodeToString(h.Sum(nil))[:16] + ".json"
+}
+
+// compactJSON returns the JSON without the indentation added to the fixture file
+func compactJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+
+	return buf.Bytes()
+}
+
+// NewRecordingClient returns client calling the external service with the wrapped client and writing each
+// successful exchange to a fixture file in the dir. The url must be the url of the wrapped client, query bodies
+// must be JSON.
+func NewRecordingClient(client Client, url, dir string) Client {
+	return &recordingClient{
+		client: client,
+		url:    url,
+		dir:    dir,
+	}
+}
+
+type recordingClient struct {
+	client Client
+	url    string
+	dir    string
+}
+
+func (c recordingClient) Get(ctx context.Context, channelID string) (*http.Response, error) {
+	resp, err := c.client.Get(ctx, channelID)
+	if err != nil {
+		return resp, err
+	}
+
+	return c.record(http.MethodGet, channelID, nil, resp)
+}
+
+func (c recordingClient) Query(ctx context.Context, channelID string, body io.ReadSeeker) (*http.Response, error) {
+	reqBody, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read the request body")
+	}
+
+	resp, err := c.client.Query(ctx, channelID, bytes.NewReader(reqBody))
+	if err != nil {
+		return resp, err
+	}
+
+	return c.record(http.MethodOptions, channelID, reqBody, resp)
+}
+
+func (c recordingClient) URL() string {
+	return c.url
+}
+
+func (c recordingClient) Close() error {
+	return c.client.Close()
+}
+
+// record writes the exchange to the fixture file and returns the response with the body that can be read again
+func (c recordingClient) record(method, channelID string, reqBody []byte, resp *http.Response) (*http.Response, error) {
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read the response body")
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	e := Exchange{
+		Method:     method,
+		URL:        c.url,
+		ChannelID:  channelID,
+		StatusCode: resp.StatusCode,
+	}
+
+	if len(reqBody) > 0 {
+		if !json.Valid(reqBody) {
+			return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "could not record the query, the body is not JSON")
+		}
+		e.RequestBody = reqBody
+	}
+
+	if json.Valid(respBody) {
+		e.ResponseBody = respBody
+	} else {
+		e.ResponseText = string(respBody)
+	}
+
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not encode the exchange")
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not create the fixture dir %s", c.dir)
+	}
+
+	filename := filepath.Join(c.dir, fixtureName(method, c.url, channelID, reqBody))
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write the fixture file %s", filename)
+	}
+
+	return resp, nil
+}
+
+// NewReplayClient returns client replaying the exchanges recorded by the recording client of the url to the dir.
+// Requests are matched by the URL, channel and the body, the request without recorded exchange fails.
+func NewReplayClient(url, dir string) Client {
+	return &replayClient{
+		url: url,
+		dir: dir,
+	}
+}
+
+type replayClient struct {
+	url string
+	dir string
+}
+
+func (c replayClient) Get(_ context.Context, channelID string) (*http.Response, error) {
+	return c.replay(http.MethodGet, channelID, nil)
+}
+
+func (c replayClient) Query(_ context.Context, channelID string, body io.ReadSeeker) (*http.Response, error) {
+	reqBody, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read the request body")
+	}
+
+	return c.replay(http.MethodOptions, channelID, reqBody)
+}
+
+func (c replayClient) URL() string {
+	return c.url
+}
+
+func (c replayClient) Close() error {
+	return nil
+}
+
+func (c replayClient) replay(method, channelID string, reqBody []byte) (*http.Response, error) {
+	filename := filepath.Join(c.dir, fixtureName(method, c.url, channelID, reqBody))
+
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, domain.NewErrorf(domain.ErrorCodeNotFound,
+			"no recorded exchange of %s %s in channel %q with body %s", method, c.url, channelID, reqBody)
+	}
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read the fixture file %s", filename)
+	}
+
+	var e Exchange
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode the fixture file %s", filename)
+	}
+
+	// the fixture name is a hash, make sure it is really the recorded request
+	if e.Method != method || e.URL != c.url || e.ChannelID != channelID || !bytes.Equal(compactJSON(e.RequestBody), reqBody) {
+		return nil, domain.NewErrorf(domain.ErrorCodeUnknown, "fixture file %s does not match the request", filename)
+	}
+
+	respBody := []byte(e.ResponseBody)
+	if len(respBody) == 0 {
+		respBody = []byte(e.ResponseText)
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+	}, nil
+}
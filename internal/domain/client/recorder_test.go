@@ -0,0 +1,117 @@
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+)
+
+// getClientMock returns the body for GET requests and the bookmark pages for queries
+type getClientMock struct {
+	pagesClientMock
+	body string
+	gets int
+}
+
+func (m *getClientMock) Get(_ context.Context, _ string) (*http.Response, error) {
+	m.gets++
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(m.body))}, nil
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	return string(body)
+}
+
+func TestRecordingClient_Replay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "itsm-fixtures")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	const url = "http://itsm/api/v1/users"
+	const query = `{"bookmark":"","selector":{"email":"a@b.c"}}`
+
+	m := &getClientMock{
+		pagesClientMock: pagesClientMock{pages: map[string]string{"": `{"bookmark":"","result":["a"]}`}},
+		body:            "plain text",
+	}
+
+	// record the exchanges, the caller still gets the responses
+	rec := client.NewRecordingClient(m, url, dir)
+
+	resp, err := rec.Get(context.Background(), "channel")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if body := readBody(t, resp); body != "plain text" {
+		t.Fatalf("expected recorded response body, got %q", body)
+	}
+
+	resp, err = rec.Query(context.Background(), "channel", strings.NewReader(query))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if body := readBody(t, resp); body != `{"bookmark":"","result":["a"]}` {
+		t.Fatalf("expected recorded response body, got %q", body)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 fixture files, got %d", len(files))
+	}
+
+	// replay the exchanges without the external service
+	rep := client.NewReplayClient(url, dir)
+
+	resp, err = rep.Get(context.Background(), "channel")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if body := readBody(t, resp); body != "plain text" {
+		t.Fatalf("expected replayed response body, got %q", body)
+	}
+
+	resp, err = rep.Query(context.Background(), "channel", strings.NewReader(query))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if body := readBody(t, resp); !strings.Contains(body, `"bookmark"`) || !strings.Contains(body, `"a"`) {
+		t.Fatalf("expected replayed response body, got %q", body)
+	}
+
+	if m.gets != 1 || len(m.queries) != 1 {
+		t.Fatalf("expected replay without calls of the wrapped client, got %d gets and %d queries", m.gets, len(m.queries))
+	}
+
+	// requests differing by the channel, body or URL are not recorded
+	if _, err := rep.Get(context.Background(), "other channel"); err == nil {
+		t.Fatal("expected error for other channel")
+	}
+	if _, err := rep.Query(context.Background(), "channel", strings.NewReader(`{"bookmark":"b1"}`)); err == nil {
+		t.Fatal("expected error for other body")
+	}
+	if _, err := client.NewReplayClient(url+"/other", dir).Get(context.Background(), "channel"); err == nil {
+		t.Fatal("expected error for other URL")
+	}
+}
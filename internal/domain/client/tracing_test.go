@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClient_TracesAttempts(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	var requests int32
+	var traceparent atomic.Value
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent.Store(r.Header.Get("traceparent"))
+
+		// the first attempt fails, the second succeeds
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	cl := client.NewHTTPClient(ts.URL, logger, new(tokenSvcClientMock))
+	cl.RetryWaitMin = 10 * time.Millisecond
+	cl.RetryWaitMax = 10 * time.Millisecond
+
+	resp, err := cl.Get(context.Background(), "channel-1")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	var attempts []map[attribute.Key]attribute.Value
+	for _, span := range recorder.Ended() {
+		if span.Name() != "HTTP GET" {
+			continue
+		}
+
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		attempts = append(attempts, attrs)
+	}
+
+	if len(attempts) != 2 {
+		t.Fatalf("expected span of each of 2 attempts, got %d", len(attempts))
+	}
+
+	for i, attrs := range attempts {
+		if attrs["http.attempt"].AsInt64() != int64(i+1) {
+			t.Fatalf("expected attempt %d, got %v", i+1, attrs["http.attempt"].AsInt64())
+		}
+		if attrs["channel.id"].AsString() != "channel-1" {
+			t.Fatalf("expected channel ID attribute, got %q", attrs["channel.id"].AsString())
+		}
+	}
+
+	if attempts[0]["http.status_code"].AsInt64() != http.StatusServiceUnavailable ||
+		attempts[1]["http.status_code"].AsInt64() != http.StatusOK {
+		t.Fatalf("expected status codes of the attempts, got %v and %v",
+			attempts[0]["http.status_code"].AsInt64(), attempts[1]["http.status_code"].AsInt64())
+	}
+
+	if v, _ := traceparent.Load().(string); v == "" {
+		t.Fatal("expected trace context propagated to the external service")
+	}
+}
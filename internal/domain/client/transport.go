@@ -0,0 +1,127 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+)
+
+// TransportConfig configures the connections of the outbound clients (ITSM, token service, Postmark)
+type TransportConfig struct {
+	// CAFile is PEM file with CA certificates trusted in addition to the system ones (empty = system CAs only)
+	CAFile string
+	// ClientCertFile and ClientKeyFile are PEM files with the client certificate for mTLS (empty = no client certificate)
+	ClientCertFile string
+	ClientKeyFile  string
+	// ProxyURL is the HTTP proxy of all requests (empty = proxy from HTTP_PROXY, HTTPS_PROXY and NO_PROXY env vars)
+	ProxyURL string
+
+	ConnectTimeout      time.Duration
+	TLSHandshakeTimeout time.Duration
+	// MaxIdleConns is the maximum number of idle (keep-alive) connections in total and per host; 0 = no limit
+	// in total and the Go default of 2 per host
+	MaxIdleConns int
+}
+
+// NewOutboundHTTPClient returns HTTP client with the transport configured by cfg. It fails if the configuration
+// is invalid, e.g. the certificate files cannot be loaded.
+func NewOutboundHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// NewTransport returns HTTP transport configured by cfg
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	if cfg.ConnectTimeout < 0 || cfg.TLSHandshakeTimeout < 0 || cfg.MaxIdleConns < 0 {
+		return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "timeouts and max idle connections must not be negative")
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, domain.WrapErrorf(err, domain.ErrorCodeInvalidArgument, "invalid proxy URL")
+		}
+
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument,
+				"invalid proxy URL %s, scheme must be http, https or socks5", cfg.ProxyURL)
+		}
+		if proxyURL.Host == "" {
+			return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "invalid proxy URL %s, host is missing", cfg.ProxyURL)
+		}
+
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConns, // most requests go to the ITSM server
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}, nil
+}
+
+// newTLSConfig returns TLS configuration with the CA certificates and the client certificate of cfg
+func newTLSConfig(cfg TransportConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, domain.WrapErrorf(err, domain.ErrorCodeInvalidArgument, "could not read CA file")
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "no PEM certificates in CA file %s", cfg.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if (cfg.ClientCertFile == "") != (cfg.ClientKeyFile == "") {
+		return nil, domain.NewErrorf(domain.ErrorCodeInvalidArgument, "both client certificate and key files must be set")
+	}
+
+	if cfg.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, domain.WrapErrorf(err, domain.ErrorCodeInvalidArgument, "could not load client certificate")
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
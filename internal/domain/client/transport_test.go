@@ -0,0 +1,182 @@
+package client_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+)
+
+func TestNewOutboundHTTPClient_CAFile(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "transport")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the server certificate is not trusted without the CA file
+	c, err := client.NewOutboundHTTPClient(client.TransportConfig{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := c.Get(ts.URL); err == nil {
+		t.Fatal("expected unknown authority error")
+	}
+
+	c, err = client.NewOutboundHTTPClient(client.TransportConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	resp, err := c.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestNewOutboundHTTPClient_ClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transport")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// self-signed client certificate, trusted by the server as its own CA
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "reporting-service"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	clientCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the server requires the client certificate
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "reporting-service" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the handshake fails without the client certificate
+	c, err := client.NewOutboundHTTPClient(client.TransportConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp, err := c.Get(ts.URL); err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected handshake error without client certificate")
+	}
+
+	c, err = client.NewOutboundHTTPClient(client.TransportConfig{CAFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	resp, err := c.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewOutboundHTTPClient_InvalidConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transport")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	cases := map[string]client.TransportConfig{
+		"missing CA file":      {CAFile: filepath.Join(dir, "missing.pem")},
+		"CA file without PEM":  {CAFile: notPEM},
+		"cert without key":     {ClientCertFile: notPEM},
+		"invalid client cert":  {ClientCertFile: notPEM, ClientKeyFile: notPEM},
+		"proxy without scheme": {ProxyURL: "proxy.example.com:3128"},
+		"proxy without host":   {ProxyURL: "http://"},
+		"negative timeout":     {ConnectTimeout: -1},
+	}
+
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.NewOutboundHTTPClient(cfg); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	if _, err := client.NewOutboundHTTPClient(client.TransportConfig{ProxyURL: "http://proxy.example.com:3128"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
@@ -16,6 +16,8 @@ type Email struct {
 	TextBody string `json:",omitempty"`
 	// MessageStream is the way Postmark separates emails
 	MessageStream string `json:",omitempty"`
+	// Headers: List of custom headers to include
+	Headers []Header `json:",omitempty"`
 	// Attachments: List of attachments
 	Attachments []Attachment `json:",omitempty"`
 }
@@ -12,9 +12,17 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/excel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/locale"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/recipient"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
 	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 )
@@ -25,42 +33,128 @@ type Sender interface {
 
 	// SendEmailsForServiceDesk sends emails with tickets' info to service desk agents
 	SendEmailsForServiceDesk(ctx context.Context) error
+
+	// SendEmailsForChannelOwners sends emails with open tickets of the channel to the owners of the channel
+	SendEmailsForChannelOwners(ctx context.Context) error
 }
 
 //go:embed email_template.html
 var templateHTML string
 
-// NewEmailSender returns new service for sending emails with attached Excel files generated in precious step
+// NewEmailSender returns new service for sending emails with attached Excel files generated in precious step.
+// Dates are rendered in the timezone and the date format of the recipient.
+// Field engineers receive the emails with the files the Excel generator has generated for them according to their
+// preferences, in their language and with the links to manage the preferences. Field engineers excluded
+// by recipientFilter get no emails.
+// Channel owners receive the emails with the dates in the timezone of the channel, if it is configured.
+// The emails show the tickets, numbers, states, titles (and creation dates of all tickets) and the extra ticket
+// fields from the Excel files generated by the layouts. The emails are sent to Postmark by httpClient
+// (nil = http.DefaultClient).
 func NewEmailSender(
-	logger *zap.SugaredLogger,
-	postmarkServerURL, postmarkServerToken, messageStream, fromEmailAddress, feAttachmentsDirPath, sdAttachmentsDirPath string,
-	ticketRepository repository.TicketRepository, sdAgentEmails []string,
+	logger *zap.SugaredLogger, httpClient *http.Client,
+	postmarkServerURL, postmarkServerToken, messageStream, fromEmailAddress string,
+	feAttachmentsDirPath, sdAttachmentsDirPath, channelAttachmentsDirPath string,
+	channelRepository repository.ChannelRepository, ticketRepository repository.TicketRepository,
+	sdAgentEmails []string, layouts excel.Layouts, extraFields []ticket.Field, dateSettings locale.Config,
+	preferencesService prefsvc.PreferencesService, recipientFilter recipient.Filter,
 ) Sender {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
 	return &sender{
-		logger:               logger,
-		postmarkServerURL:    postmarkServerURL,
-		postmarkServerToken:  postmarkServerToken,
-		messageStream:        messageStream,
-		fromEmailAddress:     fromEmailAddress,
-		feAttachmentsDirPath: feAttachmentsDirPath,
-		sdAttachmentsDirPath: sdAttachmentsDirPath,
-		ticketRepository:     ticketRepository,
-		sdAgentEmails:        sdAgentEmails,
-		client:               http.DefaultClient,
+		logger:                    logger,
+		postmarkServerURL:         postmarkServerURL,
+		postmarkServerToken:       postmarkServerToken,
+		messageStream:             messageStream,
+		fromEmailAddress:          fromEmailAddress,
+		feAttachmentsDirPath:      feAttachmentsDirPath,
+		sdAttachmentsDirPath:      sdAttachmentsDirPath,
+		channelAttachmentsDirPath: channelAttachmentsDirPath,
+		channelRepository:         channelRepository,
+		ticketRepository:          ticketRepository,
+		sdAgentEmails:             sdAgentEmails,
+		feTable:                   newEmailTable(layouts.FieldEngineer, emailFields(tableFields, extraFields)),
+		sdTable:                   newEmailTable(layouts.AllTickets, emailFields(tableFields, extraFields)),
+		dateSettings:              dateSettings,
+		preferencesService:        preferencesService,
+		recipientFilter:           recipientFilter,
+		client:                    httpClient,
 	}
 }
 
 type sender struct {
-	logger               *zap.SugaredLogger
-	postmarkServerURL    string
-	postmarkServerToken  string
-	messageStream        string
-	fromEmailAddress     string
-	feAttachmentsDirPath string // directory with Excel files for field engineers
-	sdAttachmentsDirPath string // directory with Excel files for field engineers
-	ticketRepository     repository.TicketRepository
-	sdAgentEmails        []string
-	client               *http.Client
+	logger                    *zap.SugaredLogger
+	postmarkServerURL         string
+	postmarkServerToken       string
+	messageStream             string
+	fromEmailAddress          string
+	feAttachmentsDirPath      string // directory with Excel files for field engineers
+	sdAttachmentsDirPath      string // directory with Excel files for field engineers
+	channelAttachmentsDirPath string // directory with Excel files for channel owners, in subdirectories by channel ID
+	channelRepository         repository.ChannelRepository
+	ticketRepository          repository.TicketRepository
+	sdAgentEmails             []string
+	feTable                   emailTable    // columns of the Excel files for field engineers shown in the emails
+	sdTable                   emailTable    // columns of the Excel files with all tickets shown in the emails
+	dateSettings              locale.Config // timezones and date formats of the recipients
+	preferencesService        prefsvc.PreferencesService
+	recipientFilter           recipient.Filter // decides which field engineers get the emails
+	client                    *http.Client
+}
+
+// tableFields are the fields of the Excel files shown in the emails
+var tableFields = []string{excel.FieldTicketType, excel.FieldNumber, excel.FieldState, excel.FieldTitle}
+
+// emailFields returns the fields shown in the email followed by the extra ticket fields
+func emailFields(fields []string, extraFields []ticket.Field) []string {
+	all := append([]string{}, fields...)
+	for _, f := range extraFields {
+		all = append(all, f.Key)
+	}
+
+	return all
+}
+
+// emailTable selects the columns of the Excel file shown in the email, the indexes are zero-based
+type emailTable struct {
+	columns   []int
+	numberCol int // ticket numbers are linked to the tickets in the ITSM UI, -1 = no such column
+	dateCol   int // dates are rendered in the recipient's timezone and date format, -1 = no such column
+}
+
+// newEmailTable returns the table with the columns of the fields in the layout. If the layout contains none
+// of the fields, all its columns are shown.
+func newEmailTable(layout excel.Layout, fields []string) emailTable {
+	t := emailTable{
+		numberCol: layout.ColumnIndex(excel.FieldNumber),
+		dateCol:   layout.ColumnIndex(excel.FieldCreatedAt),
+	}
+
+	for _, field := range fields {
+		if i := layout.ColumnIndex(field); i >= 0 {
+			t.columns = append(t.columns, i)
+		}
+	}
+
+	if len(t.columns) == 0 {
+		for i := range layout.Columns {
+			t.columns = append(t.columns, i)
+		}
+	}
+
+	return t
+}
+
+// emailRecipient is the recipient of the email
+type emailRecipient struct {
+	address        string
+	subject        string
+	texts          Texts
+	manageURL      string // link to the preferences page, empty if not available
+	unsubscribeURL string // unsubscribe link, empty if not available
+	subDir         string // subdirectory of the attachments directory with the recipient's file, e.g. the channel ID
+	dates          locale.Settings
 }
 
 func (s sender) SendEmailsForFieldEngineers(ctx context.Context) error {
@@ -71,20 +165,108 @@ func (s sender) SendEmailsForFieldEngineers(ctx context.Context) error {
 
 	s.logger.Info("Sending emails for Field Engineers")
 
-	caption := "<b>Hi, below are open tickets currently assigned to you.</b>"
-	return s.sendEmails(ctx, addresses, caption, "", s.feAttachmentsDirPath)
+	var recipients []emailRecipient
+	for _, address := range addresses {
+		tickets, err := s.ticketRepository.GetTicketsByEmailAddress(ctx, address)
+		if err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get tickets for email '%s' from repository", address)
+		}
+
+		if ok, _ := s.recipientFilter.Check(address, ticket.AssigneeType(tickets)); !ok {
+			continue // already reported by the Excel generator
+		}
+
+		// the Excel generator has decided whether the report is due according to the recipient's preferences,
+		// the email is sent with the file it has generated
+		generated, err := fileExists(filepath.Join(s.feAttachmentsDirPath, address+".xlsx"))
+		if err != nil {
+			return err
+		}
+		if !generated {
+			s.logger.Infow("Email for FE skipped according to the recipient's preferences", "for", address)
+			continue
+		}
+
+		prefs, err := s.preferencesService.ForRecipient(ctx, address)
+		if err != nil {
+			return err
+		}
+
+		texts := textsFor(prefs.Language)
+		recipients = append(recipients, emailRecipient{
+			address:        address,
+			subject:        fmt.Sprintf(texts.Subject, address),
+			texts:          texts,
+			manageURL:      s.preferencesService.ManageURL(address),
+			unsubscribeURL: s.preferencesService.UnsubscribeURL(address),
+			dates:          s.dateSettings.ForRecipient(address),
+		})
+	}
+
+	return s.sendEmails(ctx, recipients, s.feAttachmentsDirPath, s.feTable)
 }
 
 func (s sender) SendEmailsForServiceDesk(ctx context.Context) error {
 	s.logger.Info("Sending emails for Service Desk agents")
 
-	caption := "<b>Hi, below are all open tickets.</b>"
-	subject := "Open tickets report"
-	return s.sendEmails(ctx, s.sdAgentEmails, caption, subject, s.sdAttachmentsDirPath)
+	texts := textsFor(preferences.DefaultLanguage)
+	texts.Caption = "Hi, below are all open tickets."
+
+	var recipients []emailRecipient
+	for _, address := range s.sdAgentEmails {
+		recipients = append(recipients, emailRecipient{
+			address: address,
+			subject: "Open tickets report",
+			texts:   texts,
+			dates:   s.dateSettings.ForRecipient(address),
+		})
+	}
+
+	return s.sendEmails(ctx, recipients, s.sdAttachmentsDirPath, s.sdTable)
+}
+
+func (s sender) SendEmailsForChannelOwners(ctx context.Context) error {
+	channelList, err := s.channelRepository.GetChannelList(ctx)
+	if err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get channels from the channel repository")
+	}
+
+	s.logger.Info("Sending emails for channel owners")
+
+	var recipients []emailRecipient
+	for _, ch := range channelList {
+		if len(ch.OwnerEmails) == 0 {
+			continue
+		}
+
+		// must match the decision of the Excel generator, there is no file for the channel without tickets
+		tickets, err := s.ticketRepository.GetTicketsByChannelID(ctx, ch.ChannelID)
+		if err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get tickets for channel '%s' from the ticket repository", ch.ChannelID)
+		}
+		if len(tickets) == 0 {
+			continue
+		}
+
+		texts := textsFor(preferences.DefaultLanguage)
+		texts.Caption = fmt.Sprintf("Hi, below are all open tickets in the channel %s.", ch.Name)
+
+		for _, address := range ch.OwnerEmails {
+			recipients = append(recipients, emailRecipient{
+				address: address,
+				subject: "Open tickets in channel " + ch.Name,
+				texts:   texts,
+				subDir:  ch.ChannelID,
+				dates:   s.dateSettings.ForRecipient(address).WithTimezone(ch.Timezone),
+			})
+		}
+	}
+
+	return s.sendEmails(ctx, recipients, s.channelAttachmentsDirPath, s.sdTable)
 }
 
-func (s sender) sendEmails(ctx context.Context, addresses []string, caption, subject, attachmentsDir string) error {
-	emails, err := s.prepareEmails(addresses, caption, subject, attachmentsDir)
+func (s sender) sendEmails(ctx context.Context, recipients []emailRecipient, attachmentsDir string, table emailTable) (err error) {
+	emails, err := s.prepareEmails(recipients, attachmentsDir, table)
 	if err != nil {
 		return err
 	}
@@ -95,6 +277,9 @@ func (s sender) sendEmails(ctx context.Context, addresses []string, caption, sub
 		return nil
 	}
 
+	ctx, span := tracing.Start(ctx, "email batch", tracing.EmailsKey.Int(len(emails)))
+	defer func() { tracing.End(span, err) }()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.postmarkServerURL, nil)
 	if err != nil {
 		return err
@@ -148,20 +333,15 @@ func (s sender) sendEmails(ctx context.Context, addresses []string, caption, sub
 	return err
 }
 
-func (s sender) prepareEmails(addresses []string, caption, emailSubject, attachmentsDir string) ([]Email, error) {
+func (s sender) prepareEmails(recipients []emailRecipient, attachmentsDir string, table emailTable) ([]Email, error) {
 	var emails []Email
-	subject := emailSubject
 
-	for _, address := range addresses {
-		fileName := address + ".xlsx"
+	for _, r := range recipients {
+		fileName := r.address + ".xlsx"
 
-		if emailSubject == "" {
-			subject = fmt.Sprintf("Open tickets assigned to %s", address)
-		}
+		filePath := filepath.Join(attachmentsDir, r.subDir, fileName)
 
-		filePath := filepath.Join(attachmentsDir, fileName)
-
-		html, err := s.renderHTML(caption, filePath)
+		html, err := s.renderHTML(r, filePath, table, r.dates)
 		if err != nil {
 			return nil, err
 		}
@@ -173,12 +353,12 @@ func (s sender) prepareEmails(addresses []string, caption, emailSubject, attachm
 
 		fileDataEnc := base64.StdEncoding.EncodeToString(fileData)
 
-		text := subject
+		text := r.subject
 
 		e := Email{
 			From:     s.fromEmailAddress,
-			To:       address,
-			Subject:  subject,
+			To:       r.address,
+			Subject:  r.subject,
 			HtmlBody: html,
 			TextBody: text,
 			Attachments: []Attachment{{
@@ -189,16 +369,26 @@ func (s sender) prepareEmails(addresses []string, caption, emailSubject, attachm
 			MessageStream: s.messageStream,
 		}
 
+		if r.unsubscribeURL != "" {
+			e.Headers = []Header{
+				{Name: "List-Unsubscribe", Value: "<" + r.unsubscribeURL + ">"},
+				{Name: "List-Unsubscribe-Post", Value: "List-Unsubscribe=One-Click"},
+			}
+		}
+
 		emails = append(emails, e)
 	}
 
 	return emails, nil
 }
 
-func (s sender) renderHTML(caption, excelFile string) (string, error) {
+func (s sender) renderHTML(r emailRecipient, excelFile string, table emailTable, dates locale.Settings) (string, error) {
 	type HTMLData struct {
-		Caption template.HTML
-		Table   template.HTML
+		Texts          Texts
+		Changes        template.HTML
+		Table          template.HTML
+		ManageURL      string
+		UnsubscribeURL string
 	}
 
 	tmpl, err := template.New("htmlContent").Parse(templateHTML)
@@ -213,47 +403,197 @@ func (s sender) renderHTML(caption, excelFile string) (string, error) {
 
 	defer func() { _ = f.Close() }()
 
-	rows, err := f.GetRows("Sheet1")
+	var html string
+
+	for k, sheet := range excel.TicketSheets(f) {
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return "", err
+		}
+
+		for i, row := range rows {
+			if i == 0 {
+				continue // skip the first row, similar info was already added to the email
+			}
+			if k > 0 && i < excel.HeaderRow {
+				continue // the header is shown only once, from the first sheet
+			}
+
+			var htmlRow string
+			for _, j := range table.columns {
+				if j >= len(row) {
+					htmlRow += "<td>&nbsp;</td>"
+					continue
+				}
+
+				colCell := template.HTMLEscapeString(row[j])
+				switch j {
+				case table.numberCol:
+					if colCell, err = linkedCell(f, sheet, j, i, colCell); err != nil {
+						return "", err
+					}
+				case table.dateCol:
+					if colCell, err = dateCell(f, sheet, j, i, colCell, dates); err != nil {
+						return "", err
+					}
+				}
+
+				htmlRow += "<td>" + colCell + "</td>"
+			}
+
+			html += "<tr>" + htmlRow + "</tr>"
+		}
+	}
+
+	changesHTML, since, err := s.renderChanges(f, dates)
 	if err != nil {
 		return "", err
 	}
 
-	var html string
-	totalColsNum := 4
+	texts := r.texts
+	if since != "" {
+		texts.ChangesHeading += " (" + since + ")"
+	}
+
+	var processedHTML bytes.Buffer
+	err = tmpl.Execute(&processedHTML, HTMLData{
+		Texts:          texts,
+		Changes:        template.HTML(changesHTML),
+		Table:          template.HTML(html),
+		ManageURL:      r.manageURL,
+		UnsubscribeURL: r.unsubscribeURL,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return processedHTML.String(), nil
+}
+
+// renderChanges returns HTML table rows with changes since the previous report, if the Excel file contains them,
+// and the date of the previous report formatted by the recipient's date format (empty if not known)
+func (s sender) renderChanges(f *excelize.File, dates locale.Settings) (html, since string, err error) {
+	hasChanges := false
+	for _, sheet := range f.GetSheetList() {
+		if sheet == excel.ChangesSheet {
+			hasChanges = true
+			break
+		}
+	}
+
+	if !hasChanges {
+		return "", "", nil
+	}
+
+	if since, err = changesSince(f, dates); err != nil {
+		return "", "", err
+	}
 
+	rows, err := f.GetRows(excel.ChangesSheet)
+	if err != nil {
+		return "", "", err
+	}
+
+	changesNumberCol := 2 // ticket numbers are linked to the tickets in the ITSM UI
 	for i, row := range rows {
-		if i == 0 {
-			continue // skip the first row, similar info was already added to the email
+		if i < 2 {
+			continue // skip the title and the date, the section has its own heading
 		}
 
 		var htmlRow string
-		emptyColsNum := 0
-		if len(row) <= totalColsNum {
-			emptyColsNum = totalColsNum - len(row)
-		}
+		for j, colCell := range row {
+			if i == 2 {
+				htmlRow += "<th align=\"left\">" + template.HTMLEscapeString(colCell) + "</th>"
+				continue
+			}
 
-		for i, colCell := range row {
-			if i == totalColsNum {
-				break
+			cellHTML := template.HTMLEscapeString(colCell)
+			if j == changesNumberCol {
+				if cellHTML, err = linkedCell(f, excel.ChangesSheet, j, i, cellHTML); err != nil {
+					return "", "", err
+				}
 			}
-			htmlRow += "<td>" + colCell + "</td>"
-		}
 
-		for i := 0; i < emptyColsNum; i++ {
-			htmlRow += "<td>&nbsp;</td>"
+			htmlRow += "<td>" + cellHTML + "</td>"
 		}
 
 		html += "<tr>" + htmlRow + "</tr>"
 	}
 
-	var processedHTML bytes.Buffer
-	err = tmpl.Execute(&processedHTML, HTMLData{
-		Caption: template.HTML(caption),
-		Table:   template.HTML(html),
-	})
+	return html, since, nil
+}
+
+// changesSince returns the date of the previous report from the changes sheet formatted by the recipient's date
+// format, empty if the sheet does not contain it
+func changesSince(f *excelize.File, dates locale.Settings) (string, error) {
+	value, err := f.GetCellValue(excel.ChangesSheet, excel.ChangesSinceCell)
+	if err != nil || value == "" {
+		return "", err
+	}
+
+	col, row, err := excelize.CellNameToCoordinates(excel.ChangesSinceCell)
 	if err != nil {
 		return "", err
 	}
 
-	return processedHTML.String(), nil
+	return dateCell(f, excel.ChangesSheet, col-1, row-1, template.HTMLEscapeString(value), dates)
+}
+
+// linkedCell wraps the HTML content of the cell in the link if the cell has a hyperlink.
+// Column and row indexes are zero-based.
+func linkedCell(f *excelize.File, sheet string, col, row int, content string) (string, error) {
+	cell, err := excelize.CoordinatesToCellName(col+1, row+1)
+	if err != nil {
+		return "", err
+	}
+
+	hasLink, link, err := f.GetCellHyperLink(sheet, cell)
+	if err != nil {
+		return "", err
+	}
+
+	if !hasLink || link == "" {
+		return content, nil
+	}
+
+	return "<a href=\"" + template.HTMLEscapeString(link) + "\">" + content + "</a>", nil
+}
+
+// dateCell returns the HTML content of the cell with the date formatted by the recipient's date format.
+// If the cell does not contain Excel date, the content is returned unchanged.
+// Column and row indexes are zero-based.
+func dateCell(f *excelize.File, sheet string, col, row int, content string, dates locale.Settings) (string, error) {
+	cell, err := excelize.CoordinatesToCellName(col+1, row+1)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := f.GetCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return "", err
+	}
+
+	serial, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return content, nil // not a date, e.g. the header
+	}
+
+	date, err := excelize.ExcelDateToTime(serial, false)
+	if err != nil {
+		return content, nil
+	}
+
+	return template.HTMLEscapeString(dates.FormatExcelDate(date)), nil
+}
+
+// fileExists returns true if the file exists
+func fileExists(path string) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not check file '%s'", path)
+	}
+
+	return true, nil
 }
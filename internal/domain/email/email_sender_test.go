@@ -0,0 +1,141 @@
+package email
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/excel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/locale"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/recipient"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSender_renderHTML(t *testing.T) {
+	ctx := context.Background()
+	const feEmail = "fe@email.test"
+	const sdEmail = "sd@email.test"
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	// the files are generated to the temp dir, which is not shared with the tests of other packages
+	tmpDir, ok := os.LookupEnv("TMPDIR")
+	require.NoError(t, os.Setenv("TMPDIR", t.TempDir()))
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv("TMPDIR", tmpDir)
+		} else {
+			_ = os.Unsetenv("TMPDIR")
+		}
+	})
+
+	clock := mocks.NewFixedClock() // 2021-04-01 12:34:56 in Prague
+	jobRepository := memory.NewJobRepositoryMemory(clock)
+	snapshotRepository := memory.NewTicketSnapshotRepositoryMemory(clock, 0)
+	ticketRepository := memory.NewTicketRepositoryMemory()
+	channelRepository := memory.NewChannelRepositoryMemory()
+	require.NoError(t, channelRepository.StoreChannelList(ctx, channel.List{
+		{ChannelID: "c1", Name: "First"}, {ChannelID: "c2", Name: "Second"},
+	}))
+
+	inc1 := ticket.Ticket{
+		UserEmail: feEmail, ChannelID: "c1", ChannelName: "First", TicketType: "Incident", RecordType: "incident",
+		TicketData: ticket.Data{
+			Number: "INC1", ShortDescription: "Printer <b>broken</b>", StateID: 1,
+			URL: "https://itsm.test/incident?number=INC1&view=full",
+		},
+	}
+	inc2 := ticket.Ticket{
+		UserEmail: feEmail, ChannelID: "c1", ChannelName: "First", TicketType: "Incident", RecordType: "incident",
+		TicketData: ticket.Data{Number: "INC2", ShortDescription: "No network", StateID: 1},
+	}
+	inc3 := ticket.Ticket{
+		UserEmail: "other@email.test", ChannelID: "c2", ChannelName: "Second", TicketType: "Incident",
+		RecordType: "incident", TicketData: ticket.Data{Number: "INC3", ShortDescription: "No power", StateID: 1},
+	}
+
+	// the previous report was generated yesterday, INC2 is new since then
+	now := clock.Now()
+	clock.SetTime(now.AddDate(0, 0, -1))
+	jobID, err := jobRepository.AddJob(ctx, job.Job{Type: job.TypeAll})
+	require.NoError(t, err)
+	j, err := jobRepository.GetJob(ctx, jobID)
+	require.NoError(t, err)
+	j.FinalStatus = job.StatusSuccess
+	_, err = jobRepository.UpdateJob(ctx, j)
+	require.NoError(t, err)
+	require.NoError(t, snapshotRepository.SaveSnapshot(ctx, jobID, ticket.List{inc1, inc3}, nil))
+	clock.SetTime(now)
+
+	require.NoError(t, ticketRepository.AddTicketList(ctx, ticket.List{inc1, inc2, inc3}))
+
+	feDates, err := locale.ParseSettings("Europe/Prague", "cs-CZ", locale.DefaultSettings())
+	require.NoError(t, err)
+	dateSettings := locale.Config{Recipients: map[string]locale.Settings{feEmail: feDates}}
+
+	layouts := excel.DefaultLayouts(nil)
+	generator := excel.NewExcelGenerator(zap.NewNop().Sugar(), clock, channelRepository, ticketRepository,
+		jobRepository, snapshotRepository, []string{sdEmail}, layouts, nil, dateSettings,
+		prefsvc.NewPreferencesService(clock, memory.NewPreferencesRepositoryMemory(), channelRepository, nil, "", 0, ""),
+		recipient.Filter{},
+	)
+	require.NoError(t, generator.GenerateExcelFilesForFieldEngineers(ctx))
+	require.NoError(t, generator.GenerateExcelFilesForServiceDesk(ctx))
+
+	s := sender{}
+	texts := textsFor(preferences.DefaultLanguage)
+
+	t.Run("field engineer", func(t *testing.T) {
+		html, err := s.renderHTML(
+			emailRecipient{address: feEmail, texts: texts},
+			filepath.Join(generator.FEDirPath(), feEmail+".xlsx"),
+			newEmailTable(layouts.FieldEngineer, tableFields),
+			dateSettings.ForRecipient(feEmail),
+		)
+		require.NoError(t, err)
+
+		// the changes are rendered before the tickets
+		changesStart := strings.Index(html, texts.ChangesHeading)
+		require.True(t, changesStart >= 0, "changes section is rendered")
+		ticketsStart := strings.Index(html, "<td>Ticket type</td>")
+		require.True(t, ticketsStart > changesStart, "tickets are rendered after the changes")
+		changes, tickets := html[changesStart:ticketsStart], html[ticketsStart:]
+
+		assert.Contains(t, changes, texts.ChangesHeading+" (31.3.2021)", "date of the previous report in recipient's format")
+		assert.Contains(t, changes, "<td>New</td><td>Incident</td><td>INC2</td>")
+		assert.NotContains(t, changes, "INC1", "INC1 has not changed")
+
+		assert.Contains(t, tickets, `<td><a href="https://itsm.test/incident?number=INC1&amp;view=full">INC1</a></td>`)
+		assert.Contains(t, tickets, "<td>INC2</td>", "no link without ticket URL")
+		assert.Contains(t, tickets, "<td>Printer &lt;b&gt;broken&lt;/b&gt;</td>", "text of the cells is escaped")
+		assert.NotContains(t, tickets, "INC3", "ticket of other recipient")
+	})
+
+	t.Run("service desk", func(t *testing.T) {
+		html, err := s.renderHTML(
+			emailRecipient{address: sdEmail, texts: texts},
+			filepath.Join(generator.SDDirPath(), sdEmail+".xlsx"),
+			newEmailTable(layouts.AllTickets, tableFields),
+			dateSettings.ForRecipient(sdEmail),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, strings.Count(html, "<td>Ticket type</td>"), "the header is shown once for all channel sheets")
+		for _, number := range []string{"INC1", "INC2", "INC3"} {
+			assert.Contains(t, html, ">"+number+"<", "tickets of all channels")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package email
+
+import "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+
+// Texts of the emails for field engineers in the language of the recipient
+type Texts struct {
+	// Subject of the email, %s is replaced by the email address of the recipient
+	Subject string
+	// Caption shown above the tickets
+	Caption string
+	// ChangesHeading is the heading of the changes since the previous report, the date of the previous report
+	// is appended to it if it is known
+	ChangesHeading string
+	// ManagePreferences is the text of the link to the preferences page
+	ManagePreferences string
+	// Unsubscribe is the text of the unsubscribe link
+	Unsubscribe string
+}
+
+var texts = map[string]Texts{
+	"en": {
+		Subject:           "Open tickets assigned to %s",
+		Caption:           "Hi, below are open tickets currently assigned to you.",
+		ChangesHeading:    "Since the previous report",
+		ManagePreferences: "Manage report preferences",
+		Unsubscribe:       "Unsubscribe",
+	},
+	"cs": {
+		Subject:           "Otevřené tikety přiřazené %s",
+		Caption:           "Dobrý den, níže jsou otevřené tikety, které jsou vám aktuálně přiřazeny.",
+		ChangesHeading:    "Od předchozího reportu",
+		ManagePreferences: "Nastavení reportů",
+		Unsubscribe:       "Odhlásit odběr",
+	},
+	"de": {
+		Subject:           "Offene Tickets zugewiesen an %s",
+		Caption:           "Hallo, unten finden Sie die offenen Tickets, die Ihnen derzeit zugewiesen sind.",
+		ChangesHeading:    "Seit dem letzten Bericht",
+		ManagePreferences: "Berichtseinstellungen verwalten",
+		Unsubscribe:       "Abmelden",
+	},
+}
+
+// textsFor returns texts in the language, English texts if the language is not supported
+func textsFor(language string) Texts {
+	if t, ok := texts[language]; ok {
+		return t
+	}
+
+	return texts[preferences.DefaultLanguage]
+}
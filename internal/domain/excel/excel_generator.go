@@ -2,11 +2,20 @@ package excel
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/locale"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/recipient"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository"
 	"github.com/xuri/excelize/v2"
@@ -18,6 +27,10 @@ type Generator interface {
 	// GenerateExcelFilesForFieldEngineers creates Excel spreadsheet files with tickets' info for field engineers
 	GenerateExcelFilesForFieldEngineers(ctx context.Context) error
 
+	// ExcludedRecipients returns field engineers excluded by the recipient filter during the last generation,
+	// with the reason
+	ExcludedRecipients() []string
+
 	// GenerateExcelFilesForServiceDesk creates Excel spreadsheet files with tickets' info for service desk agents
 	GenerateExcelFilesForServiceDesk(ctx context.Context) error
 
@@ -26,27 +39,79 @@ type Generator interface {
 
 	// SDDirPath returns the absolute path to the directory where the files for service desk agents are generated to
 	SDDirPath() string
+
+	// GenerateExcelFilesForChannelOwners creates Excel spreadsheet files with open tickets of the channel for each
+	// owner of the channel
+	GenerateExcelFilesForChannelOwners(ctx context.Context) error
+
+	// ChannelDirPath returns the absolute path to the directory where the files for channel owners are generated to,
+	// the files are in the subdirectories named by channel ID
+	ChannelDirPath() string
 }
 
-// NewExcelGenerator returns new Excel files generating service
-func NewExcelGenerator(logger *zap.SugaredLogger, ticketRepository repository.TicketRepository, sdAgentEmails []string) Generator {
+// NewExcelGenerator returns new Excel files generating service.
+// Layouts define the columns of the reports, their grouping and sort order; extraFields are the ticket fields
+// available to the layouts in addition to the built-in ones.
+// Changes of the tickets since the previous report are taken from the ticket snapshot of its job: the last successful
+// job, for the weekly field engineer reports the last one before the end of the recipient's weekday a week ago.
+// The changes are left out if the snapshot has expired.
+// Dates are rendered in the timezone and the date format of the recipient.
+// Field engineers get the files according to their preferences: only when the report is due at the time of the clock
+// and only with tickets from the channels and of the types they have chosen. Field engineers excluded
+// by recipientFilter get no files.
+// Channel owners get the files with the dates in the timezone of the channel, if it is configured.
+func NewExcelGenerator(
+	logger *zap.SugaredLogger,
+	clock repository.Clock,
+	channelRepository repository.ChannelRepository,
+	ticketRepository repository.TicketRepository,
+	jobRepository repository.JobRepository,
+	snapshotRepository repository.TicketSnapshotRepository,
+	sdAgentEmails []string,
+	layouts Layouts,
+	extraFields []ticket.Field,
+	dateSettings locale.Config,
+	preferencesService prefsvc.PreferencesService,
+	recipientFilter recipient.Filter,
+) Generator {
 	return &excelGen{
-		logger:           logger,
-		ticketRepository: ticketRepository,
-		sdAgentEmails:    sdAgentEmails,
-		dirName:          filepath.Join(os.TempDir(), "reporting-xls-files"),
-		feSubDir:         "fe",
-		sdSubDir:         "sd",
+		logger:             logger,
+		clock:              clock,
+		channelRepository:  channelRepository,
+		ticketRepository:   ticketRepository,
+		jobRepository:      jobRepository,
+		snapshotRepository: snapshotRepository,
+		sdAgentEmails:      sdAgentEmails,
+		layouts:            layouts,
+		extraFields:        extraFields,
+		dateSettings:       dateSettings,
+		preferencesService: preferencesService,
+		recipientFilter:    recipientFilter,
+		dirName:            filepath.Join(os.TempDir(), "reporting-xls-files"),
+		feSubDir:           "fe",
+		sdSubDir:           "sd",
+		channelSubDir:      "channel",
 	}
 }
 
 type excelGen struct {
-	logger           *zap.SugaredLogger
-	ticketRepository repository.TicketRepository
-	sdAgentEmails    []string
-	dirName          string // directory to put generated files to
-	feSubDir         string // subdirectory with files for field engineers
-	sdSubDir         string // subdirectory with files for service desk agents
+	logger             *zap.SugaredLogger
+	clock              repository.Clock
+	channelRepository  repository.ChannelRepository
+	ticketRepository   repository.TicketRepository
+	jobRepository      repository.JobRepository
+	snapshotRepository repository.TicketSnapshotRepository
+	sdAgentEmails      []string
+	layouts            Layouts        // columns, grouping and sort order of the reports
+	extraFields        []ticket.Field // additional ticket fields available to the layouts
+	dateSettings       locale.Config  // timezones and date formats of the recipients
+	preferencesService prefsvc.PreferencesService
+	recipientFilter    recipient.Filter // decides which field engineers get the files
+	excluded           []string         // field engineers excluded during the last generation
+	dirName            string           // directory to put generated files to
+	feSubDir           string           // subdirectory with files for field engineers
+	sdSubDir           string           // subdirectory with files for service desk agents
+	channelSubDir      string           // subdirectory with files for channel owners
 }
 
 func (g excelGen) FEDirPath() string {
@@ -57,7 +122,13 @@ func (g excelGen) SDDirPath() string {
 	return filepath.Join(g.dirName, g.sdSubDir)
 }
 
-func (g excelGen) GenerateExcelFilesForFieldEngineers(ctx context.Context) error {
+func (g excelGen) ChannelDirPath() string {
+	return filepath.Join(g.dirName, g.channelSubDir)
+}
+
+func (g *excelGen) GenerateExcelFilesForFieldEngineers(ctx context.Context) error {
+	g.excluded = nil
+
 	emails, err := g.ticketRepository.GetDistinctEmailAddresses(ctx)
 	if err != nil {
 		return err
@@ -66,6 +137,10 @@ func (g excelGen) GenerateExcelFilesForFieldEngineers(ctx context.Context) error
 	return g.generateExcelFilesForFE(ctx, emails)
 }
 
+func (g excelGen) ExcludedRecipients() []string {
+	return g.excluded
+}
+
 func (g excelGen) GenerateExcelFilesForServiceDesk(ctx context.Context) error {
 	emails := g.sdAgentEmails
 	return g.generateExcelFilesForSD(ctx, emails)
@@ -95,55 +170,68 @@ func (g excelGen) prepareDir(dir string) error {
 	return nil
 }
 
-func (g excelGen) generateExcelFilesForFE(ctx context.Context, emails []string) error {
+func (g *excelGen) generateExcelFilesForFE(ctx context.Context, emails []string) error {
 	if err := g.prepareDirForFE(); err != nil {
 		return err
 	}
 
+	// snapshots of the previous reports by job, shared by the recipients of the same report
+	snapshots := make(map[ref.UUID]snapshot)
+	var currentTickets ticket.List
+	var currentLoaded bool
+
+	now := g.clock.Now()
 	for _, email := range emails {
+		prefs, err := g.preferencesService.ForRecipient(ctx, email)
+		if err != nil {
+			return err
+		}
+
 		userTickets, err := g.ticketRepository.GetTicketsByEmailAddress(ctx, email)
 		if err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get tickets for email '%s' from repository", email)
 		}
 
+		if ok, reason := g.recipientFilter.Check(email, ticket.AssigneeType(userTickets)); !ok {
+			g.logger.Infow("Excel file for FE skipped, recipient is excluded", "for", email, "reason", reason)
+			g.excluded = append(g.excluded, recipient.Exclusion(email, reason))
+			continue
+		}
+
+		recipientNow := g.dateSettings.ForRecipient(email).Date(now)
+		userTickets, ok := prefs.Report(recipientNow, userTickets)
+		if !ok {
+			g.logger.Infow("Excel file for FE skipped according to the recipient's preferences", "for", email)
+			continue
+		}
+
 		filename := email + ".xlsx"
 
 		f := excelize.NewFile()
 
-		// Set columns width
 		sheet := "Sheet1"
-		if err := f.SetColWidth(sheet, "A", "B", 13); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-		}
-		if err := f.SetColWidth(sheet, "C", "C", 10); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-		}
-		if err := f.SetColWidth(sheet, "D", "E", 45); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+		caption := "Open tickets assigned to " + email
+		if err := g.addTicketSheet(f, sheet, filename, caption, g.layouts.FieldEngineer, userTickets, g.dateSettings.ForRecipient(email)); err != nil {
+			return err
 		}
 
-		// Excel file header
-		if err := f.SetCellValue(sheet, "A1", "Open tickets assigned to "+email); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-		}
-		if err := f.SetCellValue(sheet, "A3", "Ticket type"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-		}
-		if err := f.SetCellValue(sheet, "B3", "Number"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-		}
-		if err := f.SetCellValue(sheet, "C3", "State"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-		}
-		if err := f.SetCellValue(sheet, "D3", "Title"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-		}
-		if err := f.SetCellValue(sheet, "E3", "Location"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+		previous, err := g.previousReport(ctx, prefs.PreviousReportBefore(recipientNow), snapshots)
+		if err != nil {
+			return err
 		}
 
-		if err := g.addRowsWithTicketData(f, userTickets, sheet, filename, false); err != nil {
-			return err
+		if previous.found {
+			if !currentLoaded {
+				if currentTickets, err = g.ticketRepository.GetTicketList(ctx); err != nil {
+					return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get tickets from repository")
+				}
+				currentLoaded = true
+			}
+
+			changes := ticket.CompareTickets(prefs.Filter(previous.tickets), prefs.Filter(currentTickets), email)
+			if err := g.addChangesSheet(f, changes, previous.since, g.dateSettings.ForRecipient(email), filename); err != nil {
+				return err
+			}
 		}
 
 		// Save Excel file
@@ -168,138 +256,481 @@ func (g excelGen) generateExcelFilesForSD(ctx context.Context, emails []string)
 	}
 
 	var channelTickets ticket.List
+	var channels []ticket.List
 	for _, channelID := range channelIDs {
 		tickets, err := g.ticketRepository.GetTicketsByChannelID(ctx, channelID)
 		if err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get tickets for channel '%s' from the ticket repository", channelID)
 		}
+		if len(tickets) == 0 {
+			continue
+		}
 
 		channelTickets = append(channelTickets, tickets...)
+		channels = append(channels, tickets)
 	}
 
+	// sheets of the channels are ordered by the channel name
+	sort.SliceStable(channels, func(i, j int) bool {
+		return channels[i][0].ChannelName < channels[j][0].ChannelName
+	})
+
 	if len(channelTickets) == 0 { // nothing to send
 		return nil
 	}
 
+	previousTickets, since, hasPrevious, err := g.previousTickets(ctx)
+	if err != nil {
+		return err
+	}
+
+	var changes []ticket.Change
+	if hasPrevious {
+		changes = ticket.CompareTickets(previousTickets, channelTickets, "")
+	}
+
 	for _, email := range emails {
 		filename := email + ".xlsx"
+		dates := g.dateSettings.ForRecipient(email)
 
-		f := excelize.NewFile()
-
-		// Set columns width
-		sheet := "Sheet1"
-		if err := f.SetColWidth(sheet, "A", "B", 13); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+		if err := g.writeServiceDeskFile(filename, channelTickets, channels, changes, since, hasPrevious, dates); err != nil {
+			return err
 		}
-		if err := f.SetColWidth(sheet, "C", "C", 10); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+
+		g.logger.Infow("Excel file for SD generated", "for", email, "open tickets", len(channelTickets))
+	}
+
+	return nil
+}
+
+func (g excelGen) GenerateExcelFilesForChannelOwners(ctx context.Context) error {
+	if err := g.prepareDir(g.ChannelDirPath()); err != nil {
+		return err
+	}
+
+	channelList, err := g.channelRepository.GetChannelList(ctx)
+	if err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get channels from the channel repository")
+	}
+
+	previousTickets, since, hasPrevious, err := g.previousTickets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range channelList {
+		if len(ch.OwnerEmails) == 0 {
+			continue
 		}
-		if err := f.SetColWidth(sheet, "D", "E", 45); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+
+		channelTickets, err := g.ticketRepository.GetTicketsByChannelID(ctx, ch.ChannelID)
+		if err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get tickets for channel '%s' from the ticket repository", ch.ChannelID)
 		}
-		if err := f.SetColWidth(sheet, "F", "I", 20); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+
+		if len(channelTickets) == 0 { // nothing to send
+			continue
 		}
 
-		// Excel file header
-		if err := f.SetCellValue(sheet, "A1", "All open tickets"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+		var changes []ticket.Change
+		if hasPrevious {
+			changes = ticket.CompareTickets(ticketsInChannel(previousTickets, ch.ChannelID), channelTickets, "")
 		}
-		if err := f.SetCellValue(sheet, "A3", "Ticket type"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+
+		if err := os.MkdirAll(ch.ChannelID, 0750); err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not create directory '%s'", ch.ChannelID)
 		}
-		if err := f.SetCellValue(sheet, "B3", "Number"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+
+		for _, email := range ch.OwnerEmails {
+			filename := filepath.Join(ch.ChannelID, email+".xlsx")
+			dates := g.dateSettings.ForRecipient(email).WithTimezone(ch.Timezone)
+
+			if err := g.writeAllTicketsFile(filename, channelCaption(ch.Name), channelTickets, changes, since, hasPrevious, dates); err != nil {
+				return err
+			}
+
+			g.logger.Infow("Excel file for channel owner generated", "for", email, "channel", ch.Name, "open tickets", len(channelTickets))
 		}
-		if err := f.SetCellValue(sheet, "C3", "State"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	return nil
+}
+
+// writeServiceDeskFile saves Excel file for service desk agents with the summary of all tickets followed by the sheets
+// with the tickets and their assignees of each channel
+func (g excelGen) writeServiceDeskFile(
+	filename string, tickets ticket.List, channels []ticket.List, changes []ticket.Change, since time.Time,
+	hasPrevious bool, dates locale.Settings,
+) error {
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", SummarySheet)
+
+	if err := g.addSummarySheet(f, SummarySheet, filename, tickets); err != nil {
+		return err
+	}
+
+	used := map[string]bool{strings.ToLower(SummarySheet): true, strings.ToLower(ChangesSheet): true}
+	for _, channelTickets := range channels {
+		channelName := channelTickets[0].ChannelName
+		sheet := sheetName(channelName, used)
+		f.NewSheet(sheet)
+
+		if err := g.addTicketSheet(f, sheet, filename, channelCaption(channelName), g.layouts.AllTickets, channelTickets, dates); err != nil {
+			return err
 		}
-		if err := f.SetCellValue(sheet, "D3", "Title"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	return g.saveWithChanges(f, filename, changes, since, hasPrevious, dates)
+}
+
+// writeAllTicketsFile saves Excel file with the tickets and their assignees, as sent to channel owners
+func (g excelGen) writeAllTicketsFile(
+	filename, caption string, tickets ticket.List, changes []ticket.Change, since time.Time, hasPrevious bool,
+	dates locale.Settings,
+) error {
+	f := excelize.NewFile()
+
+	if err := g.addTicketSheet(f, "Sheet1", filename, caption, g.layouts.AllTickets, tickets, dates); err != nil {
+		return err
+	}
+
+	return g.saveWithChanges(f, filename, changes, since, hasPrevious, dates)
+}
+
+// saveWithChanges adds the sheet with the changes since the previous report, if there is one, and saves Excel file
+func (g excelGen) saveWithChanges(
+	f *excelize.File, filename string, changes []ticket.Change, since time.Time, hasPrevious bool, dates locale.Settings,
+) error {
+	if hasPrevious {
+		if err := g.addChangesSheet(f, changes, since, dates, filename); err != nil {
+			return err
 		}
-		if err := f.SetCellValue(sheet, "E3", "Location"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	// Save Excel file
+	if err := f.SaveAs(filename); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not save file '%s'", filename)
+	}
+
+	return nil
+}
+
+// channelCaption returns the caption of the sheet with the tickets of the channel
+func channelCaption(channelName string) string {
+	return "Open tickets in channel " + channelName
+}
+
+// ticketsInChannel returns the tickets of the channel
+func ticketsInChannel(tickets ticket.List, channelID string) ticket.List {
+	var channelTickets ticket.List
+	for _, t := range tickets {
+		if t.ChannelID == channelID {
+			channelTickets = append(channelTickets, t)
 		}
-		if err := f.SetCellValue(sheet, "F3", "Assigned to (Name)"); err != nil {
+	}
+
+	return channelTickets
+}
+
+// Rows of the sheet with the tickets
+const (
+	captionRow = 1
+	headerRow  = 3
+)
+
+// HeaderRow is the row of the sheet with the column labels, the tickets follow it
+const HeaderRow = headerRow
+
+// addTicketSheet writes the caption, the header and the rows with the tickets to the sheet according to the layout.
+// The header is styled and frozen, the tickets are filtered unless grouped and the rows of the highlighted tickets
+// are colored by their state and age at the time of the clock.
+func (g excelGen) addTicketSheet(
+	f *excelize.File, sheet, filename, caption string, layout Layout, tickets ticket.List, dates locale.Settings,
+) error {
+	st := newStyles(f, dates.ExcelDateFormat())
+
+	captionCell := "A" + strconv.Itoa(captionRow)
+	if err := f.SetCellValue(sheet, captionCell, caption); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if err := st.set(sheet, captionCell, captionCell, styleCaption, ""); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	if len(layout.Columns) == 0 {
+		return nil
+	}
+
+	lastCol, err := excelize.ColumnNumberToName(len(layout.Columns))
+	if err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	for i, column := range layout.Columns {
+		col, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 		}
-		if err := f.SetCellValue(sheet, "G3", "Assigned to (Email)"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+
+		if column.Width > 0 {
+			if err := f.SetColWidth(sheet, col, col, column.Width); err != nil {
+				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+			}
 		}
-		if err := f.SetCellValue(sheet, "H3", "Assigned to (Org)"); err != nil {
+		if err := f.SetCellValue(sheet, col+strconv.Itoa(headerRow), column.Label); err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 		}
-		if err := f.SetCellValue(sheet, "I3", "Created at"); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if err := st.set(sheet, "A"+strconv.Itoa(headerRow), lastCol+strconv.Itoa(headerRow), styleHeader, ""); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	// the highlights by age are evaluated at the generation time, the colors are not updated when the file is opened later
+	now := g.clock.Now()
+	var group string
+	i := headerRow
+	// Excel rows with ticket data
+	for _, t := range layout.sortTickets(tickets) {
+		i++
+		if layout.GroupBy != "" {
+			if value := fieldValue(t, layout.GroupBy); value != group {
+				i++
+				row := strconv.Itoa(i)
+				if err := f.SetCellValue(sheet, "A"+row, layout.groupLabel(value, g.extraFields)); err != nil {
+					return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+				}
+				if err := st.set(sheet, "A"+row, lastCol+row, styleGroup, ""); err != nil {
+					return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+				}
+				group = value
+				i += 2
+			}
 		}
 
-		if err := g.addRowsWithTicketData(f, channelTickets, sheet, filename, true); err != nil {
-			return err
+		row := strconv.Itoa(i)
+		fill := highlightColor(layout.Highlights, t, now)
+		if fill != "" {
+			if err := st.set(sheet, "A"+row, lastCol+row, styleText, fill); err != nil {
+				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+			}
 		}
 
-		// Save Excel file
-		if err := f.SaveAs(filename); err != nil {
-			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not save file '%s'", filename)
+		for j, column := range layout.Columns {
+			col, err := excelize.ColumnNumberToName(j + 1)
+			if err != nil {
+				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+			}
+
+			if err := setTicketCell(f, st, sheet, col+row, column.Field, t, dates, fill); err != nil {
+				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+			}
 		}
+	}
 
-		g.logger.Infow("Excel file for SD generated", "for", email, "open tickets", len(channelTickets))
+	// the filter range would include the group and spacer rows, so only the tables without groups are filtered
+	if err := formatTable(f, sheet, lastCol, i, layout.GroupBy == ""); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 	}
 
 	return nil
 }
 
-func (g excelGen) addRowsWithTicketData(f *excelize.File, userTickets ticket.List, sheet string, filename string, withAssignedTo bool) error {
-	var channelName string
-	var row string
-	i := 3
-	// Excel rows with ticket data
-	for _, t := range userTickets {
-		i++
-		if channelName != t.ChannelName {
-			i++
-			row = strconv.Itoa(i)
-			if err := f.SetCellValue(sheet, "A"+row, "Channel: "+t.ChannelName); err != nil {
-				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-			}
-			channelName = t.ChannelName
-			i += 2
+// setTicketCell writes the field of the ticket to the cell, the ticket number is linked to the ticket and the creation
+// date is written as Excel date. fill is the color of the highlighted row, empty if the row is not highlighted.
+func setTicketCell(
+	f *excelize.File, st *styles, sheet, cell, field string, t ticket.Ticket, dates locale.Settings, fill string,
+) error {
+	switch field {
+	case FieldNumber:
+		return setTicketNumberCell(f, st, sheet, cell, t.TicketData, fill)
+	case FieldCreatedAt:
+		return setDateCell(f, st, sheet, cell, t.TicketData, dates, fill)
+	}
+
+	value := fieldValue(t, field)
+	if value == "" {
+		return nil
+	}
+
+	return f.SetCellValue(sheet, cell, value)
+}
+
+// ChangesSheet is the name of the sheet with changes of the tickets since the previous report
+const ChangesSheet = "Changes"
+
+// ChangesSinceCell is the cell of the changes sheet with the date of the previous report, empty if not known
+const ChangesSinceCell = "A2"
+
+// previousTickets returns tickets from the snapshot of the last successful job and the time they were downloaded
+// (zero if not known). If there is no such job or its snapshot has already expired, hasPrevious is false.
+func (g excelGen) previousTickets(ctx context.Context) (tickets ticket.List, since time.Time, hasPrevious bool, err error) {
+	j, err := g.jobRepository.GetLastSuccessfulJob(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, time.Time{}, false, nil
+		}
+		return nil, time.Time{}, false, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get last successful job from repository")
+	}
+
+	previous, err := g.jobSnapshot(ctx, j)
+	return previous.tickets, previous.since, previous.found, err
+}
+
+// snapshot contains tickets of the previous report and the time they were downloaded, found is false if there is
+// no previous report or its snapshot has expired
+type snapshot struct {
+	tickets ticket.List
+	since   time.Time
+	found   bool
+}
+
+// previousReport returns snapshot of the last successful job created before the time, the snapshots are cached by job
+func (g excelGen) previousReport(ctx context.Context, before time.Time, cache map[ref.UUID]snapshot) (snapshot, error) {
+	j, err := g.jobRepository.GetLastSuccessfulJobBefore(ctx, before)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return snapshot{}, nil
+		}
+		return snapshot{}, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get last successful job before %s from repository", before)
+	}
+
+	if previous, ok := cache[j.UUID()]; ok {
+		return previous, nil
+	}
+
+	previous, err := g.jobSnapshot(ctx, j)
+	if err != nil {
+		return snapshot{}, err
+	}
+	cache[j.UUID()] = previous
+
+	return previous, nil
+}
+
+// jobSnapshot returns snapshot of the tickets downloaded by the job
+func (g excelGen) jobSnapshot(ctx context.Context, j job.Job) (snapshot, error) {
+	tickets, err := g.snapshotRepository.GetSnapshot(ctx, j.UUID())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			g.logger.Warnw("Ticket snapshot of the previous report not found, changes will not be reported", "job", j.UUID())
+			return snapshot{}, nil
 		}
-		row = strconv.Itoa(i)
+		return snapshot{}, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get ticket snapshot of job '%s' from repository", j.UUID())
+	}
+
+	downloadedAt := j.TicketsDownloadFinishedAt
+	if downloadedAt.IsZero() {
+		downloadedAt = j.CreatedAt
+	}
+	since, _ := downloadedAt.ToTime() // zero if not known
 
-		if err := f.SetCellValue(sheet, "A"+row, t.TicketType); err != nil {
+	return snapshot{tickets: tickets, since: since, found: true}, nil
+}
+
+// addChangesSheet adds sheet with changes of the tickets since the previous report, the date of the previous report
+// is shown below the caption if it is known
+func (g excelGen) addChangesSheet(
+	f *excelize.File, changes []ticket.Change, since time.Time, dates locale.Settings, filename string,
+) error {
+	sheet := ChangesSheet
+	f.NewSheet(sheet)
+	st := newStyles(f, dates.ExcelDateFormat())
+
+	// Set columns width
+	if err := f.SetColWidth(sheet, "A", "A", 18); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if err := f.SetColWidth(sheet, "B", "C", 13); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if err := f.SetColWidth(sheet, "D", "D", 10); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if err := f.SetColWidth(sheet, "E", "F", 45); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	// Sheet header
+	if err := f.SetCellValue(sheet, "A1", "Changes since the previous report"); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if err := st.set(sheet, "A1", "A1", styleCaption, ""); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if !since.IsZero() {
+		if err := f.SetCellValue(sheet, ChangesSinceCell, dates.Date(since)); err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 		}
-		if err := f.SetCellValue(sheet, "B"+row, t.TicketData.Number); err != nil {
+		if err := st.set(sheet, ChangesSinceCell, ChangesSinceCell, styleDate, ""); err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 		}
-		if err := f.SetCellValue(sheet, "C"+row, t.TicketData.StateName()); err != nil {
+	}
+	if err := f.SetSheetRow(sheet, "A3", &[]interface{}{"Change", "Ticket type", "Number", "State", "Title", "Details"}); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+	if err := st.set(sheet, "A3", "F3", styleHeader, ""); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	if len(changes) == 0 {
+		if err := f.SetCellValue(sheet, "A4", "No changes"); err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 		}
-		if err := f.SetCellValue(sheet, "D"+row, t.TicketData.ShortDescription); err != nil {
+		return nil
+	}
+
+	for i, c := range changes {
+		row := strconv.Itoa(i + 4)
+		if err := f.SetSheetRow(sheet, "A"+row, &[]interface{}{
+			string(c.Type),
+			c.Ticket.TicketType,
+			c.Ticket.TicketData.Number,
+			c.Ticket.TicketData.StateName(),
+			c.Ticket.TicketData.ShortDescription,
+			c.Details,
+		}); err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 		}
-		if err := f.SetCellValue(sheet, "E"+row, t.TicketData.Location); err != nil {
+		if err := setTicketNumberCell(f, st, sheet, "C"+row, c.Ticket.TicketData, ""); err != nil {
 			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 		}
+	}
 
-		if withAssignedTo {
-			if t.UserEmail != "" {
-				if err := f.SetCellValue(sheet, "F"+row, t.UserName); err != nil {
-					return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-				}
-				if err := f.SetCellValue(sheet, "G"+row, t.UserEmail); err != nil {
-					return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-				}
-				if err := f.SetCellValue(sheet, "H"+row, t.UserOrgName); err != nil {
-					return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-				}
-			}
-
-			if err := f.SetCellValue(sheet, "I"+row, t.TicketData.CreatedAtDate()); err != nil {
-				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
-			}
-		}
+	if err := formatTable(f, sheet, "F", len(changes)+headerRow, true); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
 	}
 
 	return nil
 }
+
+// setTicketNumberCell writes the ticket number to the cell, linked to the ticket in the ITSM UI if the ticket URL is known
+func setTicketNumberCell(f *excelize.File, st *styles, sheet, cell string, data ticket.Data, fill string) error {
+	if err := f.SetCellValue(sheet, cell, data.Number); err != nil {
+		return err
+	}
+
+	if data.URL == "" {
+		return nil
+	}
+
+	if err := f.SetCellHyperLink(sheet, cell, data.URL, "External"); err != nil {
+		return err
+	}
+
+	return st.set(sheet, cell, cell, styleLink, fill)
+}
+
+// setDateCell writes the ticket creation date to the cell as Excel date in the recipient's timezone and date format.
+// If the creation time cannot be parsed, its original value is written.
+func setDateCell(f *excelize.File, st *styles, sheet, cell string, data ticket.Data, dates locale.Settings, fill string) error {
+	createdAt, err := data.CreatedAtTime()
+	if err != nil {
+		return f.SetCellValue(sheet, cell, data.CreatedAt)
+	}
+
+	if err := f.SetCellValue(sheet, cell, dates.Date(createdAt)); err != nil {
+		return err
+	}
+
+	return st.set(sheet, cell, cell, styleDate, fill)
+}
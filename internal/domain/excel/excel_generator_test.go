@@ -0,0 +1,151 @@
+package excel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/locale"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/recipient"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+func TestExcelGen_addTicketSheet(t *testing.T) {
+	g := excelGen{clock: mocks.NewFixedClock()} // 2021-04-01
+
+	tickets := ticket.List{
+		{ChannelName: "First", TicketData: ticket.Data{Number: "INC1", State: "New", CreatedAt: "2021-03-25T10:00:00Z"}},
+		{ChannelName: "First", TicketData: ticket.Data{Number: "INC2", State: "New", CreatedAt: "2021-03-31T10:00:00Z"}},
+	}
+
+	layout := Layout{
+		Columns:    []Column{{Field: FieldNumber, Label: "Number"}, {Field: FieldState, Label: "State"}},
+		Highlights: []Highlight{{State: "New", OlderThanDays: 2, Color: "#FFC7CE"}},
+	}
+
+	hasFilter := func(f *excelize.File) bool {
+		for _, name := range f.GetDefinedName() {
+			if name.Name == "_xlnm._FilterDatabase" {
+				return true
+			}
+		}
+		return false
+	}
+
+	f := excelize.NewFile()
+	require.NoError(t, g.addTicketSheet(f, "Sheet1", "test.xlsx", "Open tickets", layout, tickets, locale.Settings{}))
+
+	// the age of the tickets is measured at the time of the clock
+	oldStyle, err := f.GetCellStyle("Sheet1", "B4")
+	require.NoError(t, err)
+	newStyle, err := f.GetCellStyle("Sheet1", "B5")
+	require.NoError(t, err)
+	assert.NotEqual(t, oldStyle, newStyle, "only the ticket older than 2 days at the time of the clock is highlighted")
+
+	assert.True(t, hasFilter(f))
+
+	// the group rows would be filtered with the tickets
+	layout.GroupBy = FieldChannel
+	f = excelize.NewFile()
+	require.NoError(t, g.addTicketSheet(f, "Sheet1", "test.xlsx", "Open tickets", layout, tickets, locale.Settings{}))
+
+	assert.False(t, hasFilter(f))
+}
+
+func TestExcelGen_GenerateExcelFilesForFieldEngineers_changesSincePreviousReport(t *testing.T) {
+	ctx := context.Background()
+	const email = "fe@email.test"
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	clock := mocks.NewFixedClock() // Thursday 2021-04-01
+	jobRepository := memory.NewJobRepositoryMemory(clock)
+	snapshotRepository := memory.NewTicketSnapshotRepositoryMemory(clock, 6*24*time.Hour)
+	ticketRepository := memory.NewTicketRepositoryMemory()
+	preferencesRepository := memory.NewPreferencesRepositoryMemory()
+	channelRepository := memory.NewChannelRepositoryMemory()
+
+	newTicket := func(number string) ticket.Ticket {
+		return ticket.Ticket{
+			UserEmail: email, ChannelID: "c1", ChannelName: "First", TicketType: "Incident", RecordType: "incident",
+			TicketData: ticket.Data{Number: number, StateID: 1},
+		}
+	}
+
+	// the jobs of the previous Thursday and of yesterday
+	now := clock.Now()
+	snapshots := []ticket.List{{newTicket("INC1")}, {newTicket("INC1"), newTicket("INC2")}}
+	for i, created := range []time.Time{now.AddDate(0, 0, -7), now.AddDate(0, 0, -1)} {
+		clock.SetTime(created)
+		jobID, err := jobRepository.AddJob(ctx, job.Job{Type: job.TypeAll})
+		require.NoError(t, err)
+		j, err := jobRepository.GetJob(ctx, jobID)
+		require.NoError(t, err)
+		j.FinalStatus = job.StatusSuccess
+		_, err = jobRepository.UpdateJob(ctx, j)
+		require.NoError(t, err)
+		require.NoError(t, snapshotRepository.SaveSnapshot(ctx, jobID, snapshots[i], nil))
+	}
+	clock.SetTime(now)
+
+	require.NoError(t, ticketRepository.AddTicketList(ctx, ticket.List{newTicket("INC1"), newTicket("INC2"), newTicket("INC3")}))
+
+	g := NewExcelGenerator(zap.NewNop().Sugar(), clock, channelRepository, ticketRepository, jobRepository,
+		snapshotRepository, nil, DefaultLayouts(nil), nil, locale.Config{},
+		prefsvc.NewPreferencesService(clock, preferencesRepository, channelRepository, nil, "", 0, ""),
+		recipient.Filter{},
+	).(*excelGen)
+	g.dirName = t.TempDir()
+
+	// changedNumbers returns numbers of the changed tickets in the generated file, nil without the changes sheet
+	changedNumbers := func() []string {
+		require.NoError(t, g.GenerateExcelFilesForFieldEngineers(ctx))
+
+		f, err := excelize.OpenFile(filepath.Join(g.FEDirPath(), email+".xlsx"))
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		if f.GetSheetIndex(ChangesSheet) < 0 {
+			return nil
+		}
+
+		rows, err := f.GetRows(ChangesSheet)
+		require.NoError(t, err)
+
+		numbers := []string{}
+		for _, row := range rows[3:] {
+			numbers = append(numbers, row[2])
+		}
+		return numbers
+	}
+
+	// daily recipient gets the changes since yesterday
+	assert.Equal(t, []string{"INC3"}, changedNumbers())
+
+	// weekly recipient gets the changes since the report of the previous Thursday
+	weekly := preferences.Default(email)
+	weekly.Frequency = preferences.FrequencyWeekly
+	weekly.Weekday = time.Thursday
+	require.NoError(t, preferencesRepository.SavePreferences(ctx, weekly))
+
+	assert.Equal(t, []string{"INC2", "INC3"}, changedNumbers())
+
+	// the changes are not reported when the snapshot of the previous report has expired
+	_, err = snapshotRepository.DeleteExpiredSnapshots(ctx)
+	require.NoError(t, err)
+
+	assert.Nil(t, changedNumbers())
+}
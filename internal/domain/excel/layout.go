@@ -0,0 +1,309 @@
+package excel
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+)
+
+// Fields of the tickets shown in the report columns, extra ticket fields are referenced by their key
+const (
+	FieldTicketType    = "ticket_type"
+	FieldNumber        = "number"
+	FieldState         = "state"
+	FieldTitle         = "title"
+	FieldLocation      = "location"
+	FieldAssigneeName  = "assignee_name"
+	FieldAssigneeEmail = "assignee_email"
+	FieldAssigneeOrg   = "assignee_org"
+	FieldCreatedAt     = "created_at"
+	FieldChannel       = "channel"
+)
+
+// builtinField is the field of the ticket with the default label and width of its column
+type builtinField struct {
+	label string
+	width float64
+	value func(t ticket.Ticket) string
+}
+
+var builtinFields = map[string]builtinField{
+	FieldTicketType: {"Ticket type", 13, func(t ticket.Ticket) string { return t.TicketType }},
+	FieldNumber:     {"Number", 13, func(t ticket.Ticket) string { return t.TicketData.Number }},
+	FieldState:      {"State", 10, func(t ticket.Ticket) string { return t.TicketData.StateName() }},
+	FieldTitle:      {"Title", 45, func(t ticket.Ticket) string { return t.TicketData.ShortDescription }},
+	FieldLocation:   {"Location", 45, func(t ticket.Ticket) string { return t.TicketData.Location }},
+	FieldAssigneeName: {"Assigned to (Name)", 20, func(t ticket.Ticket) string {
+		if t.HasUnknownAssignee() {
+			return ticket.UnknownAssignee
+		}
+		return t.UserName
+	}},
+	FieldAssigneeEmail: {"Assigned to (Email)", 20, func(t ticket.Ticket) string { return t.UserEmail }},
+	FieldAssigneeOrg:   {"Assigned to (Org)", 20, func(t ticket.Ticket) string { return t.UserOrgName }},
+	FieldCreatedAt:     {"Created at", 20, func(t ticket.Ticket) string { return t.TicketData.CreatedAt }},
+	FieldChannel:       {"Channel", 20, func(t ticket.Ticket) string { return t.ChannelName }},
+}
+
+// extraFieldWidth is the default width of the columns of the extra ticket fields
+const extraFieldWidth = 20
+
+// Layout defines the columns of the sheet with the tickets, how the rows are grouped and sorted
+type Layout struct {
+	Columns []Column
+	// GroupBy is the field grouping the rows, each group starts with the row "<label>: <value>"
+	// (e.g. "Channel: First channel"); empty = no grouping
+	GroupBy string
+	// SortBy orders the rows within the groups; empty = the order of the ticket repository
+	SortBy []SortKey
+	// Highlights color the rows of the tickets by their state and age, the first matching highlight is used
+	Highlights []Highlight
+}
+
+// Column of the sheet with the tickets
+type Column struct {
+	// Field shown in the column, one of the Field* constants or the key of the extra ticket field
+	Field string
+	Label string
+	// Width of the column, 0 = default width of Excel
+	Width float64
+}
+
+// SortKey orders the rows by the field
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// Layouts are the layouts of the reports
+type Layouts struct {
+	// FieldEngineer is the layout of the reports of the field engineers with their tickets
+	FieldEngineer Layout
+	// AllTickets is the layout of the reports of the service desk agents and channel owners with the tickets
+	// and their assignees
+	AllTickets Layout
+	// SummaryCharts adds the charts of the ticket counts to the summary sheet of the reports of service desk agents
+	SummaryCharts bool
+}
+
+// DefaultLayouts returns the layouts of the reports used by default, the extra fields are added after the default columns
+func DefaultLayouts(extraFields []ticket.Field) Layouts {
+	fe := Layout{GroupBy: FieldChannel}
+	for _, field := range []string{FieldTicketType, FieldNumber, FieldState, FieldTitle, FieldLocation} {
+		fe.Columns = append(fe.Columns, defaultColumn(field, extraFields))
+	}
+
+	all := Layout{GroupBy: FieldChannel}
+	all.Columns = append(all.Columns, fe.Columns...)
+	for _, field := range []string{FieldAssigneeName, FieldAssigneeEmail, FieldAssigneeOrg, FieldCreatedAt} {
+		all.Columns = append(all.Columns, defaultColumn(field, extraFields))
+	}
+
+	for _, field := range extraFields {
+		fe.Columns = append(fe.Columns, defaultColumn(field.Key, extraFields))
+		all.Columns = append(all.Columns, defaultColumn(field.Key, extraFields))
+	}
+
+	return Layouts{
+		FieldEngineer: fe,
+		AllTickets:    all,
+	}
+}
+
+// ParseLayout returns the layout modified by the definitions, empty definition keeps the columns or the sort order
+// of the layout.
+// Columns definition is a comma separated list of "field[=Label][:width]" items, e.g. "number,state:12,title=Summary:60";
+// label and width default to the ones of the field. Sort definition is a comma separated list of "field[:desc]" items,
+// e.g. "created_at:desc,number". Group by is the name of the field, empty = no grouping.
+// Fields are the Field* constants and the keys of the extra fields.
+func ParseLayout(layout Layout, columnsDef, groupBy, sortDef string, extraFields []ticket.Field) (Layout, error) {
+	if strings.TrimSpace(columnsDef) != "" {
+		layout.Columns = nil
+		for _, item := range strings.Split(columnsDef, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			column, err := parseColumn(item, extraFields)
+			if err != nil {
+				return layout, err
+			}
+			layout.Columns = append(layout.Columns, column)
+		}
+	}
+
+	layout.GroupBy = strings.TrimSpace(groupBy)
+	if layout.GroupBy != "" && !isField(layout.GroupBy, extraFields) {
+		return layout, fmt.Errorf("unknown group by field '%s'", layout.GroupBy)
+	}
+
+	if strings.TrimSpace(sortDef) != "" {
+		layout.SortBy = nil
+		for _, item := range strings.Split(sortDef, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			key := SortKey{Field: item}
+			if i := strings.LastIndex(item, ":"); i >= 0 {
+				key.Field = strings.TrimSpace(item[:i])
+				switch order := strings.TrimSpace(item[i+1:]); order {
+				case "asc":
+				case "desc":
+					key.Descending = true
+				default:
+					return layout, fmt.Errorf("invalid sort order '%s' of field '%s', expected 'asc' or 'desc'", order, key.Field)
+				}
+			}
+
+			if !isField(key.Field, extraFields) {
+				return layout, fmt.Errorf("unknown sort field '%s'", key.Field)
+			}
+			layout.SortBy = append(layout.SortBy, key)
+		}
+	}
+
+	return layout, nil
+}
+
+// parseColumn parses the "field[=Label][:width]" column definition
+func parseColumn(item string, extraFields []ticket.Field) (Column, error) {
+	def := item
+
+	// the suffix is the width only if it is a number, so that the labels can contain colons
+	width := -1.0
+	if i := strings.LastIndex(def, ":"); i >= 0 {
+		if w, err := strconv.ParseFloat(strings.TrimSpace(def[i+1:]), 64); err == nil {
+			if w < 0 {
+				return Column{}, fmt.Errorf("invalid column '%s', width must not be negative", item)
+			}
+			width = w
+			def = def[:i]
+		}
+	}
+
+	var label string
+	if i := strings.Index(def, "="); i >= 0 {
+		label = strings.TrimSpace(def[i+1:])
+		def = def[:i]
+	}
+
+	field := strings.TrimSpace(def)
+	if !isField(field, extraFields) {
+		return Column{}, fmt.Errorf("invalid column '%s', unknown field '%s'", item, field)
+	}
+
+	column := defaultColumn(field, extraFields)
+	if label != "" {
+		column.Label = label
+	}
+	if width >= 0 {
+		column.Width = width
+	}
+
+	return column, nil
+}
+
+// defaultColumn returns the column of the field with its default label and width
+func defaultColumn(field string, extraFields []ticket.Field) Column {
+	if f, ok := builtinFields[field]; ok {
+		return Column{Field: field, Label: f.label, Width: f.width}
+	}
+
+	for _, f := range extraFields {
+		if f.Key == field {
+			return Column{Field: field, Label: f.Label, Width: extraFieldWidth}
+		}
+	}
+
+	return Column{Field: field, Label: field}
+}
+
+// isField returns true if the field is a built-in field or the key of the extra field
+func isField(field string, extraFields []ticket.Field) bool {
+	if _, ok := builtinFields[field]; ok {
+		return true
+	}
+
+	for _, f := range extraFields {
+		if f.Key == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldValue returns the value of the field of the ticket, built-in fields take precedence over the extra fields
+func fieldValue(t ticket.Ticket, field string) string {
+	if f, ok := builtinFields[field]; ok {
+		return f.value(t)
+	}
+
+	return t.TicketData.Field(field)
+}
+
+// ColumnIndex returns zero-based index of the column of the field, -1 if the layout has no such column
+func (l Layout) ColumnIndex(field string) int {
+	for i, c := range l.Columns {
+		if c.Field == field {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// groupLabel returns the label of the group with the value of the group field, e.g. "Channel: First channel"
+func (l Layout) groupLabel(value string, extraFields []ticket.Field) string {
+	return defaultColumn(l.GroupBy, extraFields).Label + ": " + value
+}
+
+// sortTickets returns the tickets sorted by the group field and then by the sort keys, so that each group is one run
+// of rows. Tickets with equal keys keep their original order, without grouping and sort keys the order is unchanged.
+func (l Layout) sortTickets(tickets ticket.List) ticket.List {
+	keys := l.SortBy
+	if l.GroupBy != "" {
+		keys = append([]SortKey{{Field: l.GroupBy}}, keys...)
+	}
+
+	if len(keys) == 0 {
+		return tickets
+	}
+
+	sorted := make(ticket.List, len(tickets))
+	copy(sorted, tickets)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, key := range keys {
+			c := compareField(sorted[i], sorted[j], key.Field)
+			if c == 0 {
+				continue
+			}
+			if key.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	return sorted
+}
+
+// compareField compares the field of the tickets, ticket types are compared by the sort order of their record type
+func compareField(a, b ticket.Ticket, field string) int {
+	if field == FieldTicketType && a.TicketTypeOrder != b.TicketTypeOrder {
+		if a.TicketTypeOrder < b.TicketTypeOrder {
+			return -1
+		}
+		return 1
+	}
+
+	return strings.Compare(fieldValue(a, field), fieldValue(b, field))
+}
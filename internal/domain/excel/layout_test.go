@@ -0,0 +1,119 @@
+package excel
+
+import (
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultLayouts(t *testing.T) {
+	extraFields := []ticket.Field{{Key: "priority", Label: "Priority", Path: "priority"}}
+	layouts := DefaultLayouts(extraFields)
+
+	assert.Equal(t, Layout{
+		Columns: []Column{
+			{Field: FieldTicketType, Label: "Ticket type", Width: 13},
+			{Field: FieldNumber, Label: "Number", Width: 13},
+			{Field: FieldState, Label: "State", Width: 10},
+			{Field: FieldTitle, Label: "Title", Width: 45},
+			{Field: FieldLocation, Label: "Location", Width: 45},
+			{Field: "priority", Label: "Priority", Width: 20},
+		},
+		GroupBy: FieldChannel,
+	}, layouts.FieldEngineer)
+
+	var labels []string
+	for _, c := range layouts.AllTickets.Columns {
+		labels = append(labels, c.Label)
+	}
+	assert.Equal(t, []string{
+		"Ticket type", "Number", "State", "Title", "Location",
+		"Assigned to (Name)", "Assigned to (Email)", "Assigned to (Org)", "Created at", "Priority",
+	}, labels)
+	assert.Equal(t, FieldChannel, layouts.AllTickets.GroupBy)
+}
+
+func TestParseLayout(t *testing.T) {
+	extraFields := []ticket.Field{{Key: "priority", Label: "Priority", Path: "priority"}}
+	defaults := DefaultLayouts(extraFields).FieldEngineer
+
+	t.Run("empty definitions keep the columns", func(t *testing.T) {
+		l, err := ParseLayout(defaults, "", FieldChannel, "", extraFields)
+		require.NoError(t, err)
+		assert.Equal(t, defaults, l)
+	})
+
+	t.Run("columns with labels and widths, grouping and sort order", func(t *testing.T) {
+		l, err := ParseLayout(defaults, "number, priority:8, title=Summary: short:60, assignee_name=Owner", FieldState,
+			"priority:desc, created_at", extraFields)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Column{
+			{Field: FieldNumber, Label: "Number", Width: 13},
+			{Field: "priority", Label: "Priority", Width: 8},
+			{Field: FieldTitle, Label: "Summary: short", Width: 60},
+			{Field: FieldAssigneeName, Label: "Owner", Width: 20},
+		}, l.Columns)
+		assert.Equal(t, FieldState, l.GroupBy)
+		assert.Equal(t, []SortKey{{Field: "priority", Descending: true}, {Field: FieldCreatedAt}}, l.SortBy)
+	})
+
+	t.Run("no grouping", func(t *testing.T) {
+		l, err := ParseLayout(defaults, "", "", "", extraFields)
+		require.NoError(t, err)
+		assert.Empty(t, l.GroupBy)
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		_, err := ParseLayout(defaults, "number,urgency", "", "", extraFields)
+		assert.Error(t, err)
+
+		_, err = ParseLayout(defaults, "number:-5", "", "", extraFields)
+		assert.Error(t, err)
+
+		_, err = ParseLayout(defaults, "", "urgency", "", extraFields)
+		assert.Error(t, err)
+
+		_, err = ParseLayout(defaults, "", "", "number:up", extraFields)
+		assert.Error(t, err)
+
+		_, err = ParseLayout(defaults, "", "", "urgency", extraFields)
+		assert.Error(t, err)
+	})
+}
+
+func TestLayout_sortTickets(t *testing.T) {
+	tickets := ticket.List{
+		{ChannelName: "B", TicketType: "Request", TicketTypeOrder: 2, TicketData: ticket.Data{Number: "REQ1"}},
+		{ChannelName: "A", TicketType: "Incident", TicketTypeOrder: 1, TicketData: ticket.Data{Number: "INC2"}},
+		{ChannelName: "B", TicketType: "Incident", TicketTypeOrder: 1, TicketData: ticket.Data{Number: "INC3"}},
+		{ChannelName: "A", TicketType: "Request", TicketTypeOrder: 2, TicketData: ticket.Data{Number: "REQ4"}},
+	}
+
+	numbers := func(list ticket.List) []string {
+		var n []string
+		for _, t := range list {
+			n = append(n, t.TicketData.Number)
+		}
+		return n
+	}
+
+	// without grouping and sort keys the order is kept
+	assert.Equal(t, []string{"REQ1", "INC2", "INC3", "REQ4"}, numbers(Layout{}.sortTickets(tickets)))
+
+	// without sort keys the tickets are sorted by the group only, keeping their order within the group
+	l := Layout{GroupBy: FieldChannel}
+	assert.Equal(t, []string{"INC2", "REQ4", "REQ1", "INC3"}, numbers(l.sortTickets(tickets)))
+
+	// sorted by the group first, ticket types by their record type order
+	l.SortBy = []SortKey{{Field: FieldTicketType, Descending: true}}
+	assert.Equal(t, []string{"REQ4", "INC2", "REQ1", "INC3"}, numbers(l.sortTickets(tickets)))
+
+	// the original list is not changed
+	assert.Equal(t, []string{"REQ1", "INC2", "INC3", "REQ4"}, numbers(tickets))
+
+	l = Layout{SortBy: []SortKey{{Field: FieldNumber}}}
+	assert.Equal(t, []string{"INC2", "INC3", "REQ1", "REQ4"}, numbers(l.sortTickets(tickets)))
+}
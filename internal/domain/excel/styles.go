@@ -0,0 +1,217 @@
+package excel
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/xuri/excelize/v2"
+)
+
+// Highlight colors the rows of the tickets in the state older than the number of days
+type Highlight struct {
+	// State name of the tickets, empty = any state
+	State string
+	// OlderThanDays is the minimum age of the tickets since their creation, 0 = any age
+	OlderThanDays int
+	// Color of the row fill, e.g. "#FFC7CE"
+	Color string
+}
+
+// Matches returns true if the ticket is highlighted at the time now
+func (h Highlight) Matches(t ticket.Ticket, now time.Time) bool {
+	if h.State != "" && !strings.EqualFold(h.State, t.TicketData.StateName()) {
+		return false
+	}
+
+	if h.OlderThanDays > 0 {
+		createdAt, err := t.TicketData.CreatedAtTime()
+		if err != nil {
+			return false // age is not known
+		}
+		if now.Sub(createdAt) <= time.Duration(h.OlderThanDays)*24*time.Hour {
+			return false
+		}
+	}
+
+	return true
+}
+
+var colorRegexp = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ParseHighlights parses comma separated list of highlights in the form "state[>days]=#RRGGBB", state "*" matches
+// tickets in any state, e.g. "New>2=#FFC7CE,In progress>7=#FFEB9C,*>30=#D9D9D9".
+// The first highlight matching the ticket colors its row.
+func ParseHighlights(definition string) ([]Highlight, error) {
+	var highlights []Highlight
+
+	for _, item := range strings.Split(definition, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		i := strings.LastIndex(item, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid highlight '%s', expected 'state[>days]=#RRGGBB'", item)
+		}
+
+		h := Highlight{Color: strings.TrimSpace(item[i+1:])}
+		if !colorRegexp.MatchString(h.Color) {
+			return nil, fmt.Errorf("invalid highlight '%s', color must be in the form #RRGGBB", item)
+		}
+
+		condition := item[:i]
+		if j := strings.LastIndex(condition, ">"); j >= 0 {
+			days, err := strconv.Atoi(strings.TrimSpace(condition[j+1:]))
+			if err != nil || days < 0 {
+				return nil, fmt.Errorf("invalid highlight '%s', age must be a non-negative number of days", item)
+			}
+			h.OlderThanDays = days
+			condition = condition[:j]
+		}
+
+		h.State = strings.TrimSpace(condition)
+		if h.State == "" {
+			return nil, fmt.Errorf("invalid highlight '%s', state must not be empty, use '*' for any state", item)
+		}
+		if h.State == "*" {
+			h.State = ""
+		}
+
+		highlights = append(highlights, h)
+	}
+
+	return highlights, nil
+}
+
+// highlightColor returns the fill color of the row of the ticket, empty if the ticket is not highlighted
+func highlightColor(highlights []Highlight, t ticket.Ticket, now time.Time) string {
+	for _, h := range highlights {
+		if h.Matches(t, now) {
+			return h.Color
+		}
+	}
+
+	return ""
+}
+
+// Colors of the sheets
+const (
+	headerFillColor = "#1F4E78"
+	headerFontColor = "#FFFFFF"
+	groupFillColor  = "#DDEBF7"
+	linkFontColor   = "#0563C1"
+	borderColor     = "#BFBFBF"
+)
+
+// styleKind is the kind of the cell style
+type styleKind int
+
+const (
+	styleCaption styleKind = iota
+	styleHeader
+	styleGroup
+	styleText
+	styleLink
+	styleDate
+
+	// styleNone leaves the cells without style
+	styleNone styleKind = -1
+)
+
+// styleKey identifies the style of the cell, the row fill is combined with the style of the cell
+type styleKey struct {
+	kind styleKind
+	fill string
+}
+
+// styles creates the styles of the Excel file on demand, each style is created once
+type styles struct {
+	f          *excelize.File
+	dateFormat string
+	ids        map[styleKey]int
+}
+
+func newStyles(f *excelize.File, dateFormat string) *styles {
+	return &styles{
+		f:          f,
+		dateFormat: dateFormat,
+		ids:        make(map[styleKey]int),
+	}
+}
+
+// id returns ID of the style of the kind with the row fill color (empty = no fill)
+func (s *styles) id(kind styleKind, fill string) (int, error) {
+	key := styleKey{kind: kind, fill: fill}
+	if id, ok := s.ids[key]; ok {
+		return id, nil
+	}
+
+	style := &excelize.Style{}
+	switch kind {
+	case styleCaption:
+		style.Font = &excelize.Font{Bold: true, Size: 14}
+	case styleHeader:
+		style.Font = &excelize.Font{Bold: true, Color: headerFontColor}
+		fill = headerFillColor
+		style.Alignment = &excelize.Alignment{Vertical: "center", WrapText: true}
+		style.Border = []excelize.Border{{Type: "bottom", Color: borderColor, Style: 2}}
+	case styleGroup:
+		style.Font = &excelize.Font{Bold: true}
+		fill = groupFillColor
+	case styleLink:
+		style.Font = &excelize.Font{Color: linkFontColor, Underline: "single"}
+	case styleDate:
+		dateFormat := s.dateFormat
+		style.CustomNumFmt = &dateFormat
+		style.Alignment = &excelize.Alignment{Horizontal: "left"}
+	}
+
+	if fill != "" {
+		style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{fill}}
+	}
+
+	id, err := s.f.NewStyle(style)
+	if err != nil {
+		return 0, err
+	}
+	s.ids[key] = id
+
+	return id, nil
+}
+
+// set applies the style of the kind with the row fill color to the cells of the range
+func (s *styles) set(sheet, hCell, vCell string, kind styleKind, fill string) error {
+	id, err := s.id(kind, fill)
+	if err != nil {
+		return err
+	}
+
+	return s.f.SetCellStyle(sheet, hCell, vCell, id)
+}
+
+// formatTable freezes the rows above the data of the table with the header in the row headerRow and adds autofilter
+// to the table if filter is true. The table must contain only the header and the data rows to be filtered.
+func formatTable(f *excelize.File, sheet, lastCol string, lastRow int, filter bool) error {
+	if lastRow < headerRow {
+		lastRow = headerRow
+	}
+
+	if filter {
+		header := "A" + strconv.Itoa(headerRow)
+		if err := f.AutoFilter(sheet, header, lastCol+strconv.Itoa(lastRow), ""); err != nil {
+			return err
+		}
+	}
+
+	topLeft := "A" + strconv.Itoa(headerRow+1)
+	return f.SetPanes(sheet, fmt.Sprintf(
+		`{"freeze":true,"split":false,"x_split":0,"y_split":%d,"top_left_cell":"%s","active_pane":"bottomLeft",`+
+			`"panes":[{"sqref":"%s","active_cell":"%s","pane":"bottomLeft"}]}`,
+		headerRow, topLeft, topLeft, topLeft,
+	))
+}
@@ -0,0 +1,53 @@
+package excel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHighlights(t *testing.T) {
+	t.Run("empty definition", func(t *testing.T) {
+		h, err := ParseHighlights("")
+		require.NoError(t, err)
+		assert.Empty(t, h)
+	})
+
+	t.Run("states, ages and any state", func(t *testing.T) {
+		h, err := ParseHighlights("New>2=#FFC7CE, In progress = #FFEB9C, *>30=#d9d9d9")
+		require.NoError(t, err)
+		assert.Equal(t, []Highlight{
+			{State: "New", OlderThanDays: 2, Color: "#FFC7CE"},
+			{State: "In progress", Color: "#FFEB9C"},
+			{OlderThanDays: 30, Color: "#d9d9d9"},
+		}, h)
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		for _, def := range []string{"New", "New=red", "New>x=#FFC7CE", "New>-1=#FFC7CE", ">2=#FFC7CE"} {
+			_, err := ParseHighlights(def)
+			assert.Error(t, err, def)
+		}
+	})
+}
+
+func TestHighlightColor(t *testing.T) {
+	now := time.Date(2022, 3, 10, 12, 0, 0, 0, time.UTC)
+	highlights := []Highlight{
+		{State: "New", OlderThanDays: 2, Color: "#FFC7CE"},
+		{OlderThanDays: 30, Color: "#D9D9D9"},
+	}
+
+	newTicket := func(state, createdAt string) ticket.Ticket {
+		return ticket.Ticket{TicketData: ticket.Data{State: state, CreatedAt: createdAt}}
+	}
+
+	assert.Equal(t, "#FFC7CE", highlightColor(highlights, newTicket("new", "2022-03-07T12:00:00Z"), now))
+	assert.Equal(t, "", highlightColor(highlights, newTicket("New", "2022-03-08T13:00:00Z"), now))
+	assert.Equal(t, "", highlightColor(highlights, newTicket("In progress", "2022-03-07T12:00:00Z"), now))
+	assert.Equal(t, "#D9D9D9", highlightColor(highlights, newTicket("In progress", "2022-02-01T12:00:00Z"), now))
+	assert.Equal(t, "", highlightColor(highlights, newTicket("New", "unknown"), now))
+}
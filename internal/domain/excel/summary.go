@@ -0,0 +1,351 @@
+package excel
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/xuri/excelize/v2"
+)
+
+// SummarySheet is the name of the sheet with the counts of the tickets in the workbooks for service desk agents
+const SummarySheet = "Summary"
+
+// TicketSheets returns the names of the sheets with the tickets in the workbook, i.e. all sheets except the summary
+// and the changes
+func TicketSheets(f *excelize.File) []string {
+	var sheets []string
+	for _, sheet := range f.GetSheetList() {
+		if sheet != SummarySheet && sheet != ChangesSheet {
+			sheets = append(sheets, sheet)
+		}
+	}
+
+	return sheets
+}
+
+// maxSheetNameLength is the maximum length of the sheet name allowed by Excel
+const maxSheetNameLength = 31
+
+// sheetName returns the name of the sheet safe for Excel: without the characters : \ / ? * [ ], not starting or ending
+// with the apostrophe, at most 31 characters long and unique (case-insensitively) among the used names.
+// The returned name is added to the used names.
+func sheetName(name string, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '\\', '/', '?', '*', '[', ']':
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(strings.Trim(name, "'"))
+	if name == "" {
+		name = "Sheet"
+	}
+
+	unique := truncate(name, maxSheetNameLength)
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		suffix := " (" + strconv.Itoa(i) + ")"
+		unique = truncate(name, maxSheetNameLength-len(suffix)) + suffix
+	}
+	used[strings.ToLower(unique)] = true
+
+	return unique
+}
+
+// truncate returns the string shortened to n characters
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return strings.TrimSpace(string([]rune(s)[:n]))
+}
+
+// summary contains the counts of the tickets shown in the summary sheet
+type summary struct {
+	channels      []string
+	states        []string
+	byChannel     [][]int // counts of the tickets by channel (rows) and state (columns)
+	channelTotals []int
+	stateTotals   []int
+	total         int
+	assignees     []count
+	recordTypes   []count
+}
+
+// count is the number of the tickets with the name, detail is shown next to the name (e.g. assignee's email)
+type count struct {
+	name   string
+	detail string
+	n      int
+}
+
+// unassigned is shown in the summary instead of the assignee of the tickets not assigned to anybody
+const unassigned = "Unassigned"
+
+// newSummary counts the tickets by channel and state, by assignee and by record type. Channels are sorted by name,
+// states by their ID, assignees by the number of the tickets and record types by their sort order.
+func newSummary(tickets ticket.List) summary {
+	var s summary
+
+	channelIndex := make(map[string]int)
+	stateIDs := make(map[string]int)
+	for _, t := range tickets {
+		if _, ok := channelIndex[t.ChannelName]; !ok {
+			channelIndex[t.ChannelName] = 0
+			s.channels = append(s.channels, t.ChannelName)
+		}
+
+		state := t.TicketData.StateName()
+		if id, ok := stateIDs[state]; !ok || t.TicketData.StateID < id {
+			if !ok {
+				s.states = append(s.states, state)
+			}
+			stateIDs[state] = t.TicketData.StateID
+		}
+	}
+
+	sort.Strings(s.channels)
+	for i, ch := range s.channels {
+		channelIndex[ch] = i
+	}
+
+	sort.SliceStable(s.states, func(i, j int) bool {
+		return stateIDs[s.states[i]] < stateIDs[s.states[j]]
+	})
+	stateIndex := make(map[string]int, len(s.states))
+	for i, state := range s.states {
+		stateIndex[state] = i
+	}
+
+	s.byChannel = make([][]int, len(s.channels))
+	for i := range s.byChannel {
+		s.byChannel[i] = make([]int, len(s.states))
+	}
+	s.channelTotals = make([]int, len(s.channels))
+	s.stateTotals = make([]int, len(s.states))
+
+	assigneeIndex := make(map[string]int)
+	recordTypeIndex := make(map[string]int)
+	recordTypeOrder := make(map[string]int)
+
+	for _, t := range tickets {
+		c, st := channelIndex[t.ChannelName], stateIndex[t.TicketData.StateName()]
+		s.byChannel[c][st]++
+		s.channelTotals[c]++
+		s.stateTotals[st]++
+		s.total++
+
+		name, email := assigneeOf(t)
+		key := name + "\x00" + email
+		if i, ok := assigneeIndex[key]; ok {
+			s.assignees[i].n++
+		} else {
+			assigneeIndex[key] = len(s.assignees)
+			s.assignees = append(s.assignees, count{name: name, detail: email, n: 1})
+		}
+
+		if i, ok := recordTypeIndex[t.TicketType]; ok {
+			s.recordTypes[i].n++
+		} else {
+			recordTypeIndex[t.TicketType] = len(s.recordTypes)
+			recordTypeOrder[t.TicketType] = t.TicketTypeOrder
+			s.recordTypes = append(s.recordTypes, count{name: t.TicketType, n: 1})
+		}
+	}
+
+	sort.SliceStable(s.assignees, func(i, j int) bool {
+		if s.assignees[i].n != s.assignees[j].n {
+			return s.assignees[i].n > s.assignees[j].n
+		}
+		return s.assignees[i].name < s.assignees[j].name
+	})
+
+	sort.SliceStable(s.recordTypes, func(i, j int) bool {
+		oi, oj := recordTypeOrder[s.recordTypes[i].name], recordTypeOrder[s.recordTypes[j].name]
+		if oi != oj {
+			return oi < oj
+		}
+		return s.recordTypes[i].name < s.recordTypes[j].name
+	})
+
+	return s
+}
+
+// assigneeOf returns the name and the email of the assignee of the ticket shown in the summary
+func assigneeOf(t ticket.Ticket) (name, email string) {
+	switch {
+	case t.UserID == "":
+		return unassigned, ""
+	case t.HasUnknownAssignee():
+		return ticket.UnknownAssignee, ""
+	default:
+		return t.UserName, t.UserEmail
+	}
+}
+
+// addSummarySheet writes the counts of the tickets to the sheet: tickets by channel and state, by assignee and
+// by record type, each table with the totals. With charts enabled, the tickets by channel and by record type are
+// also shown in the charts next to the tables.
+func (g excelGen) addSummarySheet(f *excelize.File, sheet, filename string, tickets ticket.List) error {
+	s := newSummary(tickets)
+	st := newStyles(f, "")
+	w := sheetWriter{f: f, st: st, sheet: sheet}
+
+	w.caption("Open tickets summary")
+
+	// tickets by channel and state
+	row := headerRow
+	w.title(row, "Tickets by channel and state")
+	row++
+	header := []interface{}{"Channel"}
+	for _, state := range s.states {
+		header = append(header, state)
+	}
+	w.header(row, append(header, "Total"))
+
+	channelsFirstRow := row + 1
+	for i, ch := range s.channels {
+		row++
+		values := []interface{}{ch}
+		for _, n := range s.byChannel[i] {
+			values = append(values, n)
+		}
+		w.row(row, append(values, s.channelTotals[i]))
+	}
+	channelsLastRow := row
+
+	row++
+	totals := []interface{}{"Total"}
+	for _, n := range s.stateTotals {
+		totals = append(totals, n)
+	}
+	w.total(row, append(totals, s.total))
+	channelTotalCol := len(s.states) + 2
+
+	// tickets by assignee
+	row += 2
+	w.title(row, "Tickets by assignee")
+	row++
+	w.header(row, []interface{}{"Assignee", "Email", "Tickets"})
+	for _, a := range s.assignees {
+		row++
+		w.row(row, []interface{}{a.name, a.detail, a.n})
+	}
+	row++
+	w.total(row, []interface{}{"Total", "", s.total})
+
+	// tickets by record type
+	row += 2
+	w.title(row, "Tickets by record type")
+	row++
+	w.header(row, []interface{}{"Ticket type", "Tickets"})
+	typesFirstRow := row + 1
+	for _, rt := range s.recordTypes {
+		row++
+		w.row(row, []interface{}{rt.name, rt.n})
+	}
+	typesLastRow := row
+	row++
+	w.total(row, []interface{}{"Total", s.total})
+
+	if w.err == nil {
+		w.err = f.SetColWidth(sheet, "A", "A", 30)
+	}
+	if w.err == nil {
+		w.err = f.SetColWidth(sheet, "B", "B", 20)
+	}
+
+	if w.err == nil && g.layouts.SummaryCharts && len(tickets) > 0 {
+		chartCol, err := excelize.ColumnNumberToName(channelTotalCol + 2)
+		if err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+		}
+		totalCol, err := excelize.ColumnNumberToName(channelTotalCol)
+		if err != nil {
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+		}
+
+		w.err = f.AddChart(sheet, chartCol+strconv.Itoa(headerRow), barChart(
+			"Tickets by channel", sheet, "A", totalCol, channelsFirstRow, channelsLastRow,
+		))
+		if w.err == nil {
+			w.err = f.AddChart(sheet, chartCol+strconv.Itoa(headerRow+16), barChart(
+				"Tickets by record type", sheet, "A", "B", typesFirstRow, typesLastRow,
+			))
+		}
+	}
+
+	if w.err != nil {
+		return domain.WrapErrorf(w.err, domain.ErrorCodeUnknown, "could not write data to Excel file '%s'", filename)
+	}
+
+	return nil
+}
+
+// barChart returns format of the bar chart of the values in the rows of valuesCol named by the categoriesCol
+func barChart(title, sheet, categoriesCol, valuesCol string, firstRow, lastRow int) string {
+	ref := func(col string) string {
+		return fmt.Sprintf("'%s'!$%s$%d:$%s$%d", sheet, col, firstRow, col, lastRow)
+	}
+
+	return fmt.Sprintf(
+		`{"type":"bar","series":[{"categories":%q,"values":%q}],"title":{"name":%q},`+
+			`"legend":{"none":true},"dimension":{"width":480,"height":300}}`,
+		ref(categoriesCol), ref(valuesCol), title,
+	)
+}
+
+// sheetWriter writes the rows of the sheet, the first error stops writing and is kept in err
+type sheetWriter struct {
+	f     *excelize.File
+	st    *styles
+	sheet string
+	err   error
+}
+
+func (w *sheetWriter) caption(text string) {
+	w.write(captionRow, []interface{}{text}, styleCaption)
+}
+
+// title writes the title of the table
+func (w *sheetWriter) title(row int, text string) {
+	w.write(row, []interface{}{text}, styleGroup)
+}
+
+func (w *sheetWriter) header(row int, values []interface{}) {
+	w.write(row, values, styleHeader)
+}
+
+func (w *sheetWriter) row(row int, values []interface{}) {
+	w.write(row, values, styleNone)
+}
+
+func (w *sheetWriter) total(row int, values []interface{}) {
+	w.write(row, values, styleGroup)
+}
+
+// write writes the values to the row starting with the column A and styles them by the kind
+func (w *sheetWriter) write(row int, values []interface{}, kind styleKind) {
+	if w.err != nil {
+		return
+	}
+
+	first := "A" + strconv.Itoa(row)
+	if w.err = w.f.SetSheetRow(w.sheet, first, &values); w.err != nil || kind == styleNone {
+		return
+	}
+
+	lastCol, err := excelize.ColumnNumberToName(len(values))
+	if err != nil {
+		w.err = err
+		return
+	}
+
+	w.err = w.st.set(w.sheet, first, lastCol+strconv.Itoa(row), kind, "")
+}
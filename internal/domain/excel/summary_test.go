@@ -0,0 +1,77 @@
+package excel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSummary(t *testing.T) {
+	newTicket := func(channel, ticketType string, order, stateID int, userID, name, email string) ticket.Ticket {
+		return ticket.Ticket{
+			ChannelName:     channel,
+			TicketType:      ticketType,
+			TicketTypeOrder: order,
+			UserID:          userID,
+			UserName:        name,
+			UserEmail:       email,
+			TicketData:      ticket.Data{StateID: stateID},
+		}
+	}
+
+	tickets := ticket.List{
+		newTicket("Beta", "Request", 2, 2, "u1", "Alice", "alice@email.test"),
+		newTicket("Alpha", "Incident", 1, 0, "u1", "Alice", "alice@email.test"),
+		newTicket("Beta", "Incident", 1, 0, "", "", ""),
+		newTicket("Alpha", "Incident", 1, 2, "u2", "Bob", "bob@email.test"),
+		newTicket("Alpha", "Request", 2, 0, "u1", "Alice", "alice@email.test"),
+		newTicket("Beta", "Incident", 1, 0, "u3", "", ""),
+	}
+
+	s := newSummary(tickets)
+
+	assert.Equal(t, []string{"Alpha", "Beta"}, s.channels)
+	assert.Equal(t, []string{"New", "In progress"}, s.states)
+	assert.Equal(t, [][]int{{2, 1}, {2, 1}}, s.byChannel)
+	assert.Equal(t, []int{3, 3}, s.channelTotals)
+	assert.Equal(t, []int{4, 2}, s.stateTotals)
+	assert.Equal(t, 6, s.total)
+
+	assert.Equal(t, []count{
+		{name: "Alice", detail: "alice@email.test", n: 3},
+		{name: "Bob", detail: "bob@email.test", n: 1},
+		{name: unassigned, n: 1},
+		{name: ticket.UnknownAssignee, n: 1},
+	}, s.assignees)
+
+	assert.Equal(t, []count{{name: "Incident", n: 4}, {name: "Request", n: 2}}, s.recordTypes)
+}
+
+func TestNewSummary_noTickets(t *testing.T) {
+	s := newSummary(nil)
+
+	assert.Empty(t, s.channels)
+	assert.Empty(t, s.states)
+	assert.Empty(t, s.assignees)
+	assert.Equal(t, 0, s.total)
+}
+
+func TestSheetName(t *testing.T) {
+	used := map[string]bool{"summary": true, "changes": true}
+
+	assert.Equal(t, "Sales_EMEA", sheetName("Sales/EMEA", used))
+	assert.Equal(t, "Ops _test_ _a_b_ c__d", sheetName("'Ops [test] *a?b: c\\/d'", used))
+	assert.Equal(t, "summary (2)", sheetName("summary", used))
+	assert.Equal(t, "Sheet", sheetName("''", used))
+
+	long := strings.Repeat("Channel ", 5)
+	first := sheetName(long, used)
+	assert.Equal(t, "Channel Channel Channel Channel", first)
+
+	second := sheetName(long, used)
+	assert.Equal(t, "Channel Channel Channel Cha (2)", second)
+
+	assert.Equal(t, "sales_emea (2)", sheetName("sales_emea", used))
+}
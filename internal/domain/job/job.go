@@ -49,8 +49,28 @@ type Job struct {
 
 	// Status of the finished job (success/error)
 	FinalStatus string
+
+	// Data-quality warnings found during the job, e.g. ticket assignees missing in the user directory
+	Warnings []string
+
+	// Mode of the tickets download (full/incremental); a job created with TicketsDownloadFull forces full resync
+	TicketsDownloadMode string
+
+	// Field engineers excluded from the reports by the recipient filter, with the reason
+	ExcludedRecipients []string
 }
 
+// Modes of the tickets download
+const (
+	// TicketsDownloadFull downloads all open tickets from the ITSM service
+	TicketsDownloadFull = "full"
+	// TicketsDownloadIncremental downloads only tickets updated since the last successful job
+	TicketsDownloadIncremental = "incremental"
+)
+
+// StatusSuccess is the final status of successfully finished job
+const StatusSuccess = "Success"
+
 // UUID getter
 func (e Job) UUID() ref.UUID {
 	return e.uuid
@@ -12,15 +12,17 @@ type Type struct {
 
 // Type values
 var (
-	TypeAll = Type{"all"}
-	TypeFE  = Type{"FE report only"}
-	TypeSD  = Type{"SD report only"}
+	TypeAll     = Type{"all"}
+	TypeFE      = Type{"FE report only"}
+	TypeSD      = Type{"SD report only"}
+	TypeChannel = Type{"Channel report only"}
 )
 
 var jobTypeValues = []Type{
 	TypeAll,
 	TypeFE,
 	TypeSD,
+	TypeChannel,
 }
 
 // NewTypeFromString creates new instance from string value
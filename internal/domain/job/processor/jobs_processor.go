@@ -15,7 +15,10 @@ import (
 	ticketdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/ticket/downloader"
 	userdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/user/downloader"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -94,67 +97,7 @@ func (p *processor) WaitForJobs() {
 
 		for range p.jobQueue {
 			// new job was inserted to the repository => process it
-			ctx := context.Background()
-			j, err := p.jobRepository.GetLastJob(ctx)
-			if err != nil {
-				p.logger.Errorw("Getting last job from the queue failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-			p.logger.Infow("New job read from the queue", "time", time.Now().Format(time.RFC3339), "id", j.UUID())
-
-			if err := p.userDownloader.Reset(ctx); err != nil {
-				p.logger.Errorw("User downloader reset failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-
-			if err := p.ticketDownloader.Reset(ctx); err != nil {
-				p.logger.Errorw("Ticket downloader reset failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-
-			if err := p.downloadChannelList(ctx, j.UUID()); err != nil {
-				p.logger.Errorw("Channels download failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-
-			if err := p.downloadUsersFromChannels(ctx, j.UUID()); err != nil {
-				p.logger.Errorw("Users download failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-
-			if err := p.downloadTicketsFromChannels(ctx, j.UUID()); err != nil {
-				p.logger.Errorw("Tickets download failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-
-			if err := p.generateExcelFiles(ctx, j.UUID()); err != nil {
-				p.logger.Errorw("Excel files generation failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-
-			if err := p.sendEmails(ctx, j.UUID()); err != nil {
-				p.logger.Errorw("Emails sending failed", "error", err)
-				p.markJobAsFailed(ctx, j.UUID(), err)
-				p.markAsReady()
-				continue
-			}
-
-			p.markJobAsFinished(ctx, j.UUID())
-			p.markAsReady()
+			p.processJob()
 		}
 	}()
 
@@ -162,6 +105,75 @@ func (p *processor) WaitForJobs() {
 	p.logger.Info("Jobs processor is waiting for new jobs")
 }
 
+// processJob processes the last job in the repository through all stages
+func (p *processor) processJob() {
+	// each job is traced, the stages and the calls to external services are its child spans
+	ctx, span := tracing.Start(context.Background(), "job")
+	defer span.End()
+
+	j, err := p.jobRepository.GetLastJob(ctx)
+	if err != nil {
+		p.logger.Errorw("Getting last job from the queue failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+	span.SetAttributes(tracing.JobIDKey.String(j.UUID().String()), attribute.String("job.type", j.Type.String()))
+	p.logger.Infow("New job read from the queue", "time", time.Now().Format(time.RFC3339), "id", j.UUID())
+
+	if err := p.userDownloader.Reset(ctx); err != nil {
+		p.logger.Errorw("User downloader reset failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+
+	if err := p.ticketDownloader.Reset(ctx); err != nil {
+		p.logger.Errorw("Ticket downloader reset failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+
+	if err := p.downloadChannelList(ctx, j.UUID()); err != nil {
+		p.logger.Errorw("Channels download failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+
+	if err := p.downloadUsersFromChannels(ctx, j.UUID()); err != nil {
+		p.logger.Errorw("Users download failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+
+	if err := p.downloadTicketsFromChannels(ctx, j.UUID()); err != nil {
+		p.logger.Errorw("Tickets download failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+
+	if err := p.generateExcelFiles(ctx, j.UUID()); err != nil {
+		p.logger.Errorw("Excel files generation failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+
+	if err := p.sendEmails(ctx, j.UUID()); err != nil {
+		p.logger.Errorw("Emails sending failed", "error", err)
+		p.markJobAsFailed(ctx, j.UUID(), err)
+		p.markAsReady()
+		return
+	}
+
+	p.markJobAsFinished(ctx, j.UUID())
+	p.markAsReady()
+}
+
 // ProcessNewJob notifies the job processor about new job
 func (p *processor) ProcessNewJob(jobID ref.UUID) error {
 	p.mu.Lock()
@@ -178,7 +190,10 @@ func (p *processor) ProcessNewJob(jobID ref.UUID) error {
 	return nil
 }
 
-func (p *processor) downloadChannelList(ctx context.Context, jobID ref.UUID) error {
+func (p *processor) downloadChannelList(ctx context.Context, jobID ref.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "download channels", tracing.JobIDKey.String(jobID.String()))
+	defer func() { tracing.End(span, err) }()
+
 	p.logger.Infow("Starting channel list download", "time", time.Now().Format(time.RFC3339), "job", jobID)
 
 	j, err := p.jobRepository.GetJob(ctx, jobID)
@@ -206,7 +221,10 @@ func (p *processor) downloadChannelList(ctx context.Context, jobID ref.UUID) err
 	return nil
 }
 
-func (p *processor) downloadUsersFromChannels(ctx context.Context, jobID ref.UUID) error {
+func (p *processor) downloadUsersFromChannels(ctx context.Context, jobID ref.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "download users", tracing.JobIDKey.String(jobID.String()))
+	defer func() { tracing.End(span, err) }()
+
 	p.logger.Infow("Starting users download", "time", time.Now().Format(time.RFC3339), "job", jobID)
 
 	j, err := p.jobRepository.GetJob(ctx, jobID)
@@ -234,7 +252,10 @@ func (p *processor) downloadUsersFromChannels(ctx context.Context, jobID ref.UUI
 	return nil
 }
 
-func (p *processor) downloadTicketsFromChannels(ctx context.Context, jobID ref.UUID) error {
+func (p *processor) downloadTicketsFromChannels(ctx context.Context, jobID ref.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "download tickets", tracing.JobIDKey.String(jobID.String()))
+	defer func() { tracing.End(span, err) }()
+
 	p.logger.Infow("Starting tickets download", "time", time.Now().Format(time.RFC3339), "job", jobID)
 
 	j, err := p.jobRepository.GetJob(ctx, jobID)
@@ -248,9 +269,20 @@ func (p *processor) downloadTicketsFromChannels(ctx context.Context, jobID ref.U
 		p.logger.Errorw("Could not mark job as ticket download started", "error", err)
 	}
 
-	if err := p.ticketDownloader.DownloadTickets(ctx); err != nil {
+	mode, err := p.ticketDownloader.DownloadTickets(ctx, j.TicketsDownloadMode == job.TicketsDownloadFull)
+	if err != nil {
 		return err
 	}
+	j.TicketsDownloadMode = mode
+
+	if err := p.ticketDownloader.SaveSnapshot(ctx, jobID); err != nil {
+		return err
+	}
+
+	if warnings := p.ticketDownloader.Warnings(); len(warnings) > 0 {
+		p.logger.Warnw("Data-quality warnings recorded on the job", "job", jobID, "warnings", len(warnings))
+		j.Warnings = append(j.Warnings, warnings...)
+	}
 
 	j.TicketsDownloadFinishedAt.SetNow()
 
@@ -262,7 +294,10 @@ func (p *processor) downloadTicketsFromChannels(ctx context.Context, jobID ref.U
 	return nil
 }
 
-func (p *processor) generateExcelFiles(ctx context.Context, jobID ref.UUID) error {
+func (p *processor) generateExcelFiles(ctx context.Context, jobID ref.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "generate Excel files", tracing.JobIDKey.String(jobID.String()))
+	defer func() { tracing.End(span, err) }()
+
 	p.logger.Infow("Starting Excel files generation", "time", time.Now().Format(time.RFC3339), "job", jobID)
 
 	j, err := p.jobRepository.GetJob(ctx, jobID)
@@ -280,6 +315,11 @@ func (p *processor) generateExcelFiles(ctx context.Context, jobID ref.UUID) erro
 		if err := p.excelGenerator.GenerateExcelFilesForFieldEngineers(ctx); err != nil {
 			return err
 		}
+
+		if excluded := p.excelGenerator.ExcludedRecipients(); len(excluded) > 0 {
+			p.logger.Infow("Recipients excluded from the FE reports", "job", jobID, "recipients", len(excluded))
+			j.ExcludedRecipients = excluded
+		}
 	}
 
 	if p.isJobForSD(j) {
@@ -288,6 +328,12 @@ func (p *processor) generateExcelFiles(ctx context.Context, jobID ref.UUID) erro
 		}
 	}
 
+	if p.isJobForChannelOwners(j) {
+		if err := p.excelGenerator.GenerateExcelFilesForChannelOwners(ctx); err != nil {
+			return err
+		}
+	}
+
 	j.ExcelFilesGenerationFinishedAt.SetNow()
 
 	if _, err := p.jobRepository.UpdateJob(ctx, j); err != nil {
@@ -298,7 +344,10 @@ func (p *processor) generateExcelFiles(ctx context.Context, jobID ref.UUID) erro
 	return nil
 }
 
-func (p *processor) sendEmails(ctx context.Context, jobID ref.UUID) error {
+func (p *processor) sendEmails(ctx context.Context, jobID ref.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "send emails", tracing.JobIDKey.String(jobID.String()))
+	defer func() { tracing.End(span, err) }()
+
 	p.logger.Infow("Starting emails sending", "time", time.Now().Format(time.RFC3339), "job", jobID)
 
 	j, err := p.jobRepository.GetJob(ctx, jobID)
@@ -324,6 +373,12 @@ func (p *processor) sendEmails(ctx context.Context, jobID ref.UUID) error {
 		}
 	}
 
+	if p.isJobForChannelOwners(j) {
+		if err := p.emailSender.SendEmailsForChannelOwners(ctx); err != nil {
+			return err
+		}
+	}
+
 	j.EmailsSendingFinishedAt.SetNow()
 
 	if _, err := p.jobRepository.UpdateJob(ctx, j); err != nil {
@@ -347,6 +402,7 @@ func (p *processor) markJobAsFailed(ctx context.Context, jobID ref.UUID, jobErr
 	}
 
 	j.FinalStatus = fmt.Sprintf("Error: %s", jobErr)
+	tracing.Fail(trace.SpanFromContext(ctx), jobErr)
 
 	if _, err := p.jobRepository.UpdateJob(ctx, j); err != nil {
 		p.logger.Errorw("Could not mark job as failed", "error", err)
@@ -362,7 +418,7 @@ func (p *processor) markJobAsFinished(ctx context.Context, jobID ref.UUID) {
 		p.logger.Errorw("Could not mark job as finished", "error", err)
 	}
 
-	j.FinalStatus = "Success"
+	j.FinalStatus = job.StatusSuccess
 
 	if _, err := p.jobRepository.UpdateJob(ctx, j); err != nil {
 		p.logger.Errorw("Could not mark job as finished", "error", err)
@@ -378,3 +434,7 @@ func (p *processor) isJobForFE(j job.Job) bool {
 func (p *processor) isJobForSD(j job.Job) bool {
 	return j.Type == job.TypeSD || j.Type == job.TypeAll
 }
+
+func (p *processor) isJobForChannelOwners(j job.Job) bool {
+	return j.Type == job.TypeChannel || j.Type == job.TypeAll
+}
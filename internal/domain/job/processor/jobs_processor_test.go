@@ -3,6 +3,7 @@ package jobprocessor
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	chandownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/channel/downloader"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/excel"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/locale"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/recipient"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
 	ticketdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/ticket/downloader"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/user"
@@ -50,10 +54,12 @@ func Test_processor_ProcessNewJob(t *testing.T) {
 
 		ticketDownloader := new(mocks.TicketDownloaderMock)
 		ticketDownloader.On("DownloadTickets").Return(nil).Once()
+		ticketDownloader.On("SaveSnapshot", lastJob.UUID()).Return(nil).Once()
 
 		excelGen := new(mocks.ExcelGeneratorMock)
 		excelGen.On("GenerateExcelFilesForFieldEngineers").Return(nil).Once()
 		excelGen.On("GenerateExcelFilesForServiceDesk").Return(nil).Once()
+		excelGen.On("GenerateExcelFilesForChannelOwners").Return(nil).Once()
 
 		emailSender := new(mocks.EmailSenderMock)
 		emailSender.On("SendEmailsForFieldEngineers").Return(nil).Once()
@@ -62,6 +68,9 @@ func Test_processor_ProcessNewJob(t *testing.T) {
 		emailSender.On("SendEmailsForServiceDesk").Return(nil).Once()
 		emailSender.Wg.Add(1)
 
+		emailSender.On("SendEmailsForChannelOwners").Return(nil).Once()
+		emailSender.Wg.Add(1)
+
 		jp := NewJobProcessor(logger, jobsRepo, channelDownloader, userDownloader, ticketDownloader, excelGen, emailSender)
 		jp.WaitForJobs()
 
@@ -101,10 +110,13 @@ func Test_processor_ProcessNewJob(t *testing.T) {
 
 		ticketDownloader := new(mocks.TicketDownloaderMock)
 		ticketDownloader.On("DownloadTickets").Return(nil).Twice()
+		ticketDownloader.On("SaveSnapshot", lastJob.UUID()).Return(nil).Once()
+		ticketDownloader.On("SaveSnapshot", lastJob2.UUID()).Return(nil).Once()
 
 		excelGen := new(mocks.ExcelGeneratorMock)
 		excelGen.On("GenerateExcelFilesForFieldEngineers").Return(nil).Twice()
 		excelGen.On("GenerateExcelFilesForServiceDesk").Return(nil).Twice()
+		excelGen.On("GenerateExcelFilesForChannelOwners").Return(nil).Twice()
 
 		emailSender := new(mocks.EmailSenderMock)
 		emailSender.On("SendEmailsForFieldEngineers").Return(nil).Twice()
@@ -113,6 +125,9 @@ func Test_processor_ProcessNewJob(t *testing.T) {
 		emailSender.On("SendEmailsForServiceDesk").Return(nil).Twice()
 		emailSender.Wg.Add(2)
 
+		emailSender.On("SendEmailsForChannelOwners").Return(nil).Twice()
+		emailSender.Wg.Add(2)
+
 		jp := NewJobProcessor(
 			logger,
 			jobsRepo,
@@ -160,10 +175,12 @@ func Test_processor_DataProcessing(t *testing.T) {
 	var userDownloader userdownloader.UserDownloader
 	var ticketDownloader ticketdownloader.TicketDownloader
 
+	var channelRepository repository.ChannelRepository
 	var ticketRepository repository.TicketRepository
 
 	email1 := "first@user.com"
 	email2 := "second@user.com"
+	ownerEmail := "owner@user.com"
 
 	// this func prepares data and sets expectations on repository mocks
 	initTestDataForRepositories := func() {
@@ -175,8 +192,11 @@ func Test_processor_DataProcessing(t *testing.T) {
 			ChannelID: "8b6353c3-46ca-485d-87c3-66bc36c70d88",
 			Name:      "Second channel",
 		}
+		// the owner of the first channel comes from the channel configuration, not from ITSM
+		ch1FromITSM := ch1
+		ch1.OwnerEmails = []string{ownerEmail}
 		channelList = channel.List{
-			ch1,
+			ch1FromITSM,
 			ch2,
 		}
 
@@ -296,20 +316,39 @@ func Test_processor_DataProcessing(t *testing.T) {
 		userClient.On("GetUsers", ch1).Return(userListChan1, nil).Once()
 		userClient.On("GetUsers", ch2).Return(userListChan2, nil).Once()
 
+		incidentType := ticket.RecordType{Name: "incident", SortOrder: 0}
+		requestType := ticket.RecordType{Name: "k_request", SortOrder: 1}
+
 		ticketClient = new(mocks.TicketClientMock)
-		ticketClient.On("GetIncidents", ch1).Return(incListCh1, nil).Once()
-		ticketClient.On("GetIncidents", ch2).Return(incListCh2, nil).Once()
+		ticketClient.On("RecordTypes").Return([]ticket.RecordType{incidentType, requestType})
+		ticketClient.On("GetTickets", incidentType, ch1).Return(incListCh1, nil).Once()
+		ticketClient.On("GetTickets", incidentType, ch2).Return(incListCh2, nil).Once()
 
-		ticketClient.On("GetRequests", ch1).Return(reqListCh1, nil).Once()
-		ticketClient.On("GetRequests", ch2).Return(ticket.List{}, nil).Once()
+		ticketClient.On("GetTickets", requestType, ch1).Return(reqListCh1, nil).Once()
+		ticketClient.On("GetTickets", requestType, ch2).Return(ticket.List{}, nil).Once()
 		ticketClient.Wg.Add(4)
 
-		channelRepository := memory.NewChannelRepositoryMemory()
-		channelDownloader = chandownloader.NewChannelDownloader(channelRepository, channelClient)
+		channelRepository = memory.NewChannelRepositoryMemory()
+		channelConfigRepository := memory.NewChannelConfigRepositoryMemory()
+		_ = channelConfigRepository.SaveChannelConfig(context.Background(), channel.Config{
+			ChannelID:   ch1.ChannelID,
+			Enabled:     true,
+			OwnerEmails: ch1.OwnerEmails,
+		})
+		channelDownloader = chandownloader.NewChannelDownloader(
+			channelRepository, channelConfigRepository, channelClient, true,
+		)
 		userRepository := memory.NewUserRepositoryMemory()
-		userDownloader = userdownloader.NewUserDownloader(logger, channelRepository, userRepository, userClient)
+		userDownloader = userdownloader.NewUserDownloader(logger, mocks.NewFixedClock(), channelRepository, userRepository, userClient, 0)
 		ticketRepository = memory.NewTicketRepositoryMemory()
-		ticketDownloader = ticketdownloader.NewTicketDownloader(logger, channelRepository, userRepository, ticketRepository, ticketClient)
+		ticketDownloader = ticketdownloader.NewTicketDownloader(
+			logger, mocks.NewFixedClock(), channelRepository, userRepository, ticketRepository,
+			memory.NewTicketSnapshotRepositoryMemory(mocks.NewFixedClock(), 0),
+			memory.NewJobRepositoryMemory(mocks.NewFixedClock()),
+			ticketClient,
+			ticketdownloader.NewStaticStateClient(ticket.DefaultStateCatalogue()),
+			ticketdownloader.IncrementalConfig{},
+		)
 	}
 
 	t.Run("when the job type is 'FE report only'", func(t *testing.T) {
@@ -402,6 +441,51 @@ func Test_processor_DataProcessing(t *testing.T) {
 		emailSender.AssertExpectations(t)
 	})
 
+	t.Run("when the job type is 'Channel report only'", func(t *testing.T) {
+		initTestDataForRepositories()
+
+		lastJob := job.Job{Type: job.TypeChannel}
+		err := lastJob.SetUUID("d6aa467b-d07d-41e0-9182-aeedb1b02398")
+		require.NoError(t, err)
+
+		jobsRepo := new(mocks.JobRepositoryMock)
+		jobsRepo.On("GetLastJob").Return(lastJob, nil).Once()
+		jobsRepo.On("GetJob", lastJob.UUID()).Return(lastJob, nil)
+		jobsRepo.On("UpdateJob", mock.AnythingOfType("job.Job")).Return(lastJob.UUID(), nil)
+
+		// it should call only funcs for channel owners
+		excelGen := new(mocks.ExcelGeneratorMock)
+		excelGen.On("GenerateExcelFilesForChannelOwners").Return(nil).Once()
+
+		emailSender := new(mocks.EmailSenderMock)
+		emailSender.On("SendEmailsForChannelOwners").Return(nil).Once()
+		emailSender.Wg.Add(1)
+
+		jp := NewJobProcessor(
+			logger,
+			jobsRepo,
+			channelDownloader,
+			userDownloader,
+			ticketDownloader,
+			excelGen,
+			emailSender,
+		)
+		jp.WaitForJobs()
+
+		err = jp.ProcessNewJob(lastJob.UUID())
+		assert.NoError(t, err, "unexpected error", err)
+
+		ticketClient.Wg.Wait() // wait for job processor to finish
+		emailSender.Wg.Wait()  // wait for job processor to finish
+
+		jobsRepo.AssertExpectations(t)
+		channelClient.AssertExpectations(t)
+		userClient.AssertExpectations(t)
+		ticketClient.AssertExpectations(t)
+		excelGen.AssertExpectations(t)
+		emailSender.AssertExpectations(t)
+	})
+
 	t.Run("when the job type is 'all'", func(t *testing.T) {
 		initTestDataForRepositories()
 
@@ -413,12 +497,20 @@ func Test_processor_DataProcessing(t *testing.T) {
 		jobsRepo.On("GetLastJob").Return(lastJob, nil).Once()
 		jobsRepo.On("GetJob", lastJob.UUID()).Return(lastJob, nil)
 		jobsRepo.On("UpdateJob", mock.AnythingOfType("job.Job")).Return(lastJob.UUID(), nil)
+		jobsRepo.On("GetLastSuccessfulJob").Return(job.Job{}, repository.ErrNotFound)
+		jobsRepo.On("GetLastSuccessfulJobBefore", mock.AnythingOfType("time.Time")).Return(job.Job{}, repository.ErrNotFound)
 
-		// excelGen should call only funcs for both Field Engineers and Service Desk
+		// excelGen should call funcs for Field Engineers, Service Desk and channel owners
 		sdAgentEmails := []string{"firstSDAgent@email.test", "secondSDAgent@email.test", "thirdSDAgent@email.test"}
-		excelGen := excel.NewExcelGenerator(logger, ticketRepository, sdAgentEmails)
+		excelGen := excel.NewExcelGenerator(
+			logger, mocks.NewFixedClock(), channelRepository, ticketRepository, jobsRepo, memory.NewTicketSnapshotRepositoryMemory(mocks.NewFixedClock(), 0), sdAgentEmails, excel.DefaultLayouts(nil), nil, locale.Config{},
+			prefsvc.NewPreferencesService(
+				mocks.NewFixedClock(), memory.NewPreferencesRepositoryMemory(), memory.NewChannelRepositoryMemory(), nil, "", 0, "",
+			),
+			recipient.Filter{},
+		)
 
-		// emailSender should call only funcs for both Field Engineers and Service Desk
+		// emailSender should call funcs for Field Engineers, Service Desk and channel owners
 		emailSender := new(mocks.EmailSenderMock)
 		emailSender.On("SendEmailsForFieldEngineers").Return(nil)
 		emailSender.Wg.Add(1)
@@ -426,6 +518,9 @@ func Test_processor_DataProcessing(t *testing.T) {
 		emailSender.On("SendEmailsForServiceDesk").Return(nil)
 		emailSender.Wg.Add(1)
 
+		emailSender.On("SendEmailsForChannelOwners").Return(nil)
+		emailSender.Wg.Add(1)
+
 		jp := NewJobProcessor(
 			logger,
 			jobsRepo,
@@ -488,6 +583,13 @@ func Test_processor_DataProcessing(t *testing.T) {
 		assert.Equal(t, filesSD[1].Name(), sdAgentEmails[1]+".xlsx")
 		assert.Equal(t, filesSD[2].Name(), sdAgentEmails[2]+".xlsx")
 
+		// 3) files for channel owners
+		filesChannel, err := os.ReadDir(filepath.Join(excelGen.ChannelDirPath(), ch1.ChannelID))
+		require.NoError(t, err)
+
+		assert.Len(t, filesChannel, 1, "Excel files count for the channel == owner email addresses count")
+		assert.Equal(t, filesChannel[0].Name(), ownerEmail+".xlsx")
+
 		jobsRepo.AssertExpectations(t)
 		channelClient.AssertExpectations(t)
 		userClient.AssertExpectations(t)
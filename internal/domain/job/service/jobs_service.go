@@ -22,9 +22,15 @@ type jobService struct {
 }
 
 func (s jobService) CreateJob(ctx context.Context, params api.CreateJobParams) (ref.UUID, error) {
-	return s.repo.AddJob(ctx, job.Job{
+	j := job.Job{
 		Type: params.Type,
-	})
+	}
+
+	if params.FullResync {
+		j.TicketsDownloadMode = job.TicketsDownloadFull
+	}
+
+	return s.repo.AddJob(ctx, j)
 }
 
 func (s jobService) UpdateJob(ctx context.Context, j job.Job) (ref.UUID, error) {
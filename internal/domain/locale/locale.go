@@ -0,0 +1,248 @@
+package locale
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultDateFormat is used when no other date format is configured
+const DefaultDateFormat = "dd/mm/yyyy"
+
+// dateFormats contains date formats of the supported locales
+var dateFormats = map[string]string{
+	"cs-CZ": "d.m.yyyy",
+	"de-DE": "dd.mm.yyyy",
+	"en-GB": "dd/mm/yyyy",
+	"en-US": "mm/dd/yyyy",
+	"es-ES": "dd/mm/yyyy",
+	"fr-FR": "dd/mm/yyyy",
+	"hu-HU": "yyyy.mm.dd",
+	"pl-PL": "dd.mm.yyyy",
+	"sk-SK": "d.m.yyyy",
+	"iso":   "yyyy-mm-dd",
+}
+
+// Settings define how the dates are rendered in the reports
+type Settings struct {
+	// Location is the timezone the dates are converted to
+	Location *time.Location
+	// DateFormat is the date format in Excel notation, e.g. "dd/mm/yyyy"
+	DateFormat string
+}
+
+// DefaultSettings returns settings used when nothing else is configured (UTC, day/month/year)
+func DefaultSettings() Settings {
+	return Settings{
+		Location:   time.UTC,
+		DateFormat: DefaultDateFormat,
+	}
+}
+
+// ParseSettings returns date settings with the timezone name (e.g. "Europe/Prague") and the date format.
+// The date format is either a locale name (e.g. "de-DE") or a format in Excel notation using d, dd, m, mm, yy and yyyy
+// (e.g. "dd.mm.yyyy"). Empty values are taken from defaults.
+func ParseSettings(timezone, dateFormat string, defaults Settings) (Settings, error) {
+	s := defaults
+
+	if timezone = strings.TrimSpace(timezone); timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return s, fmt.Errorf("invalid timezone '%s': %v", timezone, err)
+		}
+		s.Location = location
+	}
+
+	if dateFormat = strings.TrimSpace(dateFormat); dateFormat != "" {
+		if localeFormat, ok := dateFormats[dateFormat]; ok {
+			dateFormat = localeFormat
+		}
+
+		if err := validateDateFormat(dateFormat); err != nil {
+			return s, err
+		}
+		s.DateFormat = dateFormat
+	}
+
+	return s, nil
+}
+
+// Date returns the date of t in the settings' timezone as midnight UTC, suitable for Excel date cells
+// (Excel dates have no timezone)
+func (s Settings) Date(t time.Time) time.Time {
+	year, month, day := t.In(s.location()).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// FormatDate returns the date of t in the settings' timezone formatted by the settings' date format
+func (s Settings) FormatDate(t time.Time) string {
+	return s.Date(t).Format(goLayout(s.dateFormat()))
+}
+
+// FormatExcelDate formats the date returned by Date (midnight UTC) without any timezone conversion
+func (s Settings) FormatExcelDate(t time.Time) string {
+	return t.Format(goLayout(s.dateFormat()))
+}
+
+// ExcelDateFormat returns the number format of the Excel date cells
+func (s Settings) ExcelDateFormat() string {
+	return s.dateFormat()
+}
+
+// WithTimezone returns the settings with the timezone name (e.g. "Europe/Prague") replacing the settings' timezone.
+// Empty or unknown timezone leaves the settings unchanged.
+func (s Settings) WithTimezone(timezone string) Settings {
+	if timezone == "" {
+		return s
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return s
+	}
+
+	s.Location = location
+	return s
+}
+
+func (s Settings) location() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
+}
+
+func (s Settings) dateFormat() string {
+	if s.DateFormat == "" {
+		return DefaultDateFormat
+	}
+	return s.DateFormat
+}
+
+// Config contains the default date settings with the overrides for the recipients
+type Config struct {
+	Default Settings
+	// Recipients contains settings keyed by lower case email address of the recipient
+	Recipients map[string]Settings
+}
+
+// ForRecipient returns date settings of the recipient with the email address
+func (c Config) ForRecipient(email string) Settings {
+	if s, ok := c.Recipients[strings.ToLower(email)]; ok {
+		return s
+	}
+
+	if c.Default.Location == nil && c.Default.DateFormat == "" {
+		return DefaultSettings()
+	}
+
+	return c.Default
+}
+
+// ParseRecipientSettings parses comma separated list of recipient overrides in the form "email=timezone[|format]",
+// e.g. "joe@test.com=America/New_York|en-US,jan@test.com=Europe/Prague". Missing values are taken from defaults.
+func ParseRecipientSettings(definition string, defaults Settings) (map[string]Settings, error) {
+	recipients := make(map[string]Settings)
+
+	for _, item := range strings.Split(definition, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid recipient date settings '%s', expected 'email=timezone[|format]'", item)
+		}
+
+		email := strings.ToLower(strings.TrimSpace(kv[0]))
+		if email == "" {
+			return nil, fmt.Errorf("invalid recipient date settings '%s', email must not be empty", item)
+		}
+		if _, ok := recipients[email]; ok {
+			return nil, fmt.Errorf("duplicate date settings for recipient '%s'", email)
+		}
+
+		values := strings.SplitN(kv[1], "|", 2)
+		var dateFormat string
+		if len(values) == 2 {
+			dateFormat = values[1]
+		}
+
+		s, err := ParseSettings(values[0], dateFormat, defaults)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date settings of recipient '%s': %v", email, err)
+		}
+
+		recipients[email] = s
+	}
+
+	return recipients, nil
+}
+
+// validateDateFormat checks that the date format contains day, month and year and nothing unsupported
+func validateDateFormat(format string) error {
+	tokens := dateFormatTokens(format)
+
+	var day, month, year bool
+	for _, t := range tokens {
+		switch t {
+		case "d", "dd":
+			day = true
+		case "m", "mm":
+			month = true
+		case "yy", "yyyy":
+			year = true
+		default:
+			if strings.ContainsAny(t, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") {
+				return fmt.Errorf("invalid date format '%s', unsupported part '%s'", format, t)
+			}
+		}
+	}
+
+	if !day || !month || !year {
+		return fmt.Errorf("invalid date format '%s', day, month and year are required", format)
+	}
+
+	return nil
+}
+
+// goLayout converts the date format in Excel notation to Go time layout
+func goLayout(format string) string {
+	layouts := map[string]string{
+		"d":    "2",
+		"dd":   "02",
+		"m":    "1",
+		"mm":   "01",
+		"yy":   "06",
+		"yyyy": "2006",
+	}
+
+	var layout strings.Builder
+	for _, t := range dateFormatTokens(format) {
+		if l, ok := layouts[t]; ok {
+			layout.WriteString(l)
+		} else {
+			layout.WriteString(t)
+		}
+	}
+
+	return layout.String()
+}
+
+// dateFormatTokens splits the date format to runs of the same letter and separators, "dd.mm.yyyy" => dd . mm . yyyy
+func dateFormatTokens(format string) []string {
+	var tokens []string
+
+	runes := []rune(format)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+
+	return tokens
+}
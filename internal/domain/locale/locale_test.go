@@ -0,0 +1,74 @@
+package locale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings_FormatDate(t *testing.T) {
+	createdAt := time.Date(2022, 3, 1, 23, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "01/03/2022", DefaultSettings().FormatDate(createdAt))
+
+	prague, err := ParseSettings("Europe/Prague", "cs-CZ", DefaultSettings())
+	require.NoError(t, err)
+	assert.Equal(t, "2.3.2022", prague.FormatDate(createdAt), "date in Prague is the next day")
+	assert.Equal(t, "d.m.yyyy", prague.ExcelDateFormat())
+	assert.Equal(t, time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC), prague.Date(createdAt))
+
+	custom, err := ParseSettings("", "yyyy-mm-dd", DefaultSettings())
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, custom.Location)
+	assert.Equal(t, "2022-03-01", custom.FormatDate(createdAt))
+
+	_, err = ParseSettings("Mars/Olympus", "", DefaultSettings())
+	assert.Error(t, err)
+
+	_, err = ParseSettings("", "dd/mm", DefaultSettings())
+	assert.Error(t, err, "year is missing")
+
+	_, err = ParseSettings("", "dd/mm/yyyy hh:nn", DefaultSettings())
+	assert.Error(t, err, "unsupported parts")
+}
+
+func TestConfig_ForRecipient(t *testing.T) {
+	defaults, err := ParseSettings("Europe/London", "en-GB", DefaultSettings())
+	require.NoError(t, err)
+
+	recipients, err := ParseRecipientSettings("Joe@test.com=America/New_York|en-US, jan@test.com=Europe/Prague", defaults)
+	require.NoError(t, err)
+
+	c := Config{Default: defaults, Recipients: recipients}
+
+	joe := c.ForRecipient("joe@test.com")
+	assert.Equal(t, "America/New_York", joe.Location.String())
+	assert.Equal(t, "mm/dd/yyyy", joe.DateFormat)
+
+	jan := c.ForRecipient("jan@test.com")
+	assert.Equal(t, "Europe/Prague", jan.Location.String())
+	assert.Equal(t, "dd/mm/yyyy", jan.DateFormat, "format is taken from defaults")
+
+	assert.Equal(t, defaults, c.ForRecipient("other@test.com"))
+	assert.Equal(t, DefaultSettings(), Config{}.ForRecipient("other@test.com"))
+
+	_, err = ParseRecipientSettings("joe@test.com", defaults)
+	assert.Error(t, err)
+
+	_, err = ParseRecipientSettings("joe@test.com=UTC,JOE@test.com=UTC", defaults)
+	assert.Error(t, err)
+}
+
+func TestSettings_WithTimezone(t *testing.T) {
+	s, err := ParseSettings("Europe/London", "en-GB", DefaultSettings())
+	require.NoError(t, err)
+
+	prague := s.WithTimezone("Europe/Prague")
+	assert.Equal(t, "Europe/Prague", prague.Location.String())
+	assert.Equal(t, s.DateFormat, prague.DateFormat)
+
+	assert.Equal(t, s, s.WithTimezone(""))
+	assert.Equal(t, s, s.WithTimezone("Nowhere/Unknown"))
+}
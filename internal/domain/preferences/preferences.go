@@ -0,0 +1,148 @@
+package preferences
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+)
+
+// Frequency of the reports sent to the recipient
+type Frequency string
+
+// Supported report frequencies
+const (
+	FrequencyDaily  Frequency = "daily"
+	FrequencyWeekly Frequency = "weekly"
+)
+
+// DefaultLanguage is the language of the emails of recipients without preferences
+const DefaultLanguage = "en"
+
+// Languages contains supported languages of the emails
+var Languages = []string{"en", "cs", "de"}
+
+// Preferences of the recipient of the field engineer reports
+type Preferences struct {
+	// Email address of the recipient (lower case)
+	Email string
+	// Frequency of the reports
+	Frequency Frequency
+	// Weekday when the weekly report is sent
+	Weekday time.Weekday
+	// Channels contains IDs of the channels the recipient cares about, empty means all channels
+	Channels []string
+	// RecordTypes contains names of the record types of the tickets (e.g. "incident"), empty means all types
+	RecordTypes []string
+	// Language of the emails
+	Language string
+	// OptedOut recipients do not receive any reports
+	OptedOut bool
+}
+
+// Choices contain values the recipients can choose from on the preferences page
+type Choices struct {
+	Channels    channel.List
+	RecordTypes []ticket.RecordType
+	Languages   []string
+}
+
+// Default returns preferences of the recipient who has not set any: daily reports of all tickets in English
+func Default(email string) Preferences {
+	return Preferences{
+		Email:     strings.ToLower(email),
+		Frequency: FrequencyDaily,
+		Weekday:   time.Monday,
+		Language:  DefaultLanguage,
+	}
+}
+
+// Validate returns error if the preferences contain unsupported values
+func (p Preferences) Validate() error {
+	if p.Email == "" {
+		return fmt.Errorf("email must not be empty")
+	}
+
+	if p.Frequency != FrequencyDaily && p.Frequency != FrequencyWeekly {
+		return fmt.Errorf("frequency must be one of ['%s' '%s']", FrequencyDaily, FrequencyWeekly)
+	}
+
+	if p.Weekday < time.Sunday || p.Weekday > time.Saturday {
+		return fmt.Errorf("invalid weekday %d", p.Weekday)
+	}
+
+	if !IsSupportedLanguage(p.Language) {
+		return fmt.Errorf("language must be one of %v", Languages)
+	}
+
+	return nil
+}
+
+// IsDue returns true if the recipient should receive the report at the given time. The time should be in the timezone
+// of the recipient, so that weekly reports are sent on the right day.
+func (p Preferences) IsDue(now time.Time) bool {
+	if p.OptedOut {
+		return false
+	}
+
+	return p.Frequency != FrequencyWeekly || now.Weekday() == p.Weekday
+}
+
+// PreviousReportBefore returns the time before which the previous report of the recipient due at the time was
+// generated: the time itself for the daily reports, the end of the same weekday a week ago for the weekly ones
+func (p Preferences) PreviousReportBefore(now time.Time) time.Time {
+	if p.Frequency != FrequencyWeekly {
+		return now
+	}
+
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return dayStart.AddDate(0, 0, -6)
+}
+
+// Filter returns tickets from the channels and of the record types the recipient cares about
+func (p Preferences) Filter(list ticket.List) ticket.List {
+	if len(p.Channels) == 0 && len(p.RecordTypes) == 0 {
+		return list
+	}
+
+	var filtered ticket.List
+	for _, t := range list {
+		if len(p.Channels) > 0 && !contains(p.Channels, t.ChannelID) {
+			continue
+		}
+		if len(p.RecordTypes) > 0 && !contains(p.RecordTypes, t.RecordType) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+
+	return filtered
+}
+
+// Report returns tickets the recipient should receive in the report at the given time (in the timezone
+// of the recipient). ok is false if no report should be sent, i.e. it is not due or there are no tickets left.
+func (p Preferences) Report(now time.Time, list ticket.List) (tickets ticket.List, ok bool) {
+	if !p.IsDue(now) {
+		return nil, false
+	}
+
+	tickets = p.Filter(list)
+
+	return tickets, len(tickets) > 0
+}
+
+// IsSupportedLanguage returns true if the emails can be sent in the language
+func IsSupportedLanguage(language string) bool {
+	return contains(Languages, language)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package preferences
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferences_IsDue(t *testing.T) {
+	monday := time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	p := Default("Joe@Email.test")
+	assert.Equal(t, "joe@email.test", p.Email)
+	assert.True(t, p.IsDue(monday))
+	assert.True(t, p.IsDue(tuesday))
+
+	p.Frequency = FrequencyWeekly
+	assert.True(t, p.IsDue(monday))
+	assert.False(t, p.IsDue(tuesday))
+
+	p.Frequency = FrequencyDaily
+	p.OptedOut = true
+	assert.False(t, p.IsDue(monday))
+}
+
+func TestPreferences_Filter(t *testing.T) {
+	inc1 := ticket.Ticket{ChannelID: "ch1", TicketType: "Incident", RecordType: "incident", TicketData: ticket.Data{Number: "INC1"}}
+	req1 := ticket.Ticket{ChannelID: "ch1", TicketType: "Request", RecordType: "k_request", TicketData: ticket.Data{Number: "REQ1"}}
+	inc2 := ticket.Ticket{ChannelID: "ch2", TicketType: "Incident", RecordType: "incident", TicketData: ticket.Data{Number: "INC2"}}
+	list := ticket.List{inc1, req1, inc2}
+
+	p := Default("joe@email.test")
+	assert.Equal(t, list, p.Filter(list))
+
+	p.Channels = []string{"ch1"}
+	assert.Equal(t, ticket.List{inc1, req1}, p.Filter(list))
+
+	p.RecordTypes = []string{"incident"}
+	assert.Equal(t, ticket.List{inc1}, p.Filter(list))
+
+	p.Channels = nil
+	assert.Equal(t, ticket.List{inc1, inc2}, p.Filter(list))
+
+	// the record types are matched by name, renaming their display names does not change the filter
+	inc2.TicketType = "Incident ticket"
+	assert.Equal(t, ticket.List{inc1, inc2}, p.Filter(ticket.List{inc1, req1, inc2}))
+}
+
+func TestPreferences_Report(t *testing.T) {
+	monday := time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	inc1 := ticket.Ticket{ChannelID: "ch1", TicketType: "Incident", RecordType: "incident", TicketData: ticket.Data{Number: "INC1"}}
+	req1 := ticket.Ticket{ChannelID: "ch1", TicketType: "Request", RecordType: "k_request", TicketData: ticket.Data{Number: "REQ1"}}
+
+	p := Default("joe@email.test")
+	p.RecordTypes = []string{"k_request"}
+
+	tickets, ok := p.Report(monday, ticket.List{inc1, req1})
+	assert.True(t, ok)
+	assert.Equal(t, ticket.List{req1}, tickets)
+
+	_, ok = p.Report(monday, ticket.List{inc1})
+	assert.False(t, ok, "no tickets left after filtering")
+
+	p.Frequency = FrequencyWeekly
+	p.Weekday = time.Friday
+	_, ok = p.Report(monday, ticket.List{inc1, req1})
+	assert.False(t, ok, "weekly report is not due")
+}
+
+func TestPreferences_Validate(t *testing.T) {
+	p := Default("joe@email.test")
+	require.NoError(t, p.Validate())
+
+	invalid := p
+	invalid.Frequency = "hourly"
+	assert.Error(t, invalid.Validate())
+
+	invalid = p
+	invalid.Language = "xx"
+	assert.Error(t, invalid.Validate())
+
+	invalid = p
+	invalid.Weekday = 7
+	assert.Error(t, invalid.Validate())
+}
+
+func TestSigner(t *testing.T) {
+	s := NewSigner("secret", 0)
+	issuedAt := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	token := s.Token("Joe@Email.test", issuedAt)
+	assert.True(t, strings.HasPrefix(token, "v1."))
+
+	email, err := s.Verify(token, issuedAt.AddDate(5, 0, 0))
+	require.NoError(t, err, "token without max age does not expire")
+	assert.Equal(t, "joe@email.test", email)
+
+	_, err = NewSigner("other secret", 0).Verify(token, issuedAt)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	forged := NewSigner("other secret", 0).Token("jan@email.test", issuedAt)
+	_, err = s.Verify(forged, issuedAt)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = s.Verify("v2"+strings.TrimPrefix(token, "v1"), issuedAt)
+	assert.ErrorIs(t, err, ErrInvalidToken, "token of other version")
+
+	_, err = s.Verify("nonsense", issuedAt)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	expiring := NewSigner("secret", 30*24*time.Hour)
+	_, err = expiring.Verify(token, issuedAt.AddDate(0, 0, 30))
+	assert.NoError(t, err)
+	_, err = expiring.Verify(token, issuedAt.AddDate(0, 0, 31))
+	assert.ErrorIs(t, err, ErrInvalidToken, "expired token")
+}
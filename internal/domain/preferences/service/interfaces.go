@@ -0,0 +1,33 @@
+package prefsvc
+
+import (
+	"context"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+)
+
+// PreferencesService provides operations with the preferences of the report recipients
+type PreferencesService interface {
+	// ForRecipient returns preferences of the recipient, default preferences if the recipient has not set any
+	ForRecipient(ctx context.Context, email string) (preferences.Preferences, error)
+
+	// GetPreferences returns preferences of the recipient identified by the signed token
+	GetPreferences(ctx context.Context, token string) (preferences.Preferences, error)
+
+	// UpdatePreferences updates preferences of the recipient identified by the signed token
+	UpdatePreferences(ctx context.Context, token string, params api.UpdatePreferencesParams) (preferences.Preferences, error)
+
+	// Unsubscribe opts out the recipient identified by the signed token from all reports
+	Unsubscribe(ctx context.Context, token string) (preferences.Preferences, error)
+
+	// Choices returns channels and record types the recipients can choose from
+	Choices(ctx context.Context) (preferences.Choices, error)
+
+	// ManageURL returns signed link to the page where the recipient manages the preferences,
+	// empty string if the links are not configured
+	ManageURL(email string) string
+
+	// UnsubscribeURL returns signed link that opts out the recipient, empty string if the links are not configured
+	UnsubscribeURL(email string) string
+}
@@ -0,0 +1,160 @@
+package prefsvc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// PreferencesRoute is the route of the page where the recipient manages the preferences
+const PreferencesRoute = "/preferences"
+
+// NewPreferencesService creates the preferences service. Links to the preferences page are signed by linkSecret,
+// expire after linkMaxAge (0 = never) and start with baseURL (external address of the service); if linkSecret
+// is empty, no links are generated and the page is not available. recordTypes are the record types the recipients
+// can choose from.
+func NewPreferencesService(
+	clock repository.Clock,
+	preferencesRepository repository.PreferencesRepository,
+	channelRepository repository.ChannelRepository,
+	recordTypes []ticket.RecordType,
+	linkSecret string,
+	linkMaxAge time.Duration,
+	baseURL string,
+) PreferencesService {
+	s := &preferencesService{
+		clock:             clock,
+		repo:              preferencesRepository,
+		channelRepository: channelRepository,
+		recordTypes:       recordTypes,
+		baseURL:           strings.TrimSuffix(baseURL, "/"),
+	}
+
+	if linkSecret != "" {
+		signer := preferences.NewSigner(linkSecret, linkMaxAge)
+		s.signer = &signer
+	}
+
+	return s
+}
+
+type preferencesService struct {
+	clock             repository.Clock
+	repo              repository.PreferencesRepository
+	channelRepository repository.ChannelRepository
+	recordTypes       []ticket.RecordType
+	signer            *preferences.Signer // nil if the links are not configured
+	baseURL           string
+}
+
+func (s preferencesService) ForRecipient(ctx context.Context, email string) (preferences.Preferences, error) {
+	p, err := s.repo.GetPreferences(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return preferences.Default(email), nil
+	}
+	if err != nil {
+		return p, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get preferences of recipient '%s'", email)
+	}
+
+	return p, nil
+}
+
+func (s preferencesService) GetPreferences(ctx context.Context, token string) (preferences.Preferences, error) {
+	email, err := s.verify(token)
+	if err != nil {
+		return preferences.Preferences{}, err
+	}
+
+	return s.ForRecipient(ctx, email)
+}
+
+func (s preferencesService) UpdatePreferences(
+	ctx context.Context, token string, params api.UpdatePreferencesParams,
+) (preferences.Preferences, error) {
+	p, err := s.GetPreferences(ctx, token)
+	if err != nil {
+		return p, err
+	}
+
+	p.Frequency = preferences.Frequency(params.Frequency)
+	p.Weekday = time.Weekday(params.Weekday)
+	p.Channels = params.Channels
+	p.RecordTypes = params.RecordTypes
+	p.Language = params.Language
+	p.OptedOut = params.OptedOut
+
+	if err := p.Validate(); err != nil {
+		return p, domain.WrapErrorf(err, domain.ErrorCodeInvalidArgument, "invalid preferences")
+	}
+
+	if err := s.repo.SavePreferences(ctx, p); err != nil {
+		return p, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not save preferences of recipient '%s'", p.Email)
+	}
+
+	return p, nil
+}
+
+func (s preferencesService) Unsubscribe(ctx context.Context, token string) (preferences.Preferences, error) {
+	p, err := s.GetPreferences(ctx, token)
+	if err != nil {
+		return p, err
+	}
+
+	p.OptedOut = true
+
+	if err := s.repo.SavePreferences(ctx, p); err != nil {
+		return p, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not save preferences of recipient '%s'", p.Email)
+	}
+
+	return p, nil
+}
+
+func (s preferencesService) Choices(ctx context.Context) (preferences.Choices, error) {
+	channels, err := s.channelRepository.GetChannelList(ctx)
+	if err != nil {
+		return preferences.Choices{}, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get channels from repository")
+	}
+
+	return preferences.Choices{
+		Channels:    channels,
+		RecordTypes: s.recordTypes,
+		Languages:   preferences.Languages,
+	}, nil
+}
+
+func (s preferencesService) ManageURL(email string) string {
+	if s.signer == nil {
+		return ""
+	}
+
+	return s.baseURL + PreferencesRoute + "/" + s.signer.Token(email, s.clock.Now())
+}
+
+func (s preferencesService) UnsubscribeURL(email string) string {
+	if s.signer == nil {
+		return ""
+	}
+
+	return s.ManageURL(email) + "/unsubscribe"
+}
+
+// verify returns email address of the recipient identified by the token
+func (s preferencesService) verify(token string) (string, error) {
+	if s.signer == nil {
+		return "", domain.NewErrorf(domain.ErrorCodeNotFound, "preferences not found")
+	}
+
+	email, err := s.signer.Verify(token, s.clock.Now())
+	if err != nil {
+		return "", domain.WrapErrorf(err, domain.ErrorCodeNotFound, "preferences not found")
+	}
+
+	return email, nil
+}
@@ -0,0 +1,85 @@
+package preferences
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned when the token is malformed, its signature does not match, it is signed by other
+// version of the key or it has expired
+var ErrInvalidToken = errors.New("invalid token")
+
+// tokenVersion is the version of the token format and the signing key, tokens of other versions are rejected, so that
+// changing it (together with the secret) invalidates all issued links
+const tokenVersion = "v1"
+
+// Signer creates and verifies tokens identifying the recipient in the links to the preferences page.
+// Token is "<version>.<payload>.<signature>", the payload is the base64 encoded issued-at timestamp and email address
+// signed by HMAC-SHA256 together with the version. Tokens older than the max age are rejected; with zero max age
+// the token does not expire, so the links in the old emails keep working.
+type Signer struct {
+	secret []byte
+	maxAge time.Duration
+}
+
+// NewSigner returns signer using the secret key, accepting tokens not older than maxAge (0 = tokens do not expire)
+func NewSigner(secret string, maxAge time.Duration) Signer {
+	return Signer{secret: []byte(secret), maxAge: maxAge}
+}
+
+// Token returns signed token of the email address issued at the time
+func (s Signer) Token(email string, issuedAt time.Time) string {
+	payload := []byte(strconv.FormatInt(issuedAt.Unix(), 10) + ":" + strings.ToLower(email))
+	return tokenVersion + "." + base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+// Verify returns the email address from the token valid at the time, or ErrInvalidToken
+func (s Signer) Verify(token string, now time.Time) (string, error) {
+	parts := strings.SplitN(token, ".", 3)
+	if len(parts) != 3 || parts[0] != tokenVersion {
+		return "", ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	if !hmac.Equal(signature, s.sign(payload)) {
+		return "", ErrInvalidToken
+	}
+
+	fields := strings.SplitN(string(payload), ":", 2)
+	if len(fields) != 2 {
+		return "", ErrInvalidToken
+	}
+
+	issuedAt, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	if s.maxAge > 0 && now.Sub(time.Unix(issuedAt, 0)) > s.maxAge {
+		return "", ErrInvalidToken
+	}
+
+	return fields[1], nil
+}
+
+func (s Signer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(tokenVersion + "."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
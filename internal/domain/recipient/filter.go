@@ -0,0 +1,98 @@
+package recipient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter decides which field engineers receive the reports, by their user type and the domain of their email address.
+// Empty allow list allows everything, deny list takes precedence over allow list. Values are compared case-insensitively;
+// a domain matches its subdomains as well.
+type Filter struct {
+	AllowedUserTypes []string
+	DeniedUserTypes  []string
+	AllowedDomains   []string
+	DeniedDomains    []string
+}
+
+// ParseFilter returns filter with comma separated lists of user types and email domains
+func ParseFilter(allowedUserTypes, deniedUserTypes, allowedDomains, deniedDomains string) Filter {
+	return Filter{
+		AllowedUserTypes: parseList(allowedUserTypes),
+		DeniedUserTypes:  parseList(deniedUserTypes),
+		AllowedDomains:   parseList(strings.ReplaceAll(allowedDomains, "@", "")),
+		DeniedDomains:    parseList(strings.ReplaceAll(deniedDomains, "@", "")),
+	}
+}
+
+// Check returns false with the reason if the recipient with the email address and the user type must not receive
+// the reports
+func (f Filter) Check(email, userType string) (ok bool, reason string) {
+	userType = strings.ToLower(userType)
+
+	if contains(f.DeniedUserTypes, userType) {
+		return false, fmt.Sprintf("user type '%s' is denied", userType)
+	}
+
+	if len(f.AllowedUserTypes) > 0 && !contains(f.AllowedUserTypes, userType) {
+		if userType == "" {
+			return false, "unknown user type is not allowed"
+		}
+		return false, fmt.Sprintf("user type '%s' is not allowed", userType)
+	}
+
+	domain := emailDomain(email)
+
+	if matchesDomain(f.DeniedDomains, domain) {
+		return false, fmt.Sprintf("email domain '%s' is denied", domain)
+	}
+
+	if len(f.AllowedDomains) > 0 && !matchesDomain(f.AllowedDomains, domain) {
+		return false, fmt.Sprintf("email domain '%s' is not allowed", domain)
+	}
+
+	return true, ""
+}
+
+// Exclusion returns the entry of the excluded recipient shown in the job summary
+func Exclusion(email, reason string) string {
+	return email + ": " + reason
+}
+
+func emailDomain(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return ""
+	}
+
+	return strings.ToLower(email[i+1:])
+}
+
+func matchesDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func parseList(definition string) []string {
+	var list []string
+	for _, v := range strings.Split(definition, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,81 @@
+package recipient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Filter
+		email    string
+		userType string
+		ok       bool
+		reason   string
+	}{
+		{
+			name:     "empty filter allows everybody",
+			filter:   ParseFilter("", "", "", ""),
+			email:    "joe@email.test",
+			userType: "api",
+			ok:       true,
+		},
+		{
+			name:     "denied user type",
+			filter:   ParseFilter("", "API, service", "", ""),
+			email:    "joe@email.test",
+			userType: "Service",
+			reason:   "user type 'service' is denied",
+		},
+		{
+			name:     "allowed user type",
+			filter:   ParseFilter("engineer,employee", "", "", ""),
+			email:    "joe@email.test",
+			userType: "Engineer",
+			ok:       true,
+		},
+		{
+			name:     "user type not in the allow list",
+			filter:   ParseFilter("engineer", "", "", ""),
+			email:    "joe@email.test",
+			userType: "customer",
+			reason:   "user type 'customer' is not allowed",
+		},
+		{
+			name:   "unknown user type with the allow list",
+			filter: ParseFilter("engineer", "", "", ""),
+			email:  "joe@email.test",
+			reason: "unknown user type is not allowed",
+		},
+		{
+			name:     "denied domain matches subdomains",
+			filter:   ParseFilter("", "", "", "@customer.test"),
+			email:    "joe@eu.Customer.test",
+			userType: "engineer",
+			reason:   "email domain 'eu.customer.test' is denied",
+		},
+		{
+			name:     "domain not in the allow list",
+			filter:   ParseFilter("", "", "email.test", ""),
+			email:    "joe@notemail.test",
+			userType: "engineer",
+			reason:   "email domain 'notemail.test' is not allowed",
+		},
+		{
+			name:     "allowed domain",
+			filter:   ParseFilter("", "", "email.test", ""),
+			email:    "joe@email.test",
+			userType: "engineer",
+			ok:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := tt.filter.Check(tt.email, tt.userType)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+)
+
+// Mapping maps the records of the source to channels, users and tickets.
+// Nested values are addressed by paths with dot separated names, e.g. "assignee.id".
+type Mapping struct {
+	Channel ChannelMapping
+	User    UserMapping
+	// TicketChannelID is the path of the channel ID in the ticket records
+	TicketChannelID string
+	Ticket          ticket.FieldMapping
+	// URLTemplates define the links to the tickets in the UI of the ticketing tool
+	URLTemplates ticket.URLTemplates
+}
+
+// ChannelMapping maps the channel records
+type ChannelMapping struct {
+	ID   string
+	Name string
+}
+
+// UserMapping maps the user records
+type UserMapping struct {
+	ChannelID string
+	UUID      string
+	Name      string
+	Email     string
+	Type      string
+	OrgName   string
+}
+
+// DefaultChannelMapping returns mapping of the channel records used by default
+func DefaultChannelMapping() ChannelMapping {
+	return ChannelMapping{
+		ID:   "id",
+		Name: "name",
+	}
+}
+
+// DefaultUserMapping returns mapping of the user records used by default, the same fields as ITSM users
+// with the channel ID
+func DefaultUserMapping() UserMapping {
+	return UserMapping{
+		ChannelID: "channel_id",
+		UUID:      "uuid",
+		Name:      "full_name",
+		Email:     "email",
+		Type:      "type",
+		OrgName:   "org_display_name",
+	}
+}
+
+// DefaultTicketChannelID is the default path of the channel ID in the ticket records
+const DefaultTicketChannelID = "channel_id"
+
+// ParseChannelMapping returns default channel mapping modified by the mapping definition.
+// Definition is a comma separated list of "key=path" pairs with keys "id" and "name", e.g. "id=key,name=title".
+func ParseChannelMapping(definition string) (ChannelMapping, error) {
+	m := DefaultChannelMapping()
+
+	err := parsePaths(definition, map[string]*string{
+		"id":   &m.ID,
+		"name": &m.Name,
+	})
+
+	return m, err
+}
+
+// ParseUserMapping returns default user mapping modified by the mapping definition.
+// Definition is a comma separated list of "key=path" pairs with keys "channel_id", "uuid", "full_name", "email",
+// "type" and "org_name", e.g. "uuid=id,full_name=display_name,org_name=company.name".
+func ParseUserMapping(definition string) (UserMapping, error) {
+	m := DefaultUserMapping()
+
+	err := parsePaths(definition, map[string]*string{
+		"channel_id": &m.ChannelID,
+		"uuid":       &m.UUID,
+		"full_name":  &m.Name,
+		"email":      &m.Email,
+		"type":       &m.Type,
+		"org_name":   &m.OrgName,
+	})
+
+	return m, err
+}
+
+// parsePaths sets the paths of the keys from the comma separated list of "key=path" pairs
+func parsePaths(definition string, paths map[string]*string) error {
+	for _, item := range strings.Split(definition, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid field mapping '%s', expected 'key=path'", item)
+		}
+
+		key, path := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if path == "" {
+			return fmt.Errorf("invalid field mapping '%s', path is empty", item)
+		}
+
+		p, ok := paths[key]
+		if !ok {
+			return fmt.Errorf("invalid field mapping '%s', unknown key '%s'", item, key)
+		}
+		*p = path
+	}
+
+	return nil
+}
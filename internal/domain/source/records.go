@@ -0,0 +1,287 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+)
+
+// Record is the decoded record of the source
+type Record = map[string]interface{}
+
+// Entities of the records, the tickets are read by their record type name
+const (
+	entityChannels = "channels"
+	entityUsers    = "users"
+)
+
+// recordReader reads the records of the entity from the source
+type recordReader interface {
+	// ReadRecords returns the records of the entity. If channelID is not empty, only the records with the channel ID
+	// at channelPath are returned.
+	ReadRecords(ctx context.Context, entity, channelID, channelPath string) ([]Record, error)
+}
+
+// filterByChannel returns the records of the channel
+func filterByChannel(records []Record, channelID, channelPath string) []Record {
+	if channelID == "" {
+		return records
+	}
+
+	var filtered []Record
+	for _, r := range records {
+		if ticket.FieldValue(r, channelPath) == channelID {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered
+}
+
+// fileReader reads the records from the export files <entity>.json (array of objects) or <entity>.csv (header row
+// with the field paths) in the dir. The records of each file are cached until the file is modified, so that a job
+// reading the users and tickets channel by channel decodes each file only once.
+type fileReader struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]cachedFile // by file name
+}
+
+// cachedFile is the file with its decoded records
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	records []Record
+}
+
+func newFileReader(dir string) *fileReader {
+	return &fileReader{
+		dir:   dir,
+		files: make(map[string]cachedFile),
+	}
+}
+
+func (r *fileReader) ReadRecords(_ context.Context, entity, channelID, channelPath string) ([]Record, error) {
+	jsonFile := filepath.Join(r.dir, entity+".json")
+	csvFile := filepath.Join(r.dir, entity+".csv")
+
+	filename, decode := jsonFile, readJSON
+	info, err := os.Stat(jsonFile)
+	if err != nil || info.IsDir() {
+		filename, decode = csvFile, readCSV
+		if info, err = os.Stat(csvFile); err != nil || info.IsDir() {
+			return nil, domain.NewErrorf(domain.ErrorCodeNotFound, "no export file %s or %s", jsonFile, csvFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached, ok := r.files[filename]
+	if !ok || !cached.modTime.Equal(info.ModTime()) || cached.size != info.Size() {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read %s", filename)
+		}
+		defer func() { _ = f.Close() }()
+
+		records, err := decode(f)
+		if err != nil {
+			return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode %s", filename)
+		}
+
+		cached = cachedFile{modTime: info.ModTime(), size: info.Size(), records: records}
+		r.files[filename] = cached
+	}
+
+	return filterByChannel(cached.records, channelID, channelPath), nil
+}
+
+// readJSON returns the records of the JSON array of objects
+func readJSON(reader io.Reader) ([]Record, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	if err := decodeJSON(data, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// readCSV returns the rows of the CSV file as records, the header row contains the field paths.
+// Dot separated paths create nested objects, e.g. "assigned_to.uuid", empty cells are left out.
+func readCSV(reader io.Reader) ([]Record, error) {
+	r := csv.NewReader(reader)
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := make(Record)
+		for i, value := range row {
+			if value == "" || i >= len(header) {
+				continue
+			}
+			setPath(record, strings.TrimSpace(header[i]), value)
+		}
+		records = append(records, record)
+	}
+}
+
+// setPath sets the value addressed by dot separated path in the record
+func setPath(record Record, path string, value interface{}) {
+	names := strings.Split(path, ".")
+
+	obj := record
+	for _, name := range names[:len(names)-1] {
+		next, ok := obj[name].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			obj[name] = next
+		}
+		obj = next
+	}
+
+	obj[names[len(names)-1]] = value
+}
+
+// decodeJSON decodes the JSON keeping the numbers as json.Number
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// RESTConfig configures the REST source. The URLs may contain placeholders {channel} (channel ID) and, in the tickets
+// URL, {record_type}. If the URL contains no {channel} placeholder, the records of all channels are read
+// and filtered by the channel ID in the records.
+type RESTConfig struct {
+	ChannelsURL string
+	UsersURL    string
+	TicketsURL  string
+	// ResultPath is the dot separated path of the array of records in the responses, empty = the response is the array
+	ResultPath string
+}
+
+// restReader reads the records from the REST API by GET requests, the responses are not paginated
+type restReader struct {
+	cfg       RESTConfig
+	newClient func(url string) client.Client
+
+	mu      sync.Mutex
+	clients map[string]client.Client // by URL
+}
+
+func newRESTReader(cfg RESTConfig, newClient func(url string) client.Client) *restReader {
+	return &restReader{
+		cfg:       cfg,
+		newClient: newClient,
+		clients:   make(map[string]client.Client),
+	}
+}
+
+// client returns the client of the URL, the clients are created once per URL
+func (r *restReader) client(u string) client.Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.clients[u]
+	if !ok {
+		c = r.newClient(u)
+		r.clients[u] = c
+	}
+
+	return c
+}
+
+func (r *restReader) ReadRecords(ctx context.Context, entity, channelID, channelPath string) ([]Record, error) {
+	template := r.cfg.TicketsURL
+	switch entity {
+	case entityChannels:
+		template = r.cfg.ChannelsURL
+	case entityUsers:
+		template = r.cfg.UsersURL
+	}
+
+	u := strings.NewReplacer(
+		"{channel}", url.PathEscape(channelID),
+		"{record_type}", url.PathEscape(entity),
+	).Replace(template)
+
+	// the channel is in the URL, the channel header of the ITSM queries is not sent
+	resp, err := r.client(u).Get(ctx, "")
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get %s from %s", entity, u)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not read %s from %s", entity, u)
+	}
+
+	var payload interface{}
+	if err := decodeJSON(body, &payload); err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode %s from %s", entity, u)
+	}
+
+	if r.cfg.ResultPath != "" {
+		for _, name := range strings.Split(r.cfg.ResultPath, ".") {
+			obj, _ := payload.(map[string]interface{})
+			payload = obj[name]
+		}
+	}
+
+	// wrong result path would silently return no records
+	items, ok := payload.([]interface{})
+	if !ok {
+		return nil, domain.NewErrorf(domain.ErrorCodeUnknown, "%s returned no array of records at '%s'", u, r.cfg.ResultPath)
+	}
+
+	records := make([]Record, 0, len(items))
+	for _, item := range items {
+		if record, ok := item.(map[string]interface{}); ok {
+			records = append(records, record)
+		}
+	}
+
+	// the records of the channel are already selected by the URL
+	if strings.Contains(template, "{channel}") {
+		return records, nil
+	}
+
+	return filterByChannel(records, channelID, channelPath), nil
+}
@@ -0,0 +1,198 @@
+// Package source provides adapters reading channels, users and tickets from sources other than ITSM,
+// i.e. from export files and from generic REST APIs, mapped to the domain objects by field mappings.
+package source
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	chandownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/channel/downloader"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	ticketdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/ticket/downloader"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/user"
+	userdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/user/downloader"
+)
+
+// Kind selects the source of the channels, users and tickets
+type Kind string
+
+// Kind values
+const (
+	// KindITSM downloads the data from ITSM
+	KindITSM Kind = "itsm"
+	// KindFile reads the data from JSON or CSV export files
+	KindFile Kind = "file"
+	// KindREST downloads the data from a generic REST API
+	KindREST Kind = "rest"
+)
+
+// ParseKind returns the source kind of the name
+func ParseKind(name string) (Kind, error) {
+	switch k := Kind(name); k {
+	case KindITSM, KindFile, KindREST:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unknown source '%s', expected one of %s, %s, %s", name, KindITSM, KindFile, KindREST)
+	}
+}
+
+// Source bundles the clients of the channels, users and tickets of one source
+type Source struct {
+	ChannelClient chandownloader.ChannelClient
+	UserClient    userdownloader.UserClient
+	TicketClient  ticketdownloader.TicketClient
+}
+
+// NewFileSource returns source reading the export files in the dir: channels.json, users.json and <record_type>.json
+// with arrays of objects, or the same files with .csv extension and the field paths in the header row.
+// Each record of users and tickets contains ID of its channel.
+func NewFileSource(dir string, recordTypes []ticket.RecordType, mapping Mapping) Source {
+	return newSource(newFileReader(dir), recordTypes, mapping)
+}
+
+// NewRESTSource returns source downloading the records from the REST API by GET requests. newClient returns
+// the client of the URL with the placeholders replaced (authorization, retries, limits), it is called once per URL.
+func NewRESTSource(
+	cfg RESTConfig, recordTypes []ticket.RecordType, mapping Mapping, newClient func(url string) client.Client,
+) Source {
+	return newSource(newRESTReader(cfg, newClient), recordTypes, mapping)
+}
+
+func newSource(reader recordReader, recordTypes []ticket.RecordType, mapping Mapping) Source {
+	sorted := make([]ticket.RecordType, len(recordTypes))
+	copy(sorted, recordTypes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SortOrder < sorted[j].SortOrder
+	})
+
+	a := &adapter{
+		reader:      reader,
+		recordTypes: sorted,
+		mapping:     mapping,
+	}
+
+	return Source{
+		ChannelClient: a,
+		UserClient:    a,
+		TicketClient:  a,
+	}
+}
+
+// adapter maps the records of the reader to channels, users and tickets
+type adapter struct {
+	reader      recordReader
+	recordTypes []ticket.RecordType
+	mapping     Mapping
+}
+
+func (a adapter) GetChannels(ctx context.Context) (channel.List, error) {
+	var channelList channel.List
+
+	records, err := a.reader.ReadRecords(ctx, entityChannels, "", "")
+	if err != nil {
+		return channelList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about channels")
+	}
+
+	for _, r := range records {
+		channelList = append(channelList, channel.Channel{
+			ChannelID: ticket.FieldValue(r, a.mapping.Channel.ID),
+			Name:      ticket.FieldValue(r, a.mapping.Channel.Name),
+		})
+	}
+
+	return channelList, nil
+}
+
+func (a adapter) GetUsers(ctx context.Context, channel channel.Channel) (user.List, error) {
+	var userList user.List
+
+	m := a.mapping.User
+	records, err := a.reader.ReadRecords(ctx, entityUsers, channel.ChannelID, m.ChannelID)
+	if err != nil {
+		return userList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about users")
+	}
+
+	for _, r := range records {
+		userList = append(userList, user.User{
+			ChannelID: channel.ChannelID,
+			UserID:    ticket.FieldValue(r, m.UUID),
+			Email:     ticket.FieldValue(r, m.Email),
+			Name:      ticket.FieldValue(r, m.Name),
+			Type:      ticket.FieldValue(r, m.Type),
+			OrgName:   ticket.FieldValue(r, m.OrgName),
+		})
+	}
+
+	return userList, nil
+}
+
+func (a adapter) RecordTypes() []ticket.RecordType {
+	return a.recordTypes
+}
+
+func (a adapter) GetTickets(
+	ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel,
+) (ticket.List, error) {
+	return a.getTickets(ctx, recordType, states, channel, func(t ticket.Ticket, _ map[string]interface{}) bool {
+		return states.IsOpen(t.TicketData.StateID)
+	})
+}
+
+func (a adapter) GetUpdatedTickets(
+	ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel, since time.Time,
+) (ticket.List, error) {
+	return a.getTickets(ctx, recordType, states, channel, func(_ ticket.Ticket, record map[string]interface{}) bool {
+		updatedAt, err := time.Parse(time.RFC3339, ticket.FieldValue(record, a.mapping.Ticket.UpdatedAt))
+		// records with unknown time of the update are always treated as updated
+		return err != nil || !updatedAt.Before(since)
+	})
+}
+
+// getTickets returns the tickets of the record type in the channel selected by the filter
+func (a adapter) getTickets(
+	ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel,
+	filter func(t ticket.Ticket, record map[string]interface{}) bool,
+) (ticket.List, error) {
+	var ticketList ticket.List
+
+	if !a.hasRecordType(recordType) {
+		return ticketList, domain.NewErrorf(domain.ErrorCodeUnknown, "no client configured for record type '%s'", recordType.Name)
+	}
+
+	records, err := a.reader.ReadRecords(ctx, recordType.Name, channel.ChannelID, a.mapping.TicketChannelID)
+	if err != nil {
+		return ticketList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about %s records", recordType.Name)
+	}
+
+	for _, r := range records {
+		t, err := a.mapping.Ticket.NewTicket(r, recordType, states, channel.ChannelID, channel.Name, a.mapping.URLTemplates)
+		if err != nil {
+			return ticketList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode %s records", recordType.Name)
+		}
+
+		if filter(t, r) {
+			ticketList = append(ticketList, t)
+		}
+	}
+
+	return ticketList, nil
+}
+
+func (a adapter) hasRecordType(recordType ticket.RecordType) bool {
+	for _, rt := range a.recordTypes {
+		if rt.Name == recordType.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *adapter) Close() error {
+	return nil
+}
@@ -0,0 +1,200 @@
+package source
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/user"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMappings(t *testing.T) {
+	cm, err := ParseChannelMapping("id=key, name=title")
+	require.NoError(t, err)
+	assert.Equal(t, ChannelMapping{ID: "key", Name: "title"}, cm)
+
+	um, err := ParseUserMapping("uuid=id,org_name=company.name")
+	require.NoError(t, err)
+	assert.Equal(t, "id", um.UUID)
+	assert.Equal(t, "company.name", um.OrgName)
+	assert.Equal(t, "full_name", um.Name)
+
+	_, err = ParseUserMapping("manager=manager.id")
+	assert.Error(t, err)
+
+	_, err = ParseChannelMapping("id")
+	assert.Error(t, err)
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "channels.json", `[{"id":"ch1","name":"First"},{"id":"ch2","name":"Second"}]`)
+	writeFile(t, dir, "users.csv", "channel_id,uuid,full_name,email,type\n"+
+		"ch1,u1,Alice,alice@test.com,engineer\n"+
+		"ch2,u2,Bob,bob@test.com,engineer\n")
+	writeFile(t, dir, "incident.csv", "channel_id,uuid,number,assigned_to.uuid,state_id,location.full_location,updated_at\n"+
+		"ch1,t1,INC1,u1,2,Praha,2022-03-01T10:00:00Z\n"+
+		"ch1,t2,INC2,u1,4,Praha,2022-03-05T10:00:00Z\n"+
+		"ch2,t3,INC3,u2,2,Brno,2022-03-05T10:00:00Z\n")
+
+	states, err := ticket.ParseStateModel("2=Assigned,4=Resolved:closed")
+	require.NoError(t, err)
+
+	recordTypes := []ticket.RecordType{{Name: "incident", DisplayName: "Incident"}}
+	src := NewFileSource(dir, recordTypes, Mapping{
+		Channel:         DefaultChannelMapping(),
+		User:            DefaultUserMapping(),
+		TicketChannelID: DefaultTicketChannelID,
+		Ticket:          ticket.DefaultFieldMapping(),
+	})
+
+	channels, err := src.ChannelClient.GetChannels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, channel.List{{ChannelID: "ch1", Name: "First"}, {ChannelID: "ch2", Name: "Second"}}, channels)
+
+	users, err := src.UserClient.GetUsers(context.Background(), channels[0])
+	require.NoError(t, err)
+	assert.Equal(t, user.List{
+		{ChannelID: "ch1", UserID: "u1", Email: "alice@test.com", Name: "Alice", Type: "engineer"},
+	}, users)
+
+	assert.Equal(t, recordTypes, src.TicketClient.RecordTypes())
+
+	tickets, err := src.TicketClient.GetTickets(context.Background(), recordTypes[0], states, channels[0])
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	assert.Equal(t, "INC1", tickets[0].TicketData.Number)
+	assert.Equal(t, "u1", tickets[0].UserID)
+	assert.Equal(t, "Assigned", tickets[0].TicketData.State)
+	assert.Equal(t, "Praha", tickets[0].TicketData.Location)
+	assert.Equal(t, "Incident", tickets[0].TicketType)
+	assert.Equal(t, "First", tickets[0].ChannelName)
+
+	since := time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC)
+	updated, err := src.TicketClient.GetUpdatedTickets(context.Background(), recordTypes[0], states, channels[0], since)
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Equal(t, "INC2", updated[0].TicketData.Number)
+
+	_, err = src.TicketClient.GetTickets(context.Background(), ticket.RecordType{Name: "problem"}, states, channels[0])
+	assert.Error(t, err)
+
+	// modified file is read again
+	writeFile(t, dir, "channels.json", `[{"id":"ch1","name":"First renamed"}]`)
+	channels, err = src.ChannelClient.GetChannels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, channel.List{{ChannelID: "ch1", Name: "First renamed"}}, channels)
+}
+
+func TestFileSource_MissingFile(t *testing.T) {
+	src := NewFileSource(t.TempDir(), nil, Mapping{Channel: DefaultChannelMapping()})
+
+	_, err := src.ChannelClient.GetChannels(context.Background())
+	assert.Error(t, err)
+}
+
+func TestRESTSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/projects":
+			_, _ = w.Write([]byte(`{"data":{"items":[{"key":"P1","title":"Project 1"}]}}`))
+		case "/users":
+			_, _ = w.Write([]byte(`{"data":{"items":[
+				{"project":"P1","id":"u1","display_name":"Alice","email":"alice@test.com"},
+				{"project":"P2","id":"u2","display_name":"Bob","email":"bob@test.com"}
+			]}}`))
+		case "/projects/P1/issue":
+			_, _ = w.Write([]byte(`{"data":{"items":[
+				{"id":"t1","key":"ISS-1","assignee":{"id":"u1"},"status":1,"summary":"Broken printer"}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	channelMapping, err := ParseChannelMapping("id=key,name=title")
+	require.NoError(t, err)
+	userMapping, err := ParseUserMapping("channel_id=project,uuid=id,full_name=display_name")
+	require.NoError(t, err)
+	fieldMapping, err := ticket.ParseFieldMapping("uuid=id,number=key,assigned_to=assignee.id,state_id=status,short_description=summary")
+	require.NoError(t, err)
+
+	logger, _ := testutils.NewTestLogger()
+	var urls []string
+	newClient := func(url string) client.Client {
+		urls = append(urls, url)
+		c := client.NewHTTPClient(url, logger, staticToken("Bearer token"))
+		c.Client = ts.Client()
+		return c
+	}
+
+	recordTypes := []ticket.RecordType{{Name: "issue", DisplayName: "Issue"}}
+	src := NewRESTSource(RESTConfig{
+		ChannelsURL: ts.URL + "/projects",
+		UsersURL:    ts.URL + "/users",
+		TicketsURL:  ts.URL + "/projects/{channel}/{record_type}",
+		ResultPath:  "data.items",
+	}, recordTypes, Mapping{
+		Channel: channelMapping,
+		User:    userMapping,
+		Ticket:  fieldMapping,
+	}, newClient)
+
+	channels, err := src.ChannelClient.GetChannels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, channel.List{{ChannelID: "P1", Name: "Project 1"}}, channels)
+
+	users, err := src.UserClient.GetUsers(context.Background(), channels[0])
+	require.NoError(t, err)
+	assert.Equal(t, user.List{{ChannelID: "P1", UserID: "u1", Email: "alice@test.com", Name: "Alice"}}, users)
+
+	tickets, err := src.TicketClient.GetTickets(context.Background(), recordTypes[0], ticket.DefaultStateModel(), channels[0])
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	assert.Equal(t, "ISS-1", tickets[0].TicketData.Number)
+	assert.Equal(t, "Broken printer", tickets[0].TicketData.ShortDescription)
+	assert.Equal(t, "u1", tickets[0].UserID)
+	assert.Equal(t, 1, tickets[0].TicketData.StateID)
+
+	_, err = src.TicketClient.GetTickets(context.Background(), recordTypes[0], ticket.DefaultStateModel(), channel.Channel{ChannelID: "P9"})
+	assert.Error(t, err)
+
+	_, err = src.UserClient.GetUsers(context.Background(), channels[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		ts.URL + "/projects", ts.URL + "/users", ts.URL + "/projects/P1/issue", ts.URL + "/projects/P9/issue",
+	}, urls, "one client per URL")
+
+	// wrong result path is an error, not an empty list
+	wrongPath := NewRESTSource(RESTConfig{ChannelsURL: ts.URL + "/projects", ResultPath: "data.records"},
+		recordTypes, Mapping{Channel: channelMapping}, newClient)
+	_, err = wrongPath.ChannelClient.GetChannels(context.Background())
+	assert.Error(t, err)
+}
+
+type staticToken string
+
+func (s staticToken) GetToken() (string, error) {
+	return string(s), nil
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+}
@@ -0,0 +1,131 @@
+package ticket
+
+import (
+	"sort"
+)
+
+// ChangeType describes how the ticket changed since the previous report
+type ChangeType string
+
+// ChangeType values
+const (
+	ChangeNew            ChangeType = "New"
+	ChangeReassignedToMe ChangeType = "Reassigned to me"
+	ChangeReassigned     ChangeType = "Reassigned"
+	ChangeStateChanged   ChangeType = "State changed"
+	ChangeGone           ChangeType = "Gone"
+)
+
+// changeTypeOrder defines the order of changes in the reports
+var changeTypeOrder = map[ChangeType]int{
+	ChangeNew:            0,
+	ChangeReassignedToMe: 1,
+	ChangeReassigned:     2,
+	ChangeStateChanged:   3,
+	ChangeGone:           4,
+}
+
+// Change of the ticket since the previous report
+type Change struct {
+	Type ChangeType
+	// Ticket is the current ticket, or the ticket from the previous report if the ticket is gone
+	Ticket Ticket
+	// Details describe the change in human-readable form, e.g. "New => In progress"
+	Details string
+}
+
+// Key identifies the ticket across the reports. The record type name is used rather than the display name, which can
+// change between the reports; tickets without the record type name fall back to the display name.
+func (t Ticket) Key() string {
+	recordType := t.RecordType
+	if recordType == "" {
+		recordType = t.TicketType
+	}
+	return t.ChannelID + "/" + recordType + "/" + t.TicketData.Number
+}
+
+// CompareTickets returns changes between the previous and the current list of all tickets from the point of view
+// of the recipient with the specified email address. If userEmail is empty, all tickets are compared.
+func CompareTickets(previous, current List, userEmail string) []Change {
+	previousByKey := indexByKey(previous)
+	currentByKey := indexByKey(current)
+
+	isRelevant := func(t Ticket) bool {
+		return userEmail == "" || t.UserEmail == userEmail
+	}
+
+	var changes []Change
+
+	for _, cur := range current {
+		if !isRelevant(cur) {
+			continue
+		}
+
+		prev, existed := previousByKey[cur.Key()]
+		switch {
+		case !existed:
+			changes = append(changes, Change{Type: ChangeNew, Ticket: cur})
+
+		case prev.UserEmail != cur.UserEmail:
+			changeType := ChangeReassigned
+			if userEmail != "" {
+				changeType = ChangeReassignedToMe
+			}
+			changes = append(changes, Change{Type: changeType, Ticket: cur, Details: "From " + assigneeName(prev)})
+
+		case prev.TicketData.StateID != cur.TicketData.StateID:
+			changes = append(changes, Change{
+				Type:    ChangeStateChanged,
+				Ticket:  cur,
+				Details: prev.TicketData.StateName() + " => " + cur.TicketData.StateName(),
+			})
+		}
+	}
+
+	for _, prev := range previous {
+		if !isRelevant(prev) {
+			continue
+		}
+
+		cur, exists := currentByKey[prev.Key()]
+		switch {
+		case !exists:
+			changes = append(changes, Change{Type: ChangeGone, Ticket: prev, Details: "Resolved or closed"})
+
+		case userEmail != "" && cur.UserEmail != userEmail:
+			changes = append(changes, Change{Type: ChangeGone, Ticket: prev, Details: "Reassigned to " + assigneeName(cur)})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Type != changes[j].Type {
+			return changeTypeOrder[changes[i].Type] < changeTypeOrder[changes[j].Type]
+		}
+		if changes[i].Ticket.TicketTypeOrder != changes[j].Ticket.TicketTypeOrder {
+			return changes[i].Ticket.TicketTypeOrder < changes[j].Ticket.TicketTypeOrder
+		}
+		return changes[i].Ticket.TicketData.Number < changes[j].Ticket.TicketData.Number
+	})
+
+	return changes
+}
+
+func indexByKey(list List) map[string]Ticket {
+	m := make(map[string]Ticket, len(list))
+	for _, t := range list {
+		m[t.Key()] = t
+	}
+
+	return m
+}
+
+func assigneeName(t Ticket) string {
+	switch {
+	case t.UserName != "":
+		return t.UserName
+	case t.UserEmail != "":
+		return t.UserEmail
+	default:
+		return "(unassigned)"
+	}
+}
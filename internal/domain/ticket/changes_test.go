@@ -0,0 +1,104 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareTickets(t *testing.T) {
+	newTicket := func(number, email, name string, stateID int) Ticket {
+		return Ticket{
+			UserEmail:  email,
+			UserName:   name,
+			ChannelID:  "ch1",
+			TicketType: "Incident",
+			TicketData: Data{Number: number, StateID: stateID},
+		}
+	}
+
+	previous := List{
+		newTicket("INC1", "me@email.test", "Me", 0),       // unchanged
+		newTicket("INC2", "me@email.test", "Me", 0),       // state changed
+		newTicket("INC3", "other@email.test", "Other", 0), // reassigned to me
+		newTicket("INC4", "me@email.test", "Me", 2),       // resolved
+		newTicket("INC5", "me@email.test", "Me", 2),       // reassigned away
+		newTicket("INC6", "other@email.test", "Other", 0), // resolved, not mine
+		newTicket("INC7", "other@email.test", "Other", 0), // reassigned to third person
+	}
+
+	current := List{
+		newTicket("INC1", "me@email.test", "Me", 0),
+		newTicket("INC2", "me@email.test", "Me", 2),
+		newTicket("INC3", "me@email.test", "Me", 0),
+		newTicket("INC5", "other@email.test", "Other", 2),
+		newTicket("INC7", "third@email.test", "Third", 0),
+		newTicket("INC8", "me@email.test", "Me", 0), // new
+	}
+
+	t.Run("for the recipient", func(t *testing.T) {
+		changes := CompareTickets(previous, current, "me@email.test")
+		require.Len(t, changes, 5)
+
+		assert.Equal(t, ChangeNew, changes[0].Type)
+		assert.Equal(t, "INC8", changes[0].Ticket.TicketData.Number)
+
+		assert.Equal(t, ChangeReassignedToMe, changes[1].Type)
+		assert.Equal(t, "INC3", changes[1].Ticket.TicketData.Number)
+		assert.Equal(t, "From Other", changes[1].Details)
+
+		assert.Equal(t, ChangeStateChanged, changes[2].Type)
+		assert.Equal(t, "INC2", changes[2].Ticket.TicketData.Number)
+		assert.Equal(t, "New => In progress", changes[2].Details)
+
+		assert.Equal(t, ChangeGone, changes[3].Type)
+		assert.Equal(t, "INC4", changes[3].Ticket.TicketData.Number)
+		assert.Equal(t, "Resolved or closed", changes[3].Details)
+
+		assert.Equal(t, ChangeGone, changes[4].Type)
+		assert.Equal(t, "INC5", changes[4].Ticket.TicketData.Number)
+		assert.Equal(t, "Reassigned to Other", changes[4].Details)
+	})
+
+	t.Run("for all tickets", func(t *testing.T) {
+		changes := CompareTickets(previous, current, "")
+		require.Len(t, changes, 7)
+
+		var types []ChangeType
+		var numbers []string
+		for _, c := range changes {
+			types = append(types, c.Type)
+			numbers = append(numbers, c.Ticket.TicketData.Number)
+		}
+
+		assert.Equal(t, []ChangeType{
+			ChangeNew, ChangeReassigned, ChangeReassigned, ChangeReassigned, ChangeStateChanged, ChangeGone, ChangeGone,
+		}, types)
+		assert.Equal(t, []string{"INC8", "INC3", "INC5", "INC7", "INC2", "INC4", "INC6"}, numbers)
+	})
+
+	t.Run("with renamed record type", func(t *testing.T) {
+		var renamedPrevious, renamedCurrent List
+		for _, t := range previous {
+			t.RecordType = "incident"
+			renamedPrevious = append(renamedPrevious, t)
+		}
+		for _, t := range current {
+			t.RecordType = "incident"
+			t.TicketType = "Incidents"
+			renamedCurrent = append(renamedCurrent, t)
+		}
+
+		changes := CompareTickets(renamedPrevious, renamedCurrent, "me@email.test")
+		assert.Len(t, changes, 5, "tickets are matched by the record type name, not by the display name")
+	})
+
+	t.Run("without previous tickets", func(t *testing.T) {
+		changes := CompareTickets(nil, current, "me@email.test")
+		require.Len(t, changes, 4)
+		for _, c := range changes {
+			assert.Equal(t, ChangeNew, c.Type)
+		}
+	})
+}
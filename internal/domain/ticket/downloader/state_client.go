@@ -0,0 +1,90 @@
+package ticketdownloader
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+)
+
+// StateClient gets catalogue of ticket states
+type StateClient interface {
+	// GetStateCatalogue returns state models of the record types
+	GetStateCatalogue(ctx context.Context) (ticket.StateCatalogue, error)
+
+	// Close closes client connections
+	Close() error
+}
+
+// NewStaticStateClient returns state client that always returns the configured catalogue
+func NewStaticStateClient(catalogue ticket.StateCatalogue) StateClient {
+	return &staticStateClient{
+		catalogue: catalogue,
+	}
+}
+
+type staticStateClient struct {
+	catalogue ticket.StateCatalogue
+}
+
+func (c staticStateClient) GetStateCatalogue(_ context.Context) (ticket.StateCatalogue, error) {
+	return c.catalogue, nil
+}
+
+func (c *staticStateClient) Close() error {
+	return nil
+}
+
+// NewStateClient returns state client that downloads the state catalogue from external service.
+// State models missing in the downloaded catalogue are taken from the configured catalogue.
+func NewStateClient(client client.Client, configured ticket.StateCatalogue) StateClient {
+	return &stateClient{
+		Client:     client,
+		configured: configured,
+	}
+}
+
+type stateClient struct {
+	client.Client
+	configured ticket.StateCatalogue
+}
+
+func (c stateClient) GetStateCatalogue(ctx context.Context) (ticket.StateCatalogue, error) {
+	catalogue := ticket.StateCatalogue{
+		Default:     c.configured.Default,
+		RecordTypes: make(map[string]ticket.StateModel),
+	}
+	for name, model := range c.configured.RecordTypes {
+		catalogue.RecordTypes[name] = model
+	}
+
+	resp, err := c.Get(ctx, "")
+	if err != nil {
+		return catalogue, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve ticket state catalogue")
+	}
+
+	type OKPayload struct {
+		Default     ticket.StateModel            `json:"default"`
+		RecordTypes map[string]ticket.StateModel `json:"record_types"`
+	}
+	var payload OKPayload
+
+	defer func() { _ = resp.Body.Close() }()
+	if err = json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return catalogue, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode state service Ok response")
+	}
+
+	if len(payload.Default) > 0 {
+		catalogue.Default = payload.Default
+	}
+
+	for name, model := range payload.RecordTypes {
+		if len(model) > 0 {
+			catalogue.RecordTypes[name] = model
+		}
+	}
+
+	return catalogue, nil
+}
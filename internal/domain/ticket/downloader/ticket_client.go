@@ -1,10 +1,11 @@
 package ticketdownloader
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"net/http"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
@@ -12,148 +13,188 @@ import (
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
 )
 
-// TicketClient gets ticket list (incidents and requests from external service
+// TicketClient gets ticket list (incidents, requests and other configured record types) from external service
 type TicketClient interface {
-	// GetIncidents gets ticket list with incidents from external service
-	GetIncidents(ctx context.Context, channel channel.Channel) (ticket.List, error)
+	// RecordTypes returns record types the client is able to download, ordered by their sort order
+	RecordTypes() []ticket.RecordType
 
-	// GetRequests gets ticket list with requests from external service
-	GetRequests(ctx context.Context, channel channel.Channel) (ticket.List, error)
+	// GetTickets gets ticket list with open records of the specified type from external service.
+	// State model of the record type defines which records are open and the names of their states.
+	GetTickets(ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel) (ticket.List, error)
+
+	// GetUpdatedTickets gets ticket list with records of the specified type updated since the given time, regardless
+	// of their state. It is used by the incremental download to find changed, new and no longer open records.
+	GetUpdatedTickets(
+		ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel, since time.Time,
+	) (ticket.List, error)
 
 	// Close closes client connections
 	Close() error
 }
 
-func NewTicketClient(incidentClient, requestClient client.Client) TicketClient {
+// RecordTypeClient binds the record type with the client of its endpoint
+type RecordTypeClient struct {
+	RecordType ticket.RecordType
+	Client     client.Client
+}
+
+// NewTicketClient returns client that downloads the tickets of the record types page by page according to pageConfig.
+// urlTemplates define the links to the downloaded tickets in the ITSM UI.
+func NewTicketClient(
+	recordTypeClients []RecordTypeClient, fieldMapping ticket.FieldMapping, urlTemplates ticket.URLTemplates,
+	pageConfig client.PageConfig,
+) TicketClient {
+	clients := make([]recordTypePaginator, 0, len(recordTypeClients))
+	for _, rtc := range recordTypeClients {
+		clients = append(clients, recordTypePaginator{
+			RecordTypeClient: rtc,
+			paginator:        client.NewPaginator(rtc.Client, pageConfig),
+		})
+	}
+
+	sort.SliceStable(clients, func(i, j int) bool {
+		return clients[i].RecordType.SortOrder < clients[j].RecordType.SortOrder
+	})
+
 	return &ticketClient{
-		incidentClient: incidentClient,
-		requestClient:  requestClient,
+		clients:      clients,
+		fieldMapping: fieldMapping,
+		urlTemplates: urlTemplates,
 	}
 }
 
-type ticketClient struct {
-	incidentClient client.Client
-	requestClient  client.Client
+// recordTypePaginator queries the pages of the record type endpoint
+type recordTypePaginator struct {
+	RecordTypeClient
+	paginator client.Paginator
 }
 
-func (c ticketClient) GetIncidents(ctx context.Context, channel channel.Channel) (ticket.List, error) {
-	var ticketList ticket.List
-	var bookmark string
+type ticketClient struct {
+	clients      []recordTypePaginator
+	fieldMapping ticket.FieldMapping
+	urlTemplates ticket.URLTemplates
+}
 
-	for {
-		payload := c.preparePayload(bookmark)
-		body := strings.NewReader(payload)
-		resp, err := c.incidentClient.Query(ctx, channel.ChannelID, body)
-		if err != nil {
-			return ticketList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about incidents")
-		}
+func (c ticketClient) RecordTypes() []ticket.RecordType {
+	recordTypes := make([]ticket.RecordType, 0, len(c.clients))
+	for _, rtc := range c.clients {
+		recordTypes = append(recordTypes, rtc.RecordType)
+	}
 
-		ticketList, bookmark, err = c.processResponse(resp, channel)
-		if err != nil {
-			return ticketList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode incident service Ok response")
-		}
+	return recordTypes
+}
 
-		if len(ticketList) < 10 {
-			break
-		}
-	}
+func (c ticketClient) GetTickets(
+	ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel,
+) (ticket.List, error) {
+	return c.getTickets(ctx, recordType, states, channel, c.openSelector(states))
+}
 
-	return ticketList, nil
+func (c ticketClient) GetUpdatedTickets(
+	ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel, since time.Time,
+) (ticket.List, error) {
+	return c.getTickets(ctx, recordType, states, channel, c.updatedSelector(since))
 }
 
-func (c ticketClient) GetRequests(ctx context.Context, channel channel.Channel) (ticket.List, error) {
+func (c ticketClient) getTickets(
+	ctx context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel,
+	selector map[string]interface{},
+) (ticket.List, error) {
 	var ticketList ticket.List
-	var bookmark string
 
-	for {
-		payload := c.preparePayload(bookmark)
-		body := strings.NewReader(payload)
-		resp, err := c.requestClient.Query(ctx, channel.ChannelID, body)
-		if err != nil {
-			return ticketList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about requests")
-		}
+	rtc, err := c.clientFor(recordType)
+	if err != nil {
+		return ticketList, err
+	}
 
-		ticketList, bookmark, err = c.processResponse(resp, channel)
+	err = rtc.paginator.Query(ctx, channel.ChannelID, c.prepareQuery(selector), func(records []json.RawMessage) error {
+		tickets, err := c.processRecords(records, recordType, states, channel)
 		if err != nil {
-			return ticketList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode request service Ok response")
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode %s service Ok response", recordType.Name)
 		}
 
-		if len(ticketList) < 10 {
-			break
-		}
+		ticketList = append(ticketList, tickets...)
+		return nil
+	})
+	if err != nil {
+		return ticketList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about %s records", recordType.Name)
 	}
 
 	return ticketList, nil
 }
 
 func (c *ticketClient) Close() error {
-	if err := c.incidentClient.Close(); err != nil {
-		return err
-	}
-	if err := c.requestClient.Close(); err != nil {
-		return err
+	for _, rtc := range c.clients {
+		if err := rtc.Client.Close(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (c ticketClient) preparePayload(bookmark string) string {
-	// Download only "open" tickets
-	// state_id: 4 = Resolved, 5 = Closed, 6 = Cancelled
-	return `{"selector":{"$and":[{"state_id":{"$ne":4}},{"state_id":{"$ne":5}},{"state_id":{"$ne":6}}]},` +
-		`"fields":["uuid","number","assigned_to","short_description","state_id","location","location_custom", "created_at"],"bookmark":"` + bookmark + `"}`
+func (c ticketClient) clientFor(recordType ticket.RecordType) (recordTypePaginator, error) {
+	for _, rtc := range c.clients {
+		if rtc.RecordType.Name == recordType.Name {
+			return rtc, nil
+		}
+	}
+
+	return recordTypePaginator{}, domain.NewErrorf(domain.ErrorCodeUnknown, "no client configured for record type '%s'", recordType.Name)
 }
 
-func (c ticketClient) processResponse(resp *http.Response, channel channel.Channel) (ticketList ticket.List, bookmark string, err error) {
-	type AssignedTo struct {
-		UUID string `json:"uuid"`
+// openSelector returns selector of the "open" tickets, i.e. it excludes all states that are not open in the state model
+// (by default 4 = Resolved, 5 = Closed, 6 = Cancelled)
+func (c ticketClient) openSelector(states ticket.StateModel) map[string]interface{} {
+	var conditions []map[string]interface{}
+	for _, id := range states.ClosedStateIDs() {
+		conditions = append(conditions, map[string]interface{}{
+			c.fieldMapping.StateID: map[string]int{"$ne": id},
+		})
 	}
-	type Location struct {
-		FullLocation string `json:"full_location"`
+
+	selector := map[string]interface{}{}
+	if len(conditions) > 0 {
+		selector["$and"] = conditions
 	}
 
-	type OKPayload struct {
-		Bookmark string `json:"bookmark"`
-		Result   []struct {
-			TicketType       string     `json:"docType"`
-			Number           string     `json:"number"`
-			AssignedTo       AssignedTo `json:"assigned_to"`
-			ShortDescription string     `json:"short_description"`
-			StateID          int        `json:"state_id"`
-			Location         Location   `json:"location"`
-			LocationCustom   Location   `json:"location_custom"`
-			CreatedAt        string     `json:"created_at"`
-		} `json:"result"`
+	return selector
+}
+
+// updatedSelector returns selector of the tickets updated since the given time in any state,
+// the timestamps are compared as RFC 3339 strings in UTC
+func (c ticketClient) updatedSelector(since time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		c.fieldMapping.UpdatedAt: map[string]string{"$gte": since.UTC().Format(time.RFC3339)},
 	}
-	var okPayload OKPayload
+}
 
-	defer func() { _ = resp.Body.Close() }()
-	if err = json.NewDecoder(resp.Body).Decode(&okPayload); err != nil {
-		return ticketList, bookmark, err
+// prepareQuery returns query of the records selected by the selector with the mapped fields
+func (c ticketClient) prepareQuery(selector map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"selector": selector,
+		"fields":   c.fieldMapping.RequestedFields(),
 	}
+}
 
-	bookmark = okPayload.Bookmark
+// processRecords converts the records of one page of the query result to tickets
+func (c ticketClient) processRecords(
+	records []json.RawMessage, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel,
+) (ticketList ticket.List, err error) {
+	for _, raw := range records {
+		var record map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err = decoder.Decode(&record); err != nil {
+			return ticketList, err
+		}
 
-	for _, v := range okPayload.Result {
-		location := v.Location.FullLocation
-		if v.LocationCustom.FullLocation != "" { // if custom location is filled in, we use custom location
-			location = v.Location.FullLocation
+		t, err := c.fieldMapping.NewTicket(record, recordType, states, channel.ChannelID, channel.Name, c.urlTemplates)
+		if err != nil {
+			return ticketList, err
 		}
 
-		ticketList = append(ticketList, ticket.Ticket{
-			UserID:      v.AssignedTo.UUID,
-			ChannelID:   channel.ChannelID,
-			ChannelName: channel.Name,
-			TicketType:  v.TicketType,
-			TicketData: ticket.Data{
-				Number:           v.Number,
-				ShortDescription: v.ShortDescription,
-				StateID:          v.StateID,
-				Location:         location,
-				CreatedAt:        v.CreatedAt,
-			},
-		},
-		)
+		ticketList = append(ticketList, t)
 	}
 
-	return ticketList, bookmark, nil
+	return ticketList, nil
 }
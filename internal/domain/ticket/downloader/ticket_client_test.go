@@ -0,0 +1,178 @@
+package ticketdownloader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketClient_processRecords(t *testing.T) {
+	fieldMapping, err := ticket.ParseFieldMapping("priority=priority,assignment_group=assignment_group.name,escalated=escalated")
+	require.NoError(t, err)
+
+	c := ticketClient{
+		fieldMapping: fieldMapping,
+		urlTemplates: ticket.URLTemplates{BaseURL: "https://itsm.test/", Default: "{base}/{channel}/{record_type}/{uuid}"},
+	}
+
+	ch := channel.Channel{
+		ChannelID: "c5bea8d9-1d90-4d90-a445-e6ce74dff4cc",
+		Name:      "First channel",
+	}
+
+	states, err := ticket.ParseStateModel("0=New,2=Assigned,4=Resolved:closed")
+	require.NoError(t, err)
+
+	payload := `{"bookmark":"abc","result":[
+		{"docType":"INCIDENT","uuid":"5e1c1a83-4be1-4e1e-8c86-3bd5e0c3bd35","number":"INC1111",
+		 "assigned_to":{"uuid":"c8d1b9fb-35f1-46cb-aa37-a16b96937734"},"short_description":"Incident 1111","state_id":2,
+		 "location":{"full_location":"Cz Praha"},"location_custom":{"full_location":"Custom location"},
+		 "created_at":"2022-03-01T10:00:00Z","priority":3,"assignment_group":{"name":"Network"},"escalated":true},
+		{"docType":"INCIDENT","number":"INC2222","state_id":0,"location":{"full_location":"Sp Teruel"}}
+	]}`
+
+	var page struct {
+		Result []json.RawMessage `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(payload), &page))
+
+	list, err := c.processRecords(page.Result, ticket.RecordType{Name: "incident", SortOrder: 1}, states, ch)
+	require.NoError(t, err)
+
+	require.Len(t, list, 2)
+
+	assert.Equal(t, ticket.Ticket{
+		UserID:          "c8d1b9fb-35f1-46cb-aa37-a16b96937734",
+		ChannelID:       ch.ChannelID,
+		ChannelName:     ch.Name,
+		TicketType:      "INCIDENT",
+		RecordType:      "incident",
+		TicketTypeOrder: 1,
+		TicketData: ticket.Data{
+			UUID:             "5e1c1a83-4be1-4e1e-8c86-3bd5e0c3bd35",
+			Number:           "INC1111",
+			ShortDescription: "Incident 1111",
+			StateID:          2,
+			State:            "Assigned",
+			Location:         "Custom location",
+			CreatedAt:        "2022-03-01T10:00:00Z",
+			URL:              "https://itsm.test/c5bea8d9-1d90-4d90-a445-e6ce74dff4cc/incident/5e1c1a83-4be1-4e1e-8c86-3bd5e0c3bd35",
+			Fields: map[string]string{
+				"priority":         "3",
+				"assignment_group": "Network",
+				"escalated":        "true",
+			},
+		},
+	}, list[0])
+
+	assert.Equal(t, "", list[1].UserID)
+	assert.Equal(t, "Sp Teruel", list[1].TicketData.Location)
+	assert.Equal(t, "New", list[1].TicketData.StateName())
+	assert.Equal(t, "", list[1].TicketData.Field("priority"))
+	assert.Equal(t, "", list[1].TicketData.URL, "no link without uuid")
+}
+
+func TestTicketClient_prepareQuery(t *testing.T) {
+	c := ticketClient{fieldMapping: ticket.DefaultFieldMapping()}
+
+	payload, err := json.Marshal(c.prepareQuery(c.openSelector(ticket.DefaultStateModel())))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"selector":{"$and":[{"state_id":{"$ne":4}},{"state_id":{"$ne":5}},{"state_id":{"$ne":6}}]},
+		"fields":["uuid","number","assigned_to","short_description","state_id","location","location_custom","created_at"]
+	}`, string(payload))
+
+	states, err := ticket.ParseStateModel("0=New,1=Open,7=Done:closed")
+	require.NoError(t, err)
+
+	payload, err = json.Marshal(c.prepareQuery(c.openSelector(states)))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"selector":{"$and":[{"state_id":{"$ne":7}}]},
+		"fields":["uuid","number","assigned_to","short_description","state_id","location","location_custom","created_at"]
+	}`, string(payload))
+
+	since := time.Date(2022, 5, 3, 8, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	payload, err = json.Marshal(c.prepareQuery(c.updatedSelector(since)))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"selector":{"updated_at":{"$gte":"2022-05-03T06:30:00Z"}},
+		"fields":["uuid","number","assigned_to","short_description","state_id","location","location_custom","created_at"]
+	}`, string(payload))
+}
+
+func TestTicketClient_GetTickets(t *testing.T) {
+	ch := channel.Channel{ChannelID: "c5bea8d9-1d90-4d90-a445-e6ce74dff4cc", Name: "First channel"}
+	incident := ticket.RecordType{Name: "incident"}
+
+	// three pages, the last one repeats the bookmark
+	itsm := &pagedClientMock{pages: map[string]string{
+		"":   `{"bookmark":"b1","result":[{"number":"INC1"},{"number":"INC2"}]}`,
+		"b1": `{"bookmark":"b2","result":[{"number":"INC3"},{"number":"INC4"}]}`,
+		"b2": `{"bookmark":"b2","result":[{"number":"INC5"}]}`,
+	}}
+
+	c := NewTicketClient(
+		[]RecordTypeClient{{RecordType: incident, Client: itsm}}, ticket.DefaultFieldMapping(), ticket.URLTemplates{},
+		client.PageConfig{PageSize: 2},
+	)
+
+	list, err := c.GetTickets(context.Background(), incident, ticket.DefaultStateModel(), ch)
+	require.NoError(t, err)
+
+	var numbers []string
+	for _, tckt := range list {
+		numbers = append(numbers, tckt.TicketData.Number)
+	}
+	assert.Equal(t, []string{"INC1", "INC2", "INC3", "INC4", "INC5"}, numbers, "tickets of all pages")
+	assert.Equal(t, []string{"", "b1", "b2"}, itsm.bookmarks)
+	assert.Equal(t, []interface{}{float64(2), float64(2), float64(2)}, itsm.limits)
+}
+
+// pagedClientMock returns the pages of the query result by the bookmark in the query
+type pagedClientMock struct {
+	pages     map[string]string
+	bookmarks []string
+	limits    []interface{}
+}
+
+func (m *pagedClientMock) Get(_ context.Context, _ string) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *pagedClientMock) Query(_ context.Context, _ string, body io.ReadSeeker) (*http.Response, error) {
+	var query map[string]interface{}
+	if err := json.NewDecoder(body).Decode(&query); err != nil {
+		return nil, err
+	}
+
+	bookmark, _ := query["bookmark"].(string)
+	m.bookmarks = append(m.bookmarks, bookmark)
+	m.limits = append(m.limits, query["limit"])
+
+	page, ok := m.pages[bookmark]
+	if !ok {
+		page = `{"bookmark":"","result":[]}`
+	}
+
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(page))}, nil
+}
+
+func (m *pagedClientMock) URL() string { return "http://itsm.test/incident" }
+
+func (m *pagedClientMock) Close() error { return nil }
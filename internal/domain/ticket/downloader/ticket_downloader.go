@@ -2,122 +2,404 @@ package ticketdownloader
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
 	"go.uber.org/zap"
 )
 
 // TicketDownloader downloads list of users from the ITSM service
 type TicketDownloader interface {
-	// DownloadTickets downloads and stores list of tickets from the ITSM service
-	DownloadTickets(ctx context.Context) error
+	// DownloadTickets downloads list of tickets from the ITSM service and replaces the stored tickets with it.
+	// With incremental download enabled, only tickets updated since the last successful job are downloaded and merged
+	// with the tickets of that job, unless fullResync is requested; channels and record types not covered by that job
+	// are downloaded in full. It returns the used download mode (job.TicketsDownloadFull/Incremental).
+	DownloadTickets(ctx context.Context, fullResync bool) (string, error)
 
-	// Reset removes all items from downloader repository
+	// SaveSnapshot stores downloaded tickets as the snapshot of the job and removes expired snapshots
+	SaveSnapshot(ctx context.Context, jobID ref.UUID) error
+
+	// Warnings returns data-quality warnings found during the last download, e.g. assignees missing in the user directory
+	Warnings() []string
+
+	// Reset clears the state of the last download, the stored tickets are kept until the next download replaces them
 	Reset(ctx context.Context) error
 
 	// Close closes client connections
 	Close() error
 }
 
+// IncrementalConfig configures the incremental download of the tickets
+type IncrementalConfig struct {
+	// Enabled turns on the incremental download, otherwise each job downloads all open tickets
+	Enabled bool
+	// FullResyncInterval is the period of the scheduled full download measured from the last successful job with the full
+	// download. Zero means that the full download is done only on request and when no job has done it yet.
+	FullResyncInterval time.Duration
+}
+
+// incrementalOverlap is subtracted from the start of the previous download to cover clock differences
+// between this service and the ITSM service
+const incrementalOverlap = 5 * time.Minute
+
 func NewTicketDownloader(
 	logger *zap.SugaredLogger,
+	clock repository.Clock,
 	channelRepository repository.ChannelRepository,
 	userRepository repository.UserRepository,
 	ticketRepository repository.TicketRepository,
+	snapshotRepository repository.TicketSnapshotRepository,
+	jobRepository repository.JobRepository,
 	client TicketClient,
+	stateClient StateClient,
+	incremental IncrementalConfig,
 ) TicketDownloader {
 	return &ticketDownloader{
-		logger:            logger,
-		client:            client,
-		channelRepository: channelRepository,
-		userRepository:    userRepository,
-		ticketRepository:  ticketRepository,
+		logger:             logger,
+		clock:              clock,
+		client:             client,
+		stateClient:        stateClient,
+		channelRepository:  channelRepository,
+		userRepository:     userRepository,
+		ticketRepository:   ticketRepository,
+		snapshotRepository: snapshotRepository,
+		jobRepository:      jobRepository,
+		incremental:        incremental,
 	}
 }
 
 type ticketDownloader struct {
-	logger            *zap.SugaredLogger
-	client            TicketClient
-	channelRepository repository.ChannelRepository
-	userRepository    repository.UserRepository
-	ticketRepository  repository.TicketRepository
+	logger             *zap.SugaredLogger
+	clock              repository.Clock
+	client             TicketClient
+	stateClient        StateClient
+	channelRepository  repository.ChannelRepository
+	userRepository     repository.UserRepository
+	ticketRepository   repository.TicketRepository
+	snapshotRepository repository.TicketSnapshotRepository
+	jobRepository      repository.JobRepository
+	incremental        IncrementalConfig
+	scopes             []ticket.Scope // channels and record types covered by the last download
+	warnings           []string
+}
+
+// previousDownload is the base of the incremental download, the tickets of the last successful job
+type previousDownload struct {
+	// since is the time (minus overlap) the download of the tickets started
+	since   time.Time
+	tickets ticket.List
+	scopes  map[ticket.Scope]bool
+}
+
+// ticketsOf returns the tickets of the scope from the previous download
+func (p previousDownload) ticketsOf(scope ticket.Scope) ticket.List {
+	var list ticket.List
+	for _, t := range p.tickets {
+		if t.ChannelID == scope.ChannelID && t.RecordType == scope.RecordType {
+			list = append(list, t)
+		}
+	}
+
+	return list
+}
+
+// assigneeProblem is a data-quality problem with the assignee of the tickets in the channel
+type assigneeProblem struct {
+	channelName string
+	userID      string
+	userName    string
+	tickets     int
 }
 
-func (d *ticketDownloader) DownloadTickets(ctx context.Context) error {
+func (d *ticketDownloader) DownloadTickets(ctx context.Context, fullResync bool) (string, error) {
+	d.warnings = nil
+	d.scopes = nil
+
 	channels, err := d.channelRepository.GetChannelList(ctx)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	// state catalogue is loaded at the start of each job, so the changes in ITSM state models are reflected
+	states, err := d.stateClient.GetStateCatalogue(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	previous, err := d.previousDownload(ctx, fullResync)
+	if err != nil {
+		return "", err
+	}
+
+	mode := job.TicketsDownloadFull
+	if previous != nil {
+		mode = job.TicketsDownloadIncremental
+		d.logger.Infow("Downloading tickets updated since the last successful job", "since", previous.since.Format(time.RFC3339))
 	}
 
+	var unknownAssignees, assigneesWithoutEmail []*assigneeProblem
+	var downloaded ticket.List
+	var scopes []ticket.Scope
+
 	for _, channel := range channels {
 		d.logger.Infow("Downloading tickets from the channel", "channel", channel.Name)
+		ctx, span := tracing.Start(ctx, "download channel tickets", tracing.ChannelIDKey.String(channel.ChannelID))
 
-		var ticketList ticket.List
 		ticketsCount := 0
+		unknown := make(map[string]*assigneeProblem)
+		withoutEmail := make(map[string]*assigneeProblem)
 
-		ticketList, err = d.client.GetIncidents(ctx, channel)
-		if err != nil {
-			return err
-		}
+		for _, recordType := range d.client.RecordTypes() {
+			var ticketList ticket.List
+			scope := ticket.Scope{ChannelID: channel.ChannelID, RecordType: recordType.Name}
 
-		err = d.resolveAssignee(ctx, channel.ChannelID, ticketList)
-		if err != nil {
-			return err
-		}
+			if previous != nil && previous.scopes[scope] {
+				updated, err := d.client.GetUpdatedTickets(ctx, recordType, states.Model(recordType.Name), channel, previous.since)
+				if err != nil {
+					tracing.End(span, err)
+					return "", err
+				}
 
-		if err := d.ticketRepository.AddTicketList(ctx, ticketList); err != nil {
-			return err
-		}
+				ticketList = ticket.MergeUpdatedTickets(previous.ticketsOf(scope), updated, states.Model(recordType.Name))
+			} else {
+				if previous != nil {
+					// e.g. new channel or record type, its older tickets are not among the updated ones
+					d.logger.Infow("Downloading all tickets of the record type not covered by the last successful job",
+						"channel", channel.Name, "record type", recordType.Name,
+					)
+				}
 
-		ticketsCount += len(ticketList)
+				ticketList, err = d.client.GetTickets(ctx, recordType, states.Model(recordType.Name), channel)
+				if err != nil {
+					tracing.End(span, err)
+					return "", err
+				}
+			}
 
-		ticketList, err = d.client.GetRequests(ctx, channel)
-		if err != nil {
-			return err
+			err = d.resolveAssignee(ctx, channel.ChannelID, ticketList)
+			if err != nil {
+				tracing.End(span, err)
+				return "", err
+			}
+
+			for _, t := range ticketList {
+				switch {
+				case t.HasUnknownAssignee():
+					unknownAssignees = countAssigneeProblem(unknown, unknownAssignees, t)
+				case t.UserID != "" && t.UserEmail == "":
+					assigneesWithoutEmail = countAssigneeProblem(withoutEmail, assigneesWithoutEmail, t)
+				}
+			}
+
+			downloaded = append(downloaded, ticketList...)
+			scopes = append(scopes, scope)
+			ticketsCount += len(ticketList)
 		}
 
-		err = d.resolveAssignee(ctx, channel.ChannelID, ticketList)
-		if err != nil {
-			return err
+		span.End()
+		d.logger.Infow("Tickets from the channel successfully downloaded", "channel", channel.Name, "tickets found", ticketsCount)
+	}
+
+	for _, p := range unknownAssignees {
+		d.warnings = append(d.warnings, fmt.Sprintf(
+			"assignee '%s' of %d ticket(s) in channel '%s' not found in the user directory",
+			p.userID, p.tickets, p.channelName,
+		))
+	}
+
+	for _, p := range assigneesWithoutEmail {
+		d.warnings = append(d.warnings, fmt.Sprintf(
+			"assignee '%s' (%s) of %d ticket(s) in channel '%s' has no email address",
+			p.userName, p.userID, p.tickets, p.channelName,
+		))
+	}
+
+	if len(d.warnings) > 0 {
+		d.logger.Warnw("Tickets with assignee problems found", "warnings", d.warnings)
+	}
+
+	// stored tickets are replaced only when all of them are downloaded, failed download keeps the previous ones
+	if err := d.ticketRepository.Truncate(ctx); err != nil {
+		return "", err
+	}
+
+	if err := d.ticketRepository.AddTicketList(ctx, downloaded); err != nil {
+		return "", err
+	}
+
+	d.scopes = scopes
+
+	return mode, nil
+}
+
+// previousDownload returns the tickets of the last successful job, if the tickets can be downloaded incrementally.
+// Nil means that full download is needed.
+func (d *ticketDownloader) previousDownload(ctx context.Context, fullResync bool) (*previousDownload, error) {
+	switch {
+	case !d.incremental.Enabled:
+		return nil, nil
+	case fullResync:
+		d.logger.Infow("Full resync of the tickets requested")
+		return nil, nil
+	}
+
+	lastFullJob, err := d.jobRepository.GetLastFullDownloadJob(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			d.logger.Infow("Full resync of the tickets, no previous successful job with full download")
+			return nil, nil
 		}
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get last successful job with full download")
+	}
+
+	lastFullDownloadAt, err := lastFullJob.TicketsDownloadStartedAt.ToTime()
+	if err != nil {
+		d.logger.Infow("Full resync of the tickets, last full download has no ticket download time", "job", lastFullJob.UUID())
+		return nil, nil
+	}
+
+	if d.incremental.FullResyncInterval > 0 && d.clock.Now().Sub(lastFullDownloadAt) >= d.incremental.FullResyncInterval {
+		d.logger.Infow("Scheduled full resync of the tickets", "last full download", lastFullDownloadAt.Format(time.RFC3339))
+		return nil, nil
+	}
+
+	lastJob, err := d.jobRepository.GetLastSuccessfulJob(ctx)
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get last successful job")
+	}
+
+	startedAt, err := lastJob.TicketsDownloadStartedAt.ToTime()
+	if err != nil {
+		d.logger.Infow("Full resync of the tickets, last successful job has no ticket download time", "job", lastJob.UUID())
+		return nil, nil
+	}
 
-		if err := d.ticketRepository.AddTicketList(ctx, ticketList); err != nil {
-			return err
+	scopes, err := d.snapshotRepository.GetSnapshotScopes(ctx, lastJob.UUID())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			d.logger.Infow("Full resync of the tickets, ticket snapshot of the last successful job expired", "job", lastJob.UUID())
+			return nil, nil
 		}
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get ticket snapshot scopes of the job '%s'", lastJob.UUID())
+	}
 
-		ticketsCount += len(ticketList)
+	if len(scopes) == 0 {
+		d.logger.Infow("Full resync of the tickets, ticket snapshot of the last successful job covers no channels", "job", lastJob.UUID())
+		return nil, nil
+	}
 
-		d.logger.Infow("Tickets from the channel successfully downloaded", "channel", channel.Name, "tickets found", ticketsCount)
+	tickets, err := d.snapshotRepository.GetSnapshot(ctx, lastJob.UUID())
+	if err != nil {
+		return nil, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get ticket snapshot of the job '%s'", lastJob.UUID())
 	}
 
-	return nil
+	previous := &previousDownload{
+		since:   startedAt.Add(-incrementalOverlap),
+		tickets: tickets,
+		scopes:  make(map[ticket.Scope]bool, len(scopes)),
+	}
+	for _, scope := range scopes {
+		previous.scopes[scope] = true
+	}
+
+	return previous, nil
 }
 
+// countAssigneeProblem counts the ticket to the problem of its assignee, new problems are appended to the list
+func countAssigneeProblem(byUserID map[string]*assigneeProblem, list []*assigneeProblem, t ticket.Ticket) []*assigneeProblem {
+	p, ok := byUserID[t.UserID]
+	if !ok {
+		p = &assigneeProblem{channelName: t.ChannelName, userID: t.UserID, userName: t.UserName}
+		byUserID[t.UserID] = p
+		list = append(list, p)
+	}
+	p.tickets++
+
+	return list
+}
+
+// resolveAssignee fills in the assignee info of the tickets. Assignees missing in the channel are looked up in the other
+// channels; if they are not found at all, the tickets are left with unknown assignee (see ticket.HasUnknownAssignee).
 func (d *ticketDownloader) resolveAssignee(ctx context.Context, channelID string, ticketList ticket.List) error {
 	for i, tckt := range ticketList {
-		if tckt.UserID != "" {
-			user, err := d.userRepository.GetUserInChannel(ctx, channelID, tckt.UserID)
-			if err != nil {
-				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get user '%s' from repository", tckt.UserID)
-			}
+		if tckt.UserID == "" {
+			continue
+		}
 
-			tckt.UserName = user.Name
-			tckt.UserEmail = user.Email
-			tckt.UserOrgName = user.OrgName
+		// tickets kept from the previous download are resolved again, the user could have changed
+		tckt.UserName = ""
+		tckt.UserEmail = ""
+		tckt.UserOrgName = ""
+		tckt.UserType = ""
+		ticketList[i] = tckt
 
-			ticketList[i] = tckt
+		user, err := d.userRepository.GetUserInChannel(ctx, channelID, tckt.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			// e.g. the user was moved to another channel
+			user, err = d.userRepository.GetUser(ctx, tckt.UserID)
 		}
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue // e.g. the user left
+			}
+			return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not get user '%s' from repository", tckt.UserID)
+		}
+
+		tckt.UserName = user.Name
+		tckt.UserEmail = user.Email
+		tckt.UserOrgName = user.OrgName
+		tckt.UserType = user.Type
+
+		ticketList[i] = tckt
 	}
 
 	return nil
 }
 
-func (d *ticketDownloader) Reset(ctx context.Context) error {
-	return d.ticketRepository.Truncate(ctx)
+func (d *ticketDownloader) Warnings() []string {
+	return append([]string(nil), d.warnings...)
+}
+
+func (d *ticketDownloader) SaveSnapshot(ctx context.Context, jobID ref.UUID) error {
+	ticketList, err := d.ticketRepository.GetTicketList(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := d.snapshotRepository.SaveSnapshot(ctx, jobID, ticketList, d.scopes); err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not save ticket snapshot of the job '%s'", jobID)
+	}
+
+	d.logger.Infow("Ticket snapshot saved", "job", jobID, "tickets", len(ticketList))
+
+	deleted, err := d.snapshotRepository.DeleteExpiredSnapshots(ctx)
+	if err != nil {
+		return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not delete expired ticket snapshots")
+	}
+
+	if deleted > 0 {
+		d.logger.Infow("Expired ticket snapshots deleted", "tickets", deleted)
+	}
+
+	return nil
+}
+
+func (d *ticketDownloader) Reset(_ context.Context) error {
+	d.warnings = nil
+	d.scopes = nil
+	return nil
 }
 
 func (d *ticketDownloader) Close() error {
+	if err := d.stateClient.Close(); err != nil {
+		return err
+	}
 	return d.client.Close()
 }
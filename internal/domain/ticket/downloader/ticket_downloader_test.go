@@ -0,0 +1,209 @@
+package ticketdownloader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/types"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/user"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketDownloader_IncrementalDownload(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	ctx := context.Background()
+
+	ch1 := channel.Channel{ChannelID: "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01", Name: "First channel"}
+	ch2 := channel.Channel{ChannelID: "6abf417c-52e3-4340-9713-df2f37e78176", Name: "Second channel"}
+	u1 := user.User{ChannelID: ch1.ChannelID, UserID: "2daf8a6e-3b3c-4a4f-9e0e-2c7b5b1c1a01", Email: "joe@email.test", Name: "Joe"}
+
+	incidentType := ticket.RecordType{Name: "incident", DisplayName: "Incident", SortOrder: 0}
+	requestType := ticket.RecordType{Name: "request", DisplayName: "Request", SortOrder: 0}
+
+	newTicket := func(ch channel.Channel, recordType ticket.RecordType, number string, stateID int) ticket.Ticket {
+		return ticket.Ticket{
+			UserID:      u1.UserID,
+			ChannelID:   ch.ChannelID,
+			ChannelName: ch.Name,
+			TicketType:  recordType.DisplayName,
+			RecordType:  recordType.Name,
+			TicketData:  ticket.Data{Number: number, StateID: stateID},
+		}
+	}
+
+	channelRepository := memory.NewChannelRepositoryMemory()
+	require.NoError(t, channelRepository.StoreChannelList(ctx, channel.List{ch1}))
+
+	userRepository := memory.NewUserRepositoryMemory()
+	require.NoError(t, userRepository.AddUserList(ctx, user.List{u1}))
+
+	clock := mocks.NewFixedClock()
+	ticketRepository := memory.NewTicketRepositoryMemory()
+	snapshotRepository := memory.NewTicketSnapshotRepositoryMemory(clock, 0)
+	jobRepository := memory.NewJobRepositoryMemory(clock)
+	incremental := IncrementalConfig{Enabled: true, FullResyncInterval: 24 * time.Hour}
+
+	// finishJob records the successful job with the ticket download started at the time and saves its snapshot
+	finishJob := func(d TicketDownloader, mode, startedAt string) {
+		jobID, err := jobRepository.AddJob(ctx, job.Job{Type: job.TypeAll})
+		require.NoError(t, err)
+		j, err := jobRepository.GetJob(ctx, jobID)
+		require.NoError(t, err)
+		j.TicketsDownloadStartedAt = types.DateTime(startedAt)
+		j.TicketsDownloadMode = mode
+		j.FinalStatus = job.StatusSuccess
+		_, err = jobRepository.UpdateJob(ctx, j)
+		require.NoError(t, err)
+		require.NoError(t, d.SaveSnapshot(ctx, jobID))
+	}
+
+	ticketNumbers := func() []string {
+		list, err := ticketRepository.GetTicketList(ctx)
+		require.NoError(t, err)
+		var n []string
+		for _, t := range list {
+			n = append(n, t.TicketData.Number)
+		}
+		return n
+	}
+
+	// the first download is full, there is no previous job
+	ticketClient := new(mocks.TicketClientMock)
+	ticketClient.On("RecordTypes").Return([]ticket.RecordType{incidentType})
+	ticketClient.On("GetTickets", incidentType, ch1).Return(ticket.List{
+		newTicket(ch1, incidentType, "INC1", 0), newTicket(ch1, incidentType, "INC2", 1),
+	}, nil)
+	ticketClient.Wg.Add(1)
+
+	d := NewTicketDownloader(
+		logger, clock, channelRepository, userRepository, ticketRepository, snapshotRepository, jobRepository,
+		ticketClient, NewStaticStateClient(ticket.DefaultStateCatalogue()), incremental,
+	)
+
+	mode, err := d.DownloadTickets(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, job.TicketsDownloadFull, mode)
+	finishJob(d, mode, "2021-04-01T12:00:00+02:00")
+
+	ticketClient.Wg.Wait()
+	ticketClient.AssertExpectations(t)
+
+	require.NoError(t, d.Reset(ctx))
+	assert.Equal(t, []string{"INC1", "INC2"}, ticketNumbers(), "stored tickets are kept until the next download")
+
+	// after the restart, with new channel and record type, the download is still incremental; the channel and the
+	// record type not covered by the last job are downloaded in full
+	require.NoError(t, channelRepository.StoreChannelList(ctx, channel.List{ch1, ch2}))
+
+	since := time.Date(2021, 4, 1, 9, 55, 0, 0, time.UTC) // job download start minus overlap
+	ticketClient = new(mocks.TicketClientMock)
+	ticketClient.On("RecordTypes").Return([]ticket.RecordType{incidentType, requestType})
+	ticketClient.On("GetUpdatedTickets", incidentType, ch1, mock.MatchedBy(func(t time.Time) bool { return t.Equal(since) })).
+		Return(ticket.List{newTicket(ch1, incidentType, "INC2", 4), newTicket(ch1, incidentType, "INC3", 0)}, nil)
+	ticketClient.On("GetTickets", requestType, ch1).Return(ticket.List{newTicket(ch1, requestType, "REQ1", 0)}, nil)
+	ticketClient.On("GetTickets", incidentType, ch2).Return(ticket.List{newTicket(ch2, incidentType, "INC4", 0)}, nil)
+	ticketClient.On("GetTickets", requestType, ch2).Return(ticket.List{}, nil)
+	ticketClient.Wg.Add(4)
+
+	clock.AddTime(time.Hour)
+	d = NewTicketDownloader(
+		logger, clock, channelRepository, userRepository, ticketRepository, snapshotRepository, jobRepository,
+		ticketClient, NewStaticStateClient(ticket.DefaultStateCatalogue()), incremental,
+	)
+
+	mode, err = d.DownloadTickets(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, job.TicketsDownloadIncremental, mode)
+	assert.Equal(t, []string{"INC1", "INC3", "REQ1", "INC4"}, ticketNumbers(), "resolved ticket INC2 is removed")
+
+	list, err := ticketRepository.GetTicketList(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, u1.Email, list[1].UserEmail, "assignee of the new ticket is resolved")
+
+	finishJob(d, mode, "2021-04-01T13:00:00+02:00")
+
+	ticketClient.Wg.Wait()
+	ticketClient.AssertExpectations(t)
+
+	// scheduled full resync, a day after the last full download
+	ticketClient = new(mocks.TicketClientMock)
+	ticketClient.On("RecordTypes").Return([]ticket.RecordType{incidentType})
+	ticketClient.On("GetTickets", incidentType, ch1).Return(ticket.List{newTicket(ch1, incidentType, "INC1", 0)}, nil)
+	ticketClient.On("GetTickets", incidentType, ch2).Return(ticket.List{}, nil)
+	ticketClient.Wg.Add(2)
+
+	clock.AddTime(23 * time.Hour)
+	d = NewTicketDownloader(
+		logger, clock, channelRepository, userRepository, ticketRepository, snapshotRepository, jobRepository,
+		ticketClient, NewStaticStateClient(ticket.DefaultStateCatalogue()), incremental,
+	)
+
+	mode, err = d.DownloadTickets(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, job.TicketsDownloadFull, mode)
+	assert.Equal(t, []string{"INC1"}, ticketNumbers())
+
+	// full resync on request
+	require.NoError(t, d.Reset(ctx))
+	ticketClient.Wg.Add(2)
+	mode, err = d.DownloadTickets(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, job.TicketsDownloadFull, mode)
+
+	ticketClient.Wg.Wait()
+	ticketClient.AssertExpectations(t)
+}
+
+func TestTicketDownloader_StateModelOfRecordType(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	ctx := context.Background()
+
+	ch1 := channel.Channel{ChannelID: "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01", Name: "First channel"}
+	problemType := ticket.RecordType{Name: "problem", DisplayName: "Problem", SortOrder: 0}
+
+	channelRepository := memory.NewChannelRepositoryMemory()
+	require.NoError(t, channelRepository.StoreChannelList(ctx, channel.List{ch1}))
+
+	ticketRepository := memory.NewTicketRepositoryMemory()
+
+	ticketClient := new(mocks.TicketClientMock)
+	ticketClient.On("RecordTypes").Return([]ticket.RecordType{problemType})
+	ticketClient.On("GetTickets", problemType, ch1).Return(ticket.List{
+		{ChannelID: ch1.ChannelID, TicketType: problemType.DisplayName, TicketData: ticket.Data{Number: "PRB1", StateID: 0}},
+		{ChannelID: ch1.ChannelID, TicketType: problemType.DisplayName, TicketData: ticket.Data{Number: "PRB2", StateID: 1}},
+	}, nil)
+	ticketClient.Wg.Add(1)
+
+	catalogue := ticket.DefaultStateCatalogue()
+	catalogue.RecordTypes = map[string]ticket.StateModel{
+		"problem": {{ID: 0, Name: "Identified", Open: true}, {ID: 1, Name: "Fixed", Open: false}},
+	}
+
+	d := NewTicketDownloader(
+		logger, mocks.NewFixedClock(), channelRepository, memory.NewUserRepositoryMemory(), ticketRepository,
+		memory.NewTicketSnapshotRepositoryMemory(mocks.NewFixedClock(), 0), memory.NewJobRepositoryMemory(mocks.NewFixedClock()),
+		ticketClient, NewStaticStateClient(catalogue),
+		IncrementalConfig{},
+	)
+
+	_, err := d.DownloadTickets(ctx, false)
+	require.NoError(t, err)
+
+	list, err := ticketRepository.GetTicketList(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1, "ticket in the state closed by the state model of the record type is not downloaded")
+	assert.Equal(t, "PRB1", list[0].TicketData.Number)
+	assert.Equal(t, "Identified", list[0].TicketData.StateName())
+
+	ticketClient.Wg.Wait()
+	ticketClient.AssertExpectations(t)
+}
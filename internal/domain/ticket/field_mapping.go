@@ -0,0 +1,158 @@
+package ticket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldMapping maps ticket data to the fields of the ITSM record.
+// Nested values are addressed by paths with dot separated names, e.g. "location.full_location".
+type FieldMapping struct {
+	UUID             string
+	Number           string
+	AssignedTo       string
+	ShortDescription string
+	StateID          string
+	Location         string
+	LocationCustom   string
+	CreatedAt        string
+	// UpdatedAt is used only in the query of the incremental download, it is not stored in the ticket data
+	UpdatedAt string
+
+	// Extra fields are stored in Data.Fields and are shown as additional columns in the reports
+	Extra []Field
+}
+
+// Field is an additional ticket field downloaded from the ITSM service
+type Field struct {
+	// Key of the value in Data.Fields
+	Key string
+	// Label is used as a column header in the reports
+	Label string
+	// Path of the field in the ITSM record
+	Path string
+}
+
+// DefaultFieldMapping returns mapping of the ticket fields used by default
+func DefaultFieldMapping() FieldMapping {
+	return FieldMapping{
+		UUID:             "uuid",
+		Number:           "number",
+		AssignedTo:       "assigned_to.uuid",
+		ShortDescription: "short_description",
+		StateID:          "state_id",
+		Location:         "location.full_location",
+		LocationCustom:   "location_custom.full_location",
+		CreatedAt:        "created_at",
+		UpdatedAt:        "updated_at",
+	}
+}
+
+// ParseFieldMapping returns default field mapping modified by the mapping definition.
+// Definition is a comma separated list of "key=path" pairs, e.g. "priority=priority,assignment_group=assignment_group.name".
+// Keys "uuid", "number", "assigned_to", "short_description", "state_id", "location", "location_custom", "created_at"
+// and "updated_at" change the path of the default fields, any other key adds an extra field.
+func ParseFieldMapping(definition string) (FieldMapping, error) {
+	m := DefaultFieldMapping()
+
+	extraKeys := make(map[string]bool)
+
+	for _, item := range strings.Split(definition, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return m, fmt.Errorf("invalid field mapping '%s', expected 'key=path'", item)
+		}
+
+		key := strings.TrimSpace(kv[0])
+		path := strings.TrimSpace(kv[1])
+		if key == "" || path == "" {
+			return m, fmt.Errorf("invalid field mapping '%s', key and path must not be empty", item)
+		}
+
+		switch key {
+		case "uuid":
+			m.UUID = path
+		case "number":
+			m.Number = path
+		case "assigned_to":
+			m.AssignedTo = path
+		case "short_description":
+			m.ShortDescription = path
+		case "state_id":
+			m.StateID = path
+		case "location":
+			m.Location = path
+		case "location_custom":
+			m.LocationCustom = path
+		case "created_at":
+			m.CreatedAt = path
+		case "updated_at":
+			m.UpdatedAt = path
+		default:
+			if extraKeys[key] {
+				return m, fmt.Errorf("duplicate field mapping key '%s'", key)
+			}
+			extraKeys[key] = true
+
+			m.Extra = append(m.Extra, Field{
+				Key:   key,
+				Label: fieldLabel(key),
+				Path:  path,
+			})
+		}
+	}
+
+	return m, nil
+}
+
+// RequestedFields returns the list of top level field names to be requested from the ITSM service
+func (m FieldMapping) RequestedFields() []string {
+	paths := []string{
+		m.UUID,
+		m.Number,
+		m.AssignedTo,
+		m.ShortDescription,
+		m.StateID,
+		m.Location,
+		m.LocationCustom,
+		m.CreatedAt,
+	}
+	for _, f := range m.Extra {
+		paths = append(paths, f.Path)
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+
+	for _, p := range paths {
+		name := strings.SplitN(p, ".", 2)[0]
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, name)
+	}
+
+	return fields
+}
+
+// ExtraLabels returns column headers of the extra fields
+func (m FieldMapping) ExtraLabels() []string {
+	var labels []string
+	for _, f := range m.Extra {
+		labels = append(labels, f.Label)
+	}
+
+	return labels
+}
+
+// fieldLabel converts field key to human-readable column header, e.g. "assignment_group" => "Assignment group"
+func fieldLabel(key string) string {
+	label := strings.ReplaceAll(key, "_", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
@@ -0,0 +1,45 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldMapping(t *testing.T) {
+	t.Run("empty definition returns default mapping", func(t *testing.T) {
+		m, err := ParseFieldMapping("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultFieldMapping(), m)
+	})
+
+	t.Run("extra fields and overridden default fields", func(t *testing.T) {
+		m, err := ParseFieldMapping("priority=priority, assignment_group=assignment_group.name,location=site.name,updated_at=modified")
+		require.NoError(t, err)
+
+		assert.Equal(t, "site.name", m.Location)
+		assert.Equal(t, "modified", m.UpdatedAt)
+		assert.Equal(t, []Field{
+			{Key: "priority", Label: "Priority", Path: "priority"},
+			{Key: "assignment_group", Label: "Assignment group", Path: "assignment_group.name"},
+		}, m.Extra)
+		assert.Equal(t, []string{"Priority", "Assignment group"}, m.ExtraLabels())
+
+		assert.Equal(t, []string{
+			"uuid", "number", "assigned_to", "short_description", "state_id", "site", "location_custom", "created_at",
+			"priority", "assignment_group",
+		}, m.RequestedFields())
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		_, err := ParseFieldMapping("priority")
+		assert.Error(t, err)
+
+		_, err = ParseFieldMapping("priority=")
+		assert.Error(t, err)
+
+		_, err = ParseFieldMapping("priority=priority,priority=urgency")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,36 @@
+package ticket
+
+// MergeUpdatedTickets returns the previous list of open tickets updated by the tickets changed since then.
+// Updated open tickets replace the previous ones or are appended as new, updated tickets that are not open anymore
+// (by the state model) are removed. Tickets are matched by their Key.
+func MergeUpdatedTickets(previous, updated List, states StateModel) List {
+	updatedByKey := indexByKey(updated)
+
+	merged := make(List, 0, len(previous)+len(updated))
+	seen := make(map[string]bool, len(previous))
+
+	for _, t := range previous {
+		key := t.Key()
+		seen[key] = true
+
+		u, ok := updatedByKey[key]
+		switch {
+		case !ok:
+			merged = append(merged, t)
+		case states.IsOpen(u.TicketData.StateID):
+			merged = append(merged, u)
+		}
+	}
+
+	for _, u := range updated {
+		key := u.Key()
+		if seen[key] || !states.IsOpen(u.TicketData.StateID) {
+			continue
+		}
+		seen[key] = true
+
+		merged = append(merged, u)
+	}
+
+	return merged
+}
@@ -0,0 +1,44 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeUpdatedTickets(t *testing.T) {
+	newTicket := func(number, description string, stateID int) Ticket {
+		return Ticket{
+			ChannelID:  "ch1",
+			TicketType: "Incident",
+			TicketData: Data{Number: number, ShortDescription: description, StateID: stateID},
+		}
+	}
+
+	previous := List{
+		newTicket("INC1", "unchanged", 0),
+		newTicket("INC2", "before update", 1),
+		newTicket("INC3", "will be resolved", 1),
+		newTicket("INC4", "will be closed", 2),
+	}
+
+	updated := List{
+		newTicket("INC5", "new", 0),
+		newTicket("INC2", "after update", 2),
+		newTicket("INC3", "resolved", 4),
+		newTicket("INC4", "closed", 5),
+		newTicket("INC6", "created and cancelled", 6),
+	}
+
+	merged := MergeUpdatedTickets(previous, updated, DefaultStateModel())
+
+	assert.Equal(t, List{
+		newTicket("INC1", "unchanged", 0),
+		newTicket("INC2", "after update", 2),
+		newTicket("INC5", "new", 0),
+	}, merged)
+
+	t.Run("without updates", func(t *testing.T) {
+		assert.Equal(t, previous, MergeUpdatedTickets(previous, nil, DefaultStateModel()))
+	})
+}
@@ -0,0 +1,98 @@
+package ticket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewTicket converts the record of the record type to the ticket according to the field mapping. The state is resolved
+// from the state model, the link to the ticket is created from the URL templates.
+func (m FieldMapping) NewTicket(
+	record map[string]interface{}, recordType RecordType, states StateModel, channelID, channelName string,
+	urlTemplates URLTemplates,
+) (Ticket, error) {
+	location := FieldValue(record, m.Location)
+	if locationCustom := FieldValue(record, m.LocationCustom); locationCustom != "" {
+		location = locationCustom // if custom location is filled in, we use custom location
+	}
+
+	var stateID int
+	if state := FieldValue(record, m.StateID); state != "" {
+		var err error
+		stateID, err = strconv.Atoi(state)
+		if err != nil {
+			return Ticket{}, fmt.Errorf("invalid state '%s' of ticket '%s'", state, FieldValue(record, m.Number))
+		}
+	}
+
+	ticketType := recordType.DisplayName
+	if ticketType == "" {
+		ticketType = FieldValue(record, "docType")
+	}
+
+	var fields map[string]string
+	if len(m.Extra) > 0 {
+		fields = make(map[string]string, len(m.Extra))
+		for _, f := range m.Extra {
+			fields[f.Key] = FieldValue(record, f.Path)
+		}
+	}
+
+	data := Data{
+		UUID:             FieldValue(record, m.UUID),
+		Number:           FieldValue(record, m.Number),
+		ShortDescription: FieldValue(record, m.ShortDescription),
+		StateID:          stateID,
+		State:            states.Name(stateID),
+		Location:         location,
+		CreatedAt:        FieldValue(record, m.CreatedAt),
+		Fields:           fields,
+	}
+	data.URL = urlTemplates.URL(recordType, channelID, data)
+
+	return Ticket{
+		UserID:          FieldValue(record, m.AssignedTo),
+		ChannelID:       channelID,
+		ChannelName:     channelName,
+		TicketType:      ticketType,
+		RecordType:      recordType.Name,
+		TicketTypeOrder: recordType.SortOrder,
+		TicketData:      data,
+	}, nil
+}
+
+// FieldValue returns string representation of the value addressed by dot separated path in the decoded record.
+// It returns empty string if the value does not exist.
+func FieldValue(record map[string]interface{}, path string) string {
+	var value interface{} = record
+
+	for _, name := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = obj[name]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		// objects and arrays are returned JSON encoded
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
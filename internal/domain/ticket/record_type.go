@@ -0,0 +1,56 @@
+package ticket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecordType is a type of ITSM records downloaded as tickets (e.g. incident, k_request, problem)
+type RecordType struct {
+	// Name of the record type in ITSM, it is used in the endpoint path (e.g. "k_request")
+	Name string
+	// DisplayName is shown in the reports; when empty, document type returned by ITSM is shown
+	DisplayName string
+	// SortOrder of tickets of this record type in the reports, lower goes first
+	SortOrder int
+}
+
+// ParseRecordTypes parses comma separated list of record types in the form "name[=Display name]",
+// e.g. "incident=Incident,k_request=Request,problem". Sort order is given by the position in the list.
+func ParseRecordTypes(definition string) ([]RecordType, error) {
+	var recordTypes []RecordType
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(definition, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+
+		rt := RecordType{
+			Name:      strings.TrimSpace(kv[0]),
+			SortOrder: len(recordTypes),
+		}
+		if len(kv) == 2 {
+			rt.DisplayName = strings.TrimSpace(kv[1])
+		}
+
+		if rt.Name == "" {
+			return nil, fmt.Errorf("invalid record type '%s', name must not be empty", item)
+		}
+		if seen[rt.Name] {
+			return nil, fmt.Errorf("duplicate record type '%s'", rt.Name)
+		}
+		seen[rt.Name] = true
+
+		recordTypes = append(recordTypes, rt)
+	}
+
+	if len(recordTypes) == 0 {
+		return nil, fmt.Errorf("no record types defined")
+	}
+
+	return recordTypes, nil
+}
@@ -0,0 +1,28 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecordTypes(t *testing.T) {
+	recordTypes, err := ParseRecordTypes("incident=Incident, k_request=Request,problem")
+	require.NoError(t, err)
+
+	assert.Equal(t, []RecordType{
+		{Name: "incident", DisplayName: "Incident", SortOrder: 0},
+		{Name: "k_request", DisplayName: "Request", SortOrder: 1},
+		{Name: "problem", DisplayName: "", SortOrder: 2},
+	}, recordTypes)
+
+	_, err = ParseRecordTypes("")
+	assert.Error(t, err, "at least one record type must be defined")
+
+	_, err = ParseRecordTypes("incident,incident=Incident")
+	assert.Error(t, err, "duplicate record types are not allowed")
+
+	_, err = ParseRecordTypes("=Incident")
+	assert.Error(t, err, "record type name must not be empty")
+}
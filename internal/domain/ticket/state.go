@@ -0,0 +1,140 @@
+package ticket
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// State of the ITSM ticket
+type State struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Open tickets are downloaded and reported, the others (resolved, closed...) are not
+	Open bool `json:"open"`
+}
+
+// StateModel is a list of states of the record type
+type StateModel []State
+
+// DefaultStateModel returns the states used when no other state model is configured
+func DefaultStateModel() StateModel {
+	return StateModel{
+		{ID: 0, Name: "New", Open: true},
+		{ID: 1, Name: "Assigned", Open: true},
+		{ID: 2, Name: "In progress", Open: true},
+		{ID: 3, Name: "On Hold", Open: true},
+		{ID: 4, Name: "Resolved", Open: false},
+		{ID: 5, Name: "Closed", Open: false},
+		{ID: 6, Name: "Cancelled", Open: false},
+	}
+}
+
+// ParseStateModel parses comma separated list of states in the form "id=Name[:closed]",
+// e.g. "0=New,1=Assigned,2=In progress,3=On Hold,4=Resolved:closed,5=Closed:closed".
+// States not marked as closed are considered open.
+func ParseStateModel(definition string) (StateModel, error) {
+	var model StateModel
+	seen := make(map[int]bool)
+
+	for _, item := range strings.Split(definition, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid state '%s', expected 'id=Name[:closed]'", item)
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(kv[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid state '%s', state ID must be a number", item)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate state ID %d", id)
+		}
+		seen[id] = true
+
+		state := State{ID: id, Name: strings.TrimSpace(kv[1]), Open: true}
+		if strings.HasSuffix(state.Name, ":closed") {
+			state.Name = strings.TrimSpace(strings.TrimSuffix(state.Name, ":closed"))
+			state.Open = false
+		}
+
+		if state.Name == "" {
+			return nil, fmt.Errorf("invalid state '%s', name must not be empty", item)
+		}
+
+		model = append(model, state)
+	}
+
+	if len(model) == 0 {
+		return nil, fmt.Errorf("no states defined")
+	}
+
+	return model, nil
+}
+
+// Name returns name of the state with the given ID
+func (m StateModel) Name(stateID int) string {
+	for _, s := range m {
+		if s.ID == stateID {
+			return s.Name
+		}
+	}
+
+	return "Unknown"
+}
+
+// IsOpen returns true if the state with the given ID is open. Unknown states are considered open.
+func (m StateModel) IsOpen(stateID int) bool {
+	for _, s := range m {
+		if s.ID == stateID {
+			return s.Open
+		}
+	}
+
+	return true
+}
+
+// ClosedStateIDs returns IDs of the states that are not open
+func (m StateModel) ClosedStateIDs() []int {
+	var ids []int
+	for _, s := range m {
+		if !s.Open {
+			ids = append(ids, s.ID)
+		}
+	}
+
+	return ids
+}
+
+// StateCatalogue contains state models of the record types
+type StateCatalogue struct {
+	// Default state model is used for record types without their own state model
+	Default StateModel
+	// RecordTypes contains state models keyed by record type name
+	RecordTypes map[string]StateModel
+}
+
+// DefaultStateCatalogue returns catalogue with the default state model for all record types
+func DefaultStateCatalogue() StateCatalogue {
+	return StateCatalogue{
+		Default: DefaultStateModel(),
+	}
+}
+
+// Model returns state model of the record type
+func (c StateCatalogue) Model(recordTypeName string) StateModel {
+	if m, ok := c.RecordTypes[recordTypeName]; ok {
+		return m
+	}
+
+	if c.Default != nil {
+		return c.Default
+	}
+
+	return DefaultStateModel()
+}
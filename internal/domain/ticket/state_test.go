@@ -0,0 +1,58 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStateModel(t *testing.T) {
+	model, err := ParseStateModel("0=New, 1=Assigned,2=In progress,4=Resolved:closed,5=Closed:closed")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Assigned", model.Name(1))
+	assert.Equal(t, "Closed", model.Name(5))
+	assert.Equal(t, "Unknown", model.Name(9))
+
+	assert.True(t, model.IsOpen(2))
+	assert.False(t, model.IsOpen(4))
+	assert.Equal(t, []int{4, 5}, model.ClosedStateIDs())
+
+	_, err = ParseStateModel("x=New")
+	assert.Error(t, err)
+
+	_, err = ParseStateModel("0=New,0=Open")
+	assert.Error(t, err)
+
+	_, err = ParseStateModel("")
+	assert.Error(t, err)
+}
+
+func TestStateCatalogue_Model(t *testing.T) {
+	problemStates := StateModel{{ID: 0, Name: "Identified", Open: true}, {ID: 9, Name: "Fixed", Open: false}}
+
+	c := StateCatalogue{
+		Default:     DefaultStateModel(),
+		RecordTypes: map[string]StateModel{"problem": problemStates},
+	}
+
+	assert.Equal(t, problemStates, c.Model("problem"))
+	assert.Equal(t, DefaultStateModel(), c.Model("incident"))
+	assert.Equal(t, DefaultStateModel(), StateCatalogue{}.Model("incident"))
+}
+
+func TestDefaultStateModel(t *testing.T) {
+	model := DefaultStateModel()
+
+	seen := make(map[string]int)
+	for _, s := range model {
+		prev, ok := seen[s.Name]
+		assert.False(t, ok, "state name '%s' is used by states %d and %d", s.Name, prev, s.ID)
+		seen[s.Name] = s.ID
+	}
+
+	assert.Equal(t, "Assigned", model.Name(1))
+	assert.Equal(t, "On Hold", model.Name(3))
+	assert.Equal(t, []int{4, 5, 6}, model.ClosedStateIDs())
+}
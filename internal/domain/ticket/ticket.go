@@ -1,7 +1,6 @@
 package ticket
 
 import (
-	"fmt"
 	"time"
 )
 
@@ -11,51 +10,77 @@ type Ticket struct {
 	UserEmail   string
 	UserName    string
 	UserOrgName string
+	// UserType is the type of the assignee in the user directory (e.g. engineer, API user)
+	UserType    string
 	ChannelID   string
 	ChannelName string
 	TicketType  string
-	TicketData  Data
+	// RecordType is the name of the ticket's record type (e.g. incident), TicketType is its display name
+	RecordType string
+	// TicketTypeOrder is the sort order of the ticket's record type in the reports
+	TicketTypeOrder int
+	TicketData      Data
 }
 
 // List of tickets
 type List []Ticket
 
+// Scope identifies the tickets of the record type in the channel, e.g. the part of the tickets covered by a download
+type Scope struct {
+	ChannelID string
+	// RecordType is the name of the record type
+	RecordType string
+}
+
+// UnknownAssignee is shown in the reports instead of the name of the assignee missing in the user directory
+const UnknownAssignee = "Unknown assignee"
+
+// HasUnknownAssignee returns true if the ticket is assigned to the user missing in the user directory
+func (t Ticket) HasUnknownAssignee() bool {
+	return t.UserID != "" && t.UserEmail == "" && t.UserName == ""
+}
+
+// AssigneeType returns the user type of the assignee of the tickets, empty if it is not known.
+// The tickets are expected to be assigned to the same user.
+func AssigneeType(list List) string {
+	for _, t := range list {
+		if t.UserType != "" {
+			return t.UserType
+		}
+	}
+	return ""
+}
+
 // Data contain all relevant info about the ITSM ticket
 type Data struct {
+	UUID             string
 	Number           string
 	ShortDescription string
 	StateID          int
+	State            string // state name resolved from the state model of the ticket's record type
 	Location         string
 	CreatedAt        string
+	URL              string // link to the ticket in the ITSM UI, empty if no URL template is configured
+
+	// Fields contains values of the extra fields configured in FieldMapping, keyed by Field.Key
+	Fields map[string]string
 }
 
-// StateName converts state ID to its name
+// StateName returns name of the ticket state
 func (d Data) StateName() string {
-	states := [...]string{
-		"New",         // 0
-		"On Hold",     // 1
-		"In progress", // 2
-		"On Hold",     // 3
-		"Resolved",    // 4
-		"Closed",      // 5
-		"Cancelled",   // 6
-	}
-
-	if d.StateID >= len(states) {
-		return "Unknown"
+	if d.State != "" {
+		return d.State
 	}
 
-	return states[d.StateID]
+	return DefaultStateModel().Name(d.StateID)
 }
 
-// CreatedAtDate returns date of the ticket creation
-func (d Data) CreatedAtDate() string {
-	datetime, err := time.Parse(time.RFC3339, d.CreatedAt)
-	if err != nil {
-		return err.Error()
-	}
-
-	year, month, day := datetime.UTC().Date()
+// Field returns value of the extra field with the specified key
+func (d Data) Field(key string) string {
+	return d.Fields[key]
+}
 
-	return fmt.Sprintf("%d/%d/%d", day, month, year)
+// CreatedAtTime returns time of the ticket creation
+func (d Data) CreatedAtTime() (time.Time, error) {
+	return time.Parse(time.RFC3339, d.CreatedAt)
 }
@@ -0,0 +1,75 @@
+package ticket
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLTemplates define links to the tickets in the ITSM UI.
+// Templates can contain placeholders {base}, {channel}, {record_type}, {uuid} and {number},
+// e.g. "{base}/{channel}/incident/{uuid}".
+type URLTemplates struct {
+	// BaseURL replaces the {base} placeholder
+	BaseURL string
+	// Default template is used for record types and channels without their own template
+	Default string
+	// RecordTypes contains templates keyed by record type name
+	RecordTypes map[string]string
+	// Channels contains templates keyed by channel ID, they take precedence over the record type templates
+	Channels map[string]string
+}
+
+// ParseChannelURLTemplates parses comma separated list of "channelID=template" pairs
+func ParseChannelURLTemplates(definition string) (map[string]string, error) {
+	templates := make(map[string]string)
+
+	for _, item := range strings.Split(definition, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid channel URL template '%s', expected 'channelID=template'", item)
+		}
+
+		channelID := strings.TrimSpace(kv[0])
+		template := strings.TrimSpace(kv[1])
+		if channelID == "" || template == "" {
+			return nil, fmt.Errorf("invalid channel URL template '%s', channel ID and template must not be empty", item)
+		}
+
+		if _, ok := templates[channelID]; ok {
+			return nil, fmt.Errorf("duplicate URL template for channel '%s'", channelID)
+		}
+		templates[channelID] = template
+	}
+
+	return templates, nil
+}
+
+// URL returns link to the ticket of the record type in the channel.
+// It returns empty string if there is no template or the ticket has no UUID.
+func (u URLTemplates) URL(recordType RecordType, channelID string, data Data) string {
+	template, ok := u.Channels[channelID]
+	if !ok {
+		template, ok = u.RecordTypes[recordType.Name]
+	}
+	if !ok {
+		template = u.Default
+	}
+
+	if template == "" || data.UUID == "" {
+		return ""
+	}
+
+	return strings.NewReplacer(
+		"{base}", strings.TrimSuffix(u.BaseURL, "/"),
+		"{channel}", url.PathEscape(channelID),
+		"{record_type}", url.PathEscape(recordType.Name),
+		"{uuid}", url.PathEscape(data.UUID),
+		"{number}", url.PathEscape(data.Number),
+	).Replace(template)
+}
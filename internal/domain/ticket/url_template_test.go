@@ -0,0 +1,37 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLTemplates_URL(t *testing.T) {
+	channels, err := ParseChannelURLTemplates("ch2=https://other.test/tickets/{number}")
+	require.NoError(t, err)
+
+	templates := URLTemplates{
+		BaseURL:     "https://itsm.test/",
+		Default:     "{base}/{channel}/{record_type}/{uuid}",
+		RecordTypes: map[string]string{"k_request": "{base}/{channel}/request/{uuid}"},
+		Channels:    channels,
+	}
+
+	incident := RecordType{Name: "incident"}
+	request := RecordType{Name: "k_request"}
+	data := Data{UUID: "8a1a2c5e-7f0f-4a40-a4c2-6b3f63e0d1a1", Number: "INC 1"}
+
+	assert.Equal(t, "https://itsm.test/ch1/incident/8a1a2c5e-7f0f-4a40-a4c2-6b3f63e0d1a1", templates.URL(incident, "ch1", data))
+	assert.Equal(t, "https://itsm.test/ch1/request/8a1a2c5e-7f0f-4a40-a4c2-6b3f63e0d1a1", templates.URL(request, "ch1", data))
+	assert.Equal(t, "https://other.test/tickets/INC%201", templates.URL(request, "ch2", data), "channel template takes precedence")
+
+	assert.Equal(t, "", templates.URL(incident, "ch1", Data{Number: "INC2"}), "no link without uuid")
+	assert.Equal(t, "", URLTemplates{}.URL(incident, "ch1", data), "no link without template")
+
+	_, err = ParseChannelURLTemplates("ch1")
+	assert.Error(t, err)
+
+	_, err = ParseChannelURLTemplates("ch1=a,ch1=b")
+	assert.Error(t, err)
+}
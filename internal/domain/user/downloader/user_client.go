@@ -3,7 +3,6 @@ package userdownloader
 import (
 	"context"
 	"encoding/json"
-	"strings"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
@@ -20,53 +19,41 @@ type UserClient interface {
 	Close() error
 }
 
-func NewUserClient(client client.Client) UserClient {
+// NewUserClient returns client that downloads the users page by page according to pageConfig
+func NewUserClient(c client.Client, pageConfig client.PageConfig) UserClient {
 	return &userClient{
-		Client: client,
+		Client:    c,
+		paginator: client.NewPaginator(c, pageConfig),
 	}
 }
 
 type userClient struct {
 	client.Client
+	paginator client.Paginator
 }
 
 func (c userClient) GetUsers(ctx context.Context, channel channel.Channel) (user.List, error) {
 	var userList user.List
-	var bookmark string
 
-	for {
-		payload := `{"fields":["uuid","full_name","email","type","org_display_name"],"bookmark":"` + bookmark + `"}`
-		body := strings.NewReader(payload)
-		resp, err := c.Query(ctx, channel.ChannelID, body)
-		if err != nil {
-			return userList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about users")
-		}
+	query := map[string]interface{}{
+		"fields": []string{"uuid", "full_name", "email", "type", "org_display_name"},
+	}
 
-		type OKPayload struct {
-			Bookmark string `json:"bookmark"`
-			Result   []struct {
+	err := c.paginator.Query(ctx, channel.ChannelID, query, func(records []json.RawMessage) error {
+		for _, record := range records {
+			var v struct {
 				ID      string `json:"uuid"`
 				Name    string `json:"full_name"`
 				Email   string `json:"email"`
 				Type    string `json:"type"`
 				OrgName string `json:"org_display_name"`
-			} `json:"result"`
-		}
-		var okPayload OKPayload
-
-		if err = json.NewDecoder(resp.Body).Decode(&okPayload); err != nil {
-			return userList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode user service Ok response")
-		}
-
-		_ = resp.Body.Close()
-
-		bookmark = okPayload.Bookmark
-
-		for _, v := range okPayload.Result {
-			if v.Email == "" { // this is in case of some data inconsistency in ITSM, we need to skip invalid users
-				continue
+			}
+			if err := json.Unmarshal(record, &v); err != nil {
+				return domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode user service Ok response")
 			}
 
+			// users without email address (data inconsistency in ITSM) are kept, so that the tickets assigned to them
+			// can still be reported with the assignee's name
 			userList = append(userList, user.User{
 				ChannelID: channel.ChannelID,
 				UserID:    v.ID,
@@ -78,9 +65,10 @@ func (c userClient) GetUsers(ctx context.Context, channel channel.Channel) (user
 			)
 		}
 
-		if len(okPayload.Result) < 10 {
-			break
-		}
+		return nil
+	})
+	if err != nil {
+		return userList, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not retrieve info about users")
 	}
 
 	return userList, nil
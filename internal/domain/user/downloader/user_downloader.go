@@ -2,67 +2,186 @@ package userdownloader
 
 import (
 	"context"
+	"sync"
+	"time"
 
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
 	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
 	"go.uber.org/zap"
 )
 
 // UserDownloader downloads list of users from the ITSM service
 type UserDownloader interface {
-	// DownloadUsers downloads and stores list of users from the ITSM service
+	// DownloadUsers downloads and stores list of users from the ITSM service. With the cache enabled, users of the
+	// channels downloaded within the cache TTL are not downloaded again.
 	DownloadUsers(ctx context.Context) error
 
-	// Reset removes all items from downloader repository
+	// InvalidateCache marks cached users of the channel (of all channels if channelID is empty) as expired,
+	// so they are downloaded again by the next job
+	InvalidateCache(ctx context.Context, channelID string) error
+
+	// RefreshCache downloads users of the channel (of all channels if channelID is empty) immediately
+	RefreshCache(ctx context.Context, channelID string) error
+
+	// Reset removes all items from downloader repository, unless the cache is enabled
 	Reset(ctx context.Context) error
 
 	// Close closes client connections
 	Close() error
 }
 
+// NewUserDownloader returns user downloader. If cacheTTL is not zero, downloaded users are kept across the jobs
+// and the users of each channel are downloaded again only after cacheTTL.
 func NewUserDownloader(
-	logger *zap.SugaredLogger, channelRepository repository.ChannelRepository,
-	userRepository repository.UserRepository, client UserClient,
+	logger *zap.SugaredLogger, clock repository.Clock, channelRepository repository.ChannelRepository,
+	userRepository repository.UserRepository, client UserClient, cacheTTL time.Duration,
 ) UserDownloader {
 	return &userDownloader{
 		logger:            logger,
+		clock:             clock,
 		client:            client,
 		channelRepository: channelRepository,
 		userRepository:    userRepository,
+		cacheTTL:          cacheTTL,
+		downloadedAt:      make(map[string]time.Time),
 	}
 }
 
 type userDownloader struct {
 	logger            *zap.SugaredLogger
+	clock             repository.Clock
 	client            UserClient
 	channelRepository repository.ChannelRepository
 	userRepository    repository.UserRepository
+	cacheTTL          time.Duration
+	// downloadedAt contains the time of the last download of the users keyed by channel ID
+	downloadedAt map[string]time.Time
+	mu           sync.Mutex
 }
 
 func (d *userDownloader) DownloadUsers(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	channels, err := d.channelRepository.GetChannelList(ctx)
 	if err != nil {
 		return err
 	}
 
+	current := make(map[string]bool, len(channels))
+
 	for _, channel := range channels {
-		d.logger.Infow("Downloading users from the channel", "channel", channel.Name)
+		current[channel.ChannelID] = true
 
-		userList, err := d.client.GetUsers(ctx, channel)
-		if err != nil {
+		if d.isCached(channel.ChannelID) {
+			d.logger.Infow("Users from the channel are cached, download skipped", "channel", channel.Name)
+			continue
+		}
+
+		if err := d.downloadChannelUsers(ctx, channel); err != nil {
 			return err
 		}
+	}
+
+	// users of the channels that do not exist anymore are removed
+	for channelID := range d.downloadedAt {
+		if current[channelID] {
+			continue
+		}
+
+		if err := d.userRepository.ReplaceChannelUsers(ctx, channelID, nil); err != nil {
+			return err
+		}
+		delete(d.downloadedAt, channelID)
+	}
+
+	return nil
+}
+
+func (d *userDownloader) InvalidateCache(_ context.Context, channelID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// users are kept in the repository until they are downloaded again, so the running job can still use them
+	if channelID == "" {
+		d.downloadedAt = make(map[string]time.Time)
+	} else {
+		delete(d.downloadedAt, channelID)
+	}
+
+	d.logger.Infow("User cache invalidated", "channel", channelID)
+
+	return nil
+}
+
+func (d *userDownloader) RefreshCache(ctx context.Context, channelID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	channels, err := d.channelRepository.GetChannelList(ctx)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, channel := range channels {
+		if channelID != "" && channel.ChannelID != channelID {
+			continue
+		}
+		found = true
 
-		if err := d.userRepository.AddUserList(ctx, userList); err != nil {
+		if err := d.downloadChannelUsers(ctx, channel); err != nil {
 			return err
 		}
+	}
+
+	if channelID != "" && !found {
+		return domain.NewErrorf(domain.ErrorCodeNotFound, "channel '%s' not found", channelID)
+	}
+
+	return nil
+}
+
+// downloadChannelUsers replaces the users of the channel in the repository with the downloaded ones
+func (d *userDownloader) downloadChannelUsers(ctx context.Context, channel channel.Channel) (err error) {
+	ctx, span := tracing.Start(ctx, "download channel users", tracing.ChannelIDKey.String(channel.ChannelID))
+	defer func() { tracing.End(span, err) }()
 
-		d.logger.Infow("Users from the channel successfully downloaded", "channel", channel.Name, "users found", len(userList))
+	d.logger.Infow("Downloading users from the channel", "channel", channel.Name)
+
+	userList, err := d.client.GetUsers(ctx, channel)
+	if err != nil {
+		return err
 	}
 
+	if err := d.userRepository.ReplaceChannelUsers(ctx, channel.ChannelID, userList); err != nil {
+		return err
+	}
+	d.downloadedAt[channel.ChannelID] = d.clock.Now()
+
+	d.logger.Infow("Users from the channel successfully downloaded", "channel", channel.Name, "users found", len(userList))
+
 	return nil
 }
 
+// isCached returns true if the users of the channel were downloaded within the cache TTL
+func (d *userDownloader) isCached(channelID string) bool {
+	downloadedAt, ok := d.downloadedAt[channelID]
+	return ok && d.cacheTTL > 0 && d.clock.Now().Sub(downloadedAt) < d.cacheTTL
+}
+
 func (d *userDownloader) Reset(ctx context.Context) error {
+	if d.cacheTTL > 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.downloadedAt = make(map[string]time.Time)
+
 	return d.userRepository.Truncate(ctx)
 }
 
@@ -0,0 +1,103 @@
+package userdownloader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/user"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserDownloader_Cache(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	ctx := context.Background()
+
+	ch1 := channel.Channel{ChannelID: "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01", Name: "First channel"}
+	ch2 := channel.Channel{ChannelID: "5a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c02", Name: "Second channel"}
+	u1 := user.User{ChannelID: ch1.ChannelID, UserID: "2daf8a6e-3b3c-4a4f-9e0e-2c7b5b1c1a01", Email: "joe@email.test"}
+	u2 := user.User{ChannelID: ch2.ChannelID, UserID: "7c9e6679-7425-40de-944b-e07fc1f90a02", Email: "jan@email.test"}
+
+	channelRepository := memory.NewChannelRepositoryMemory()
+	require.NoError(t, channelRepository.StoreChannelList(ctx, channel.List{ch1, ch2}))
+	userRepository := memory.NewUserRepositoryMemory()
+
+	userClient := new(mocks.UserClientMock)
+	d := NewUserDownloader(logger, mocks.NewFixedClock(), channelRepository, userRepository, userClient, time.Hour)
+
+	// the first job downloads users of all channels
+	userClient.On("GetUsers", ch1).Return(user.List{u1}, nil).Once()
+	userClient.On("GetUsers", ch2).Return(user.List{u2}, nil).Once()
+	require.NoError(t, d.Reset(ctx))
+	require.NoError(t, d.DownloadUsers(ctx))
+	userClient.AssertExpectations(t)
+
+	// the next job uses the cached users
+	require.NoError(t, d.Reset(ctx))
+	require.NoError(t, d.DownloadUsers(ctx))
+	userClient.AssertNumberOfCalls(t, "GetUsers", 2)
+
+	retUser, err := userRepository.GetUserInChannel(ctx, ch2.ChannelID, u2.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, u2, retUser)
+
+	// invalidated channel is downloaded again by the next job
+	require.NoError(t, d.InvalidateCache(ctx, ch2.ChannelID))
+	userClient.On("GetUsers", ch2).Return(user.List{}, nil).Once()
+	require.NoError(t, d.DownloadUsers(ctx))
+	userClient.AssertNumberOfCalls(t, "GetUsers", 3)
+
+	_, err = userRepository.GetUserInChannel(ctx, ch2.ChannelID, u2.UserID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	// refresh downloads the users immediately
+	userClient.On("GetUsers", ch1).Return(user.List{u1}, nil).Once()
+	require.NoError(t, d.RefreshCache(ctx, ch1.ChannelID))
+	userClient.AssertNumberOfCalls(t, "GetUsers", 4)
+
+	err = d.RefreshCache(ctx, "nonexistentID")
+	var dErr *domain.Error
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, domain.ErrorCodeNotFound, dErr.Code())
+}
+
+func TestUserDownloader_CacheTTL(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	ctx := context.Background()
+
+	ch1 := channel.Channel{ChannelID: "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01", Name: "First channel"}
+	u1 := user.User{ChannelID: ch1.ChannelID, UserID: "2daf8a6e-3b3c-4a4f-9e0e-2c7b5b1c1a01", Email: "joe@email.test"}
+
+	channelRepository := memory.NewChannelRepositoryMemory()
+	require.NoError(t, channelRepository.StoreChannelList(ctx, channel.List{ch1}))
+
+	clock := mocks.NewFixedClock()
+	userClient := new(mocks.UserClientMock)
+	userClient.On("GetUsers", ch1).Return(user.List{u1}, nil)
+
+	d := NewUserDownloader(logger, clock, channelRepository, memory.NewUserRepositoryMemory(), userClient, time.Hour)
+
+	require.NoError(t, d.DownloadUsers(ctx))
+	userClient.AssertNumberOfCalls(t, "GetUsers", 1)
+
+	// cached users are used until the TTL expires
+	clock.AddTime(time.Hour - time.Second)
+	require.NoError(t, d.DownloadUsers(ctx))
+	userClient.AssertNumberOfCalls(t, "GetUsers", 1)
+
+	clock.AddTime(time.Second)
+	require.NoError(t, d.DownloadUsers(ctx))
+	userClient.AssertNumberOfCalls(t, "GetUsers", 2)
+
+	// TTL is measured from the last download
+	clock.AddTime(30 * time.Minute)
+	require.NoError(t, d.DownloadUsers(ctx))
+	userClient.AssertNumberOfCalls(t, "GetUsers", 2)
+}
@@ -0,0 +1,75 @@
+package api
+
+// ChannelConfig API object
+// swagger:model
+type ChannelConfig struct {
+	// ID of the channel (ITSM sub-space)
+	// required: true
+	ChannelID string `json:"channel_id"`
+
+	// Enabled channels are processed by the jobs, disabled channels are skipped
+	// required: true
+	Enabled bool `json:"enabled"`
+
+	// Name shown in the reports instead of the name from ITSM
+	// example: Service Desk EU
+	DisplayName string `json:"display_name,omitempty"`
+
+	// Timezone of the dates in the channel report
+	// example: Europe/Prague
+	Timezone string `json:"timezone,omitempty"`
+
+	// Email addresses of the channel owners receiving the channel report
+	OwnerEmails []string `json:"owner_emails,omitempty"`
+}
+
+// SaveChannelConfigParams is the payload used to create or replace channel configuration
+// swagger:model
+type SaveChannelConfigParams struct {
+	// Enabled channels are processed by the jobs, disabled channels are skipped (default true)
+	// example: true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Name shown in the reports instead of the name from ITSM
+	// example: Service Desk EU
+	DisplayName string `json:"display_name,omitempty"`
+
+	// Timezone of the dates in the channel report
+	// example: Europe/Prague
+	Timezone string `json:"timezone,omitempty"`
+
+	// Email addresses of the channel owners receiving the channel report
+	OwnerEmails []string `json:"owner_emails,omitempty" validate:"dive,email"`
+}
+
+// NOTE: Types defined below are purely for documentation purposes
+// these types are not used by any of the handlers
+
+// swagger:parameters GetChannelConfig SaveChannelConfig DeleteChannelConfig
+type channelConfigIDParameterWrapper struct {
+	// ID of the channel
+	// in: path
+	// required: true
+	ChannelID string `json:"channel_id"`
+}
+
+// swagger:parameters SaveChannelConfig
+type saveChannelConfigParameterWrapper struct {
+	// in: body
+	// required: true
+	Body SaveChannelConfigParams
+}
+
+// Configuration of the channel
+// swagger:response channelConfigResponse
+type channelConfigResponseWrapper struct {
+	// in: body
+	Body ChannelConfig
+}
+
+// A list of channel configurations
+// swagger:response channelConfigListResponse
+type channelConfigListResponseWrapper struct {
+	// in: body
+	Body []ChannelConfig
+}
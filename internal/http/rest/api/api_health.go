@@ -0,0 +1,36 @@
+package api
+
+// Health API object
+// swagger:model
+type Health struct {
+	// Overall status, 'degraded' if any external service endpoint is unavailable [ok|degraded]
+	// required: true
+	// example: ok
+	Status string `json:"status"`
+
+	// Circuit breakers of the external service endpoints
+	CircuitBreakers []CircuitBreaker `json:"circuit_breakers"`
+}
+
+// CircuitBreaker API object
+// swagger:model
+type CircuitBreaker struct {
+	// Endpoint of the external service
+	// required: true
+	Endpoint string `json:"endpoint"`
+
+	// State of the circuit breaker [closed|half-open|open]
+	// required: true
+	// example: closed
+	State string `json:"state"`
+}
+
+// NOTE: Types defined below are purely for documentation purposes
+// these types are not used by any of the handlers
+
+// Health of the service
+// swagger:response healthResponse
+type healthResponseWrapper struct {
+	// in: body
+	Body Health
+}
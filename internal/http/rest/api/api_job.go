@@ -79,16 +79,30 @@ type Job struct {
 
 	// Status of the finished job (success/error)
 	FinalStatus string `json:"final_status,omitempty"`
+
+	// Data-quality warnings found during the job, e.g. ticket assignees missing in the user directory
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Mode of the tickets download [full|incremental]
+	// example: incremental
+	TicketsDownloadMode string `json:"tickets_download_mode,omitempty"`
+
+	// Field engineers excluded from the reports by the recipient filter, with the reason
+	ExcludedRecipients []string `json:"excluded_recipients,omitempty"`
 }
 
 // CreateJobParams is the payload used to create new job
 // swagger:model
 type CreateJobParams struct {
-	// Type of the job [FE report only|SD report only|all]
+	// Type of the job [FE report only|SD report only|Channel report only|all]
 	// required: true
 	// example: all
 	// swagger:strfmt string
 	Type job.Type `json:"type"`
+
+	// Download all open tickets even if the incremental ticket download is enabled
+	// example: false
+	FullResync bool `json:"full_resync,omitempty"`
 }
 
 // NOTE: Types defined here are purely for documentation purposes
@@ -0,0 +1,39 @@
+package api
+
+// UpdatePreferencesParams is the form submitted from the preferences page
+type UpdatePreferencesParams struct {
+	// Frequency of the reports [daily|weekly]
+	Frequency string
+	// Weekday when the weekly report is sent (0 = Sunday)
+	Weekday int
+	// IDs of the channels, empty means all channels
+	Channels []string
+	// Names of the record types (e.g. incident), empty means all types
+	RecordTypes []string
+	// Language of the emails
+	Language string
+	// Do not send any reports
+	OptedOut bool
+}
+
+// NOTE: Types defined below are purely for documentation purposes
+// these types are not used by any of the handlers
+
+// swagger:parameters GetPreferences UpdatePreferences ConfirmUnsubscribe Unsubscribe
+type preferencesTokenParameterWrapper struct {
+	// Signed token identifying the recipient, it is part of the link sent in the report emails
+	// in: path
+	// required: true
+	Token string `json:"token"`
+}
+
+// HTML page with the preferences form
+// swagger:response preferencesPageResponse
+type preferencesPageResponseWrapper struct {
+	// in: body
+	Body string
+}
+
+// Bad Request
+// swagger:response errorResponse400
+type errorResponseWrapper400 errorResponseWrapper
@@ -0,0 +1,15 @@
+package api
+
+// NOTE: Types defined here are purely for documentation purposes
+// these types are not used by any of the handlers
+
+// swagger:parameters InvalidateUserCache RefreshUserCache
+type userCacheParameterWrapper struct {
+	// ID of the channel, users of all channels are affected if not set
+	// in: query
+	ChannelID string `json:"channel_id"`
+}
+
+// No Content
+// swagger:response noContentResponse
+type noContentResponseWrapper struct{}
@@ -0,0 +1,31 @@
+package converters
+
+import (
+	"net/http"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api/input_converters/validators"
+	"go.uber.org/zap"
+)
+
+// NewChannelConfigPayloadConverter creates a channel configuration input payload converting service
+func NewChannelConfigPayloadConverter(logger *zap.SugaredLogger, validator validators.PayloadValidator) ChannelConfigPayloadConverter {
+	return &channelConfigPayloadConverter{
+		BasePayloadConverter: NewBasePayloadConverter(logger, validator),
+	}
+}
+
+type channelConfigPayloadConverter struct {
+	*BasePayloadConverter
+}
+
+// ChannelConfigParamsFromBody converts JSON payload to api.SaveChannelConfigParams
+func (c channelConfigPayloadConverter) ChannelConfigParamsFromBody(r *http.Request) (api.SaveChannelConfigParams, error) {
+	var payload api.SaveChannelConfigParams
+
+	if err := c.unmarshalFromBody(r, &payload); err != nil {
+		return payload, err
+	}
+
+	return payload, nil
+}
@@ -20,3 +20,15 @@ type JobPayloadConverter interface {
 	// JobCreateParamsFromBody converts JSON payload to api.CreateJobParams
 	JobCreateParamsFromBody(r *http.Request) (api.CreateJobParams, error)
 }
+
+// ChannelConfigPayloadConverter provides conversion from JSON request body payload to object
+type ChannelConfigPayloadConverter interface {
+	// ChannelConfigParamsFromBody converts JSON payload to api.SaveChannelConfigParams
+	ChannelConfigParamsFromBody(r *http.Request) (api.SaveChannelConfigParams, error)
+}
+
+// PreferencesFormConverter provides conversion from the form submitted from the preferences page to object
+type PreferencesFormConverter interface {
+	// PreferencesParamsFromForm converts form values to api.UpdatePreferencesParams
+	PreferencesParamsFromForm(r *http.Request) (api.UpdatePreferencesParams, error)
+}
@@ -0,0 +1,41 @@
+package converters
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/presenters"
+)
+
+// NewPreferencesFormConverter creates a preferences form converting service
+func NewPreferencesFormConverter() PreferencesFormConverter {
+	return &preferencesFormConverter{}
+}
+
+type preferencesFormConverter struct{}
+
+// PreferencesParamsFromForm converts form values to api.UpdatePreferencesParams
+func (c preferencesFormConverter) PreferencesParamsFromForm(r *http.Request) (api.UpdatePreferencesParams, error) {
+	var params api.UpdatePreferencesParams
+
+	if err := r.ParseForm(); err != nil {
+		return params, presenters.WrapErrorf(err, http.StatusBadRequest, "Request body contains badly-formed form")
+	}
+
+	params.Frequency = r.PostForm.Get("frequency")
+	params.Channels = r.PostForm["channels"]
+	params.RecordTypes = r.PostForm["record_types"]
+	params.Language = r.PostForm.Get("language")
+	params.OptedOut = r.PostForm.Get("opted_out") != ""
+
+	if weekday := r.PostForm.Get("weekday"); weekday != "" {
+		w, err := strconv.Atoi(weekday)
+		if err != nil {
+			return params, presenters.NewErrorf(http.StatusBadRequest, "Request body contains an invalid value for the 'weekday' field (value: %s)", weekday)
+		}
+		params.Weekday = w
+	}
+
+	return params, nil
+}
@@ -82,8 +82,8 @@ func validateJobType(sl validator.StructLevel) {
 		return
 	}
 
-	if s != job.TypeFE.String() && s != job.TypeSD.String() && s != job.TypeAll.String() {
-		param := fmt.Sprintf("'%s' '%s' '%s'", job.TypeFE, job.TypeSD, job.TypeAll)
+	if s != job.TypeFE.String() && s != job.TypeSD.String() && s != job.TypeChannel.String() && s != job.TypeAll.String() {
+		param := fmt.Sprintf("'%s' '%s' '%s' '%s'", job.TypeFE, job.TypeSD, job.TypeChannel, job.TypeAll)
 		sl.ReportError(s, "type", "v", "oneof", param)
 	}
 }
@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const channelConfigsRoute = "/channel-configs"
+
+// swagger:route GET /channel-configs channels ListChannelConfigs
+// Returns configurations of all configured channels
+// responses:
+//	200: channelConfigListResponse
+
+// ListChannelConfigs returns handler for listing channel configurations
+func (s *Server) ListChannelConfigs() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		configs, err := s.channelConfigService.ListChannelConfigs(r.Context())
+		if err != nil {
+			s.logger.Errorw("ListChannelConfigs handler failed", "error", err)
+			s.channelConfigPresenter.RenderError(w, "", err)
+			return
+		}
+
+		s.channelConfigPresenter.RenderChannelConfigList(w, configs)
+	}
+}
+
+// swagger:route GET /channel-configs/{channel_id} channels GetChannelConfig
+// Returns configuration of the channel
+// responses:
+//	200: channelConfigResponse
+//	404: errorResponse404
+
+// GetChannelConfig returns handler for getting single channel configuration
+func (s *Server) GetChannelConfig() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		c, err := s.channelConfigService.GetChannelConfig(r.Context(), params.ByName("channel_id"))
+		if err != nil {
+			s.logger.Warnw("GetChannelConfig handler failed", "error", err)
+			s.channelConfigPresenter.RenderError(w, "", err)
+			return
+		}
+
+		s.channelConfigPresenter.RenderChannelConfig(w, c)
+	}
+}
+
+// swagger:route PUT /channel-configs/{channel_id} channels SaveChannelConfig
+// Creates or replaces configuration of the channel, it is applied by the next job
+// responses:
+//	200: channelConfigResponse
+//	400: errorResponse400
+
+// SaveChannelConfig returns handler for creating or replacing channel configuration
+func (s *Server) SaveChannelConfig() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		payload, err := s.channelConfigPayloadConverter.ChannelConfigParamsFromBody(r)
+		if err != nil {
+			s.logger.Warnw("SaveChannelConfig handler failed", "error", err)
+			s.channelConfigPresenter.RenderError(w, "", err)
+			return
+		}
+
+		c, err := s.channelConfigService.SaveChannelConfig(r.Context(), params.ByName("channel_id"), payload)
+		if err != nil {
+			s.logger.Warnw("SaveChannelConfig handler failed", "error", err)
+			s.channelConfigPresenter.RenderError(w, "", err)
+			return
+		}
+
+		s.channelConfigPresenter.RenderChannelConfig(w, c)
+	}
+}
+
+// swagger:route DELETE /channel-configs/{channel_id} channels DeleteChannelConfig
+// Removes configuration of the channel, the channel is then processed by default
+// responses:
+//	204: noContentResponse
+//	404: errorResponse404
+
+// DeleteChannelConfig returns handler for removing channel configuration
+func (s *Server) DeleteChannelConfig() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if err := s.channelConfigService.DeleteChannelConfig(r.Context(), params.ByName("channel_id")); err != nil {
+			s.logger.Warnw("DeleteChannelConfig handler failed", "error", err)
+			s.channelConfigPresenter.RenderError(w, "", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	chansvc "github.com/KompiTech/itsm-reporting-service/internal/domain/channel/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelConfigHandlers(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	server := NewServer(Config{
+		Addr:                    "service.url",
+		Logger:                  logger,
+		JobsProcessor:           new(mocks.JobProcessorMock),
+		ChannelConfigService:    chansvc.NewChannelConfigService(memory.NewChannelConfigRepositoryMemory()),
+		ExternalLocationAddress: "http://service.url",
+	})
+
+	do := func(method, path, payload string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(payload))
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		return resp.StatusCode, readBody(t, resp)
+	}
+
+	t.Run("save the channel config", func(t *testing.T) {
+		status, body := do("PUT", "/channel-configs/ch1",
+			`{"display_name":"Support","timezone":"Europe/Prague","owner_emails":["owner@email.test"]}`)
+
+		assert.Equal(t, http.StatusOK, status, "Status code")
+		assert.JSONEq(t, `{"channel_id":"ch1","enabled":true,"display_name":"Support","timezone":"Europe/Prague","owner_emails":["owner@email.test"]}`, body)
+	})
+
+	t.Run("save invalid channel config", func(t *testing.T) {
+		status, _ := do("PUT", "/channel-configs/ch2", `{"timezone":"Nowhere/Unknown"}`)
+		assert.Equal(t, http.StatusBadRequest, status, "Status code")
+
+		status, _ = do("PUT", "/channel-configs/ch2", `{"owner_emails":["not an email"]}`)
+		assert.Equal(t, http.StatusBadRequest, status, "Status code")
+	})
+
+	t.Run("get and list the channel configs", func(t *testing.T) {
+		status, body := do("GET", "/channel-configs/ch1", "")
+		assert.Equal(t, http.StatusOK, status, "Status code")
+		assert.Contains(t, body, `"display_name":"Support"`)
+
+		status, body = do("GET", "/channel-configs", "")
+		assert.Equal(t, http.StatusOK, status, "Status code")
+		assert.Contains(t, body, `"channel_id":"ch1"`)
+		assert.NotContains(t, body, `"channel_id":"ch2"`)
+
+		status, _ = do("GET", "/channel-configs/unknown", "")
+		assert.Equal(t, http.StatusNotFound, status, "Status code")
+	})
+
+	t.Run("delete the channel config", func(t *testing.T) {
+		status, _ := do("DELETE", "/channel-configs/ch1", "")
+		assert.Equal(t, http.StatusNoContent, status, "Status code")
+
+		status, _ = do("DELETE", "/channel-configs/ch1", "")
+		assert.Equal(t, http.StatusNotFound, status, "Status code")
+	})
+}
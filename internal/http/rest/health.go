@@ -0,0 +1,19 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// swagger:route GET /health health GetHealth
+// Returns health of the service with the states of the circuit breakers of the ITSM endpoints
+// responses:
+//	200: healthResponse
+
+// GetHealth returns handler for getting health of the service
+func (s *Server) GetHealth() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		s.healthPresenter.RenderHealth(w, s.circuitBreakers)
+	}
+}
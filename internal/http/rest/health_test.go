@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHealth(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	users := client.NewCircuitBreaker("http://itsm.test/users", 1, time.Minute)
+	tickets := client.NewCircuitBreaker("http://itsm.test/tickets", 1, time.Minute)
+
+	server := NewServer(Config{
+		Addr:                    "service.url",
+		Logger:                  logger,
+		JobsProcessor:           new(mocks.JobProcessorMock),
+		CircuitBreakers:         []*client.CircuitBreaker{users, tickets},
+		ExternalLocationAddress: "http://service.url",
+	})
+
+	getHealth := func() (int, string) {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		resp := w.Result()
+
+		return resp.StatusCode, readBody(t, resp)
+	}
+
+	status, body := getHealth()
+	assert.Equal(t, http.StatusOK, status, "Status code")
+	assert.JSONEq(t, `{"status":"ok","circuit_breakers":[
+		{"endpoint":"http://itsm.test/users","state":"closed"},
+		{"endpoint":"http://itsm.test/tickets","state":"closed"}
+	]}`, body)
+
+	tickets.Failure()
+
+	status, body = getHealth()
+	assert.Equal(t, http.StatusOK, status, "Status code")
+	assert.JSONEq(t, `{"status":"degraded","circuit_breakers":[
+		{"endpoint":"http://itsm.test/users","state":"closed"},
+		{"endpoint":"http://itsm.test/tickets","state":"open"}
+	]}`, body)
+}
@@ -9,4 +9,6 @@ func (s *Server) registerInputConverters() {
 	validator := validators.NewPayloadValidator()
 
 	s.jobInputPayloadConverter = converters.NewJobPayloadConverter(s.logger, validator)
+	s.channelConfigPayloadConverter = converters.NewChannelConfigPayloadConverter(s.logger, validator)
+	s.preferencesFormConverter = converters.NewPreferencesFormConverter()
 }
@@ -52,7 +52,7 @@ func TestCreateJobHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Status code")
 
-		expectedJSON := `{"error":"type must be one of ['FE report only' 'SD report only' 'all']"}`
+		expectedJSON := `{"error":"type must be one of ['FE report only' 'SD report only' 'Channel report only' 'all']"}`
 		assert.JSONEq(t, expectedJSON, string(b), "response does not match")
 	})
 
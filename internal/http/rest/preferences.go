@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// swagger:route GET /preferences/{token} preferences GetPreferences
+// Shows the page where the recipient manages the report preferences; the signed link is sent in the report emails
+// produces:
+//	- text/html
+// responses:
+//	200: preferencesPageResponse
+//	404: errorResponse404
+
+// GetPreferences returns handler for rendering the preferences page
+func (s *Server) GetPreferences() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+
+		prefs, err := s.preferencesService.GetPreferences(ctx, params.ByName("token"))
+		if err != nil {
+			s.logger.Warnw("GetPreferences handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		choices, err := s.preferencesService.Choices(ctx)
+		if err != nil {
+			s.logger.Errorw("GetPreferences handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		s.preferencesPresenter.RenderPreferences(w, prefs, choices, "")
+	}
+}
+
+// swagger:route POST /preferences/{token} preferences UpdatePreferences
+// Saves the report preferences submitted from the preferences page
+// consumes:
+//	- application/x-www-form-urlencoded
+// produces:
+//	- text/html
+// responses:
+//	200: preferencesPageResponse
+//	400: errorResponse400
+//	404: errorResponse404
+
+// UpdatePreferences returns handler for saving the preferences form
+func (s *Server) UpdatePreferences() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+
+		form, err := s.preferencesFormConverter.PreferencesParamsFromForm(r)
+		if err != nil {
+			s.logger.Warnw("UpdatePreferences handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		prefs, err := s.preferencesService.UpdatePreferences(ctx, params.ByName("token"), form)
+		if err != nil {
+			s.logger.Warnw("UpdatePreferences handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		choices, err := s.preferencesService.Choices(ctx)
+		if err != nil {
+			s.logger.Errorw("UpdatePreferences handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		s.preferencesPresenter.RenderPreferences(w, prefs, choices, "Your preferences have been saved.")
+	}
+}
+
+// swagger:route GET /preferences/{token}/unsubscribe preferences ConfirmUnsubscribe
+// Shows the page confirming the opt-out from all reports; the link is sent in the report emails, nothing is changed
+// until the recipient confirms it, so that mail scanners and link prefetchers opening the link do not opt out anybody
+// produces:
+//	- text/html
+// responses:
+//	200: preferencesPageResponse
+//	404: errorResponse404
+
+// ConfirmUnsubscribe returns handler for rendering the unsubscribe confirmation page
+func (s *Server) ConfirmUnsubscribe() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		prefs, err := s.preferencesService.GetPreferences(r.Context(), params.ByName("token"))
+		if err != nil {
+			s.logger.Warnw("ConfirmUnsubscribe handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		s.preferencesPresenter.RenderUnsubscribeConfirmation(w, prefs)
+	}
+}
+
+// swagger:route POST /preferences/{token}/unsubscribe preferences Unsubscribe
+// Opts out the recipient from all reports; it is the one-click unsubscribe of the List-Unsubscribe-Post email header
+// (RFC 8058) and the confirmation of the unsubscribe page
+// produces:
+//	- text/html
+// responses:
+//	200: preferencesPageResponse
+//	404: errorResponse404
+
+// Unsubscribe returns handler for opting out the recipient
+func (s *Server) Unsubscribe() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := r.Context()
+
+		prefs, err := s.preferencesService.Unsubscribe(ctx, params.ByName("token"))
+		if err != nil {
+			s.logger.Warnw("Unsubscribe handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		choices, err := s.preferencesService.Choices(ctx)
+		if err != nil {
+			s.logger.Errorw("Unsubscribe handler failed", "error", err)
+			s.preferencesPresenter.RenderError(w, "", err)
+			return
+		}
+
+		s.preferencesPresenter.RenderPreferences(w, prefs, choices, "You have been unsubscribed from all reports.")
+	}
+}
@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository/memory"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferencesHandlers(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	ctx := context.Background()
+
+	channelRepository := memory.NewChannelRepositoryMemory()
+	require.NoError(t, channelRepository.StoreChannelList(ctx, channel.List{{ChannelID: "ch1", Name: "First channel"}}))
+
+	clock := mocks.NewFixedClock()
+	preferencesRepository := memory.NewPreferencesRepositoryMemory()
+	preferencesService := prefsvc.NewPreferencesService(
+		clock, preferencesRepository, channelRepository, []ticket.RecordType{{Name: "incident", DisplayName: "Incident"}, {Name: "problem"}},
+		"secret", 0, "http://service.url/",
+	)
+
+	server := NewServer(Config{
+		Addr:                    "service.url",
+		Logger:                  logger,
+		JobsProcessor:           new(mocks.JobProcessorMock),
+		PreferencesService:      preferencesService,
+		ExternalLocationAddress: "http://service.url",
+	})
+
+	manageURL := preferencesService.ManageURL("Joe@Email.test")
+	require.True(t, strings.HasPrefix(manageURL, "http://service.url/preferences/"))
+	path := strings.TrimPrefix(manageURL, "http://service.url")
+
+	t.Run("show the preferences page", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path, nil)
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		body := readBody(t, resp)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Status code")
+		assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+		assert.Contains(t, body, "joe@email.test")
+		assert.Contains(t, body, "First channel")
+		assert.Contains(t, body, `value="incident"> Incident`)
+		assert.Contains(t, body, `value="problem"> problem`, "record type without display name is offered by its name")
+	})
+
+	t.Run("save the preferences", func(t *testing.T) {
+		form := url.Values{
+			"frequency":    {"weekly"},
+			"weekday":      {"5"},
+			"channels":     {"ch1"},
+			"record_types": {"incident"},
+			"language":     {"cs"},
+		}
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Status code")
+		assert.Contains(t, readBody(t, resp), "saved")
+
+		p, err := preferencesRepository.GetPreferences(ctx, "joe@email.test")
+		require.NoError(t, err)
+		assert.Equal(t, preferences.FrequencyWeekly, p.Frequency)
+		assert.Equal(t, time.Friday, p.Weekday)
+		assert.Equal(t, []string{"ch1"}, p.Channels)
+		assert.Equal(t, []string{"incident"}, p.RecordTypes)
+		assert.Equal(t, "cs", p.Language)
+		assert.False(t, p.OptedOut)
+	})
+
+	t.Run("invalid preferences", func(t *testing.T) {
+		form := url.Values{"frequency": {"hourly"}, "language": {"en"}}
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Status code")
+	})
+
+	t.Run("unsubscribe link only shows the confirmation", func(t *testing.T) {
+		req := httptest.NewRequest("GET", strings.TrimPrefix(preferencesService.UnsubscribeURL("joe@email.test"), "http://service.url"), nil)
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Status code")
+		body := readBody(t, resp)
+		assert.Contains(t, body, `<form method="post">`)
+		assert.Contains(t, body, "joe@email.test")
+
+		p, err := preferencesRepository.GetPreferences(ctx, "joe@email.test")
+		require.NoError(t, err)
+		assert.False(t, p.OptedOut)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		req := httptest.NewRequest("POST", strings.TrimPrefix(preferencesService.UnsubscribeURL("joe@email.test"), "http://service.url"),
+			strings.NewReader("List-Unsubscribe=One-Click"))
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Status code")
+
+		p, err := preferencesRepository.GetPreferences(ctx, "joe@email.test")
+		require.NoError(t, err)
+		assert.True(t, p.OptedOut)
+		assert.Equal(t, "cs", p.Language, "other preferences are kept")
+	})
+
+	t.Run("forged token", func(t *testing.T) {
+		forged := prefsvc.NewPreferencesService(clock, preferencesRepository, channelRepository, nil, "other secret", 0, "")
+		req := httptest.NewRequest("GET", forged.ManageURL("joe@email.test"), nil)
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Status code")
+	})
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	defer func() { _ = resp.Body.Close() }()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("could not read response: %v", err)
+	}
+
+	return string(b)
+}
@@ -4,4 +4,7 @@ import "github.com/KompiTech/itsm-reporting-service/internal/http/rest/presenter
 
 func (s *Server) registerPresenters() {
 	s.jobsPresenter = presenters.NewJobPresenter(s.logger, s.ExternalLocationAddress)
+	s.channelConfigPresenter = presenters.NewChannelConfigPresenter(s.logger, s.ExternalLocationAddress)
+	s.healthPresenter = presenters.NewHealthPresenter(s.logger, s.ExternalLocationAddress)
+	s.preferencesPresenter = presenters.NewPreferencesPresenter(s.logger, s.ExternalLocationAddress)
 }
@@ -0,0 +1,44 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+	"go.uber.org/zap"
+)
+
+// NewChannelConfigPresenter creates new channel configuration presentation service
+func NewChannelConfigPresenter(logger *zap.SugaredLogger, serverAddr string) ChannelConfigPresenter {
+	return &channelConfigPresenter{
+		BasicPresenter: NewBasicPresenter(logger, serverAddr),
+	}
+}
+
+type channelConfigPresenter struct {
+	*BasicPresenter
+}
+
+func (p channelConfigPresenter) RenderChannelConfig(w http.ResponseWriter, c channel.Config) {
+	p.renderJSON(w, p.convertChannelConfigToAPI(c))
+}
+
+func (p channelConfigPresenter) RenderChannelConfigList(w http.ResponseWriter, configs []channel.Config) {
+	apiList := make([]api.ChannelConfig, 0)
+
+	for _, c := range configs {
+		apiList = append(apiList, p.convertChannelConfigToAPI(c))
+	}
+
+	p.renderJSON(w, apiList)
+}
+
+func (p channelConfigPresenter) convertChannelConfigToAPI(c channel.Config) api.ChannelConfig {
+	return api.ChannelConfig{
+		ChannelID:   c.ChannelID,
+		Enabled:     c.Enabled,
+		DisplayName: c.DisplayName,
+		Timezone:    c.Timezone,
+		OwnerEmails: c.OwnerEmails,
+	}
+}
@@ -0,0 +1,47 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
+	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/api"
+	"go.uber.org/zap"
+)
+
+// Health statuses
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+)
+
+// NewHealthPresenter creates new health presentation service
+func NewHealthPresenter(logger *zap.SugaredLogger, serverAddr string) HealthPresenter {
+	return &healthPresenter{
+		BasicPresenter: NewBasicPresenter(logger, serverAddr),
+	}
+}
+
+type healthPresenter struct {
+	*BasicPresenter
+}
+
+func (p healthPresenter) RenderHealth(w http.ResponseWriter, breakers []*client.CircuitBreaker) {
+	health := api.Health{
+		Status:          healthOK,
+		CircuitBreakers: make([]api.CircuitBreaker, 0),
+	}
+
+	for _, b := range breakers {
+		state := b.State()
+		if state != client.BreakerClosed {
+			health.Status = healthDegraded
+		}
+
+		health.CircuitBreakers = append(health.CircuitBreakers, api.CircuitBreaker{
+			Endpoint: b.Endpoint(),
+			State:    string(state),
+		})
+	}
+
+	p.renderJSON(w, health)
+}
@@ -3,7 +3,10 @@ package presenters
 import (
 	"net/http"
 
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
 )
 
@@ -39,3 +42,37 @@ type JobPresenter interface {
 	// It does not otherwise end the request; the caller should ensure no further writes are done to 'w'.
 	RenderJobList(w http.ResponseWriter, jobList []job.Job)
 }
+
+// PreferencesPresenter provides HTML pages for the recipients managing their preferences
+type PreferencesPresenter interface {
+	ErrorPresenter
+
+	// RenderPreferences renders the page with the form for changing the preferences; message is shown
+	// above the form if not empty.
+	// It does not otherwise end the request; the caller should ensure no further writes are done to 'w'.
+	RenderPreferences(w http.ResponseWriter, p preferences.Preferences, choices preferences.Choices, message string)
+
+	// RenderUnsubscribeConfirmation renders the page with the button confirming the opt-out from all reports.
+	// It does not otherwise end the request; the caller should ensure no further writes are done to 'w'.
+	RenderUnsubscribeConfirmation(w http.ResponseWriter, p preferences.Preferences)
+}
+
+// ChannelConfigPresenter provides REST responses for channel configuration resource
+type ChannelConfigPresenter interface {
+	ErrorPresenter
+
+	// RenderChannelConfig encodes channel configuration and writes it to 'w'.  Also sets correct Content-Type header.
+	// It does not otherwise end the request; the caller should ensure no further writes are done to 'w'.
+	RenderChannelConfig(w http.ResponseWriter, c channel.Config)
+
+	// RenderChannelConfigList encodes list of channel configurations and writes it to 'w'.  Also sets correct Content-Type header.
+	// It does not otherwise end the request; the caller should ensure no further writes are done to 'w'.
+	RenderChannelConfigList(w http.ResponseWriter, configs []channel.Config)
+}
+
+// HealthPresenter provides REST responses for health resource
+type HealthPresenter interface {
+	// RenderHealth encodes the health with the states of the circuit breakers and writes it to 'w'.  Also sets correct
+	// Content-Type header. It does not otherwise end the request; the caller should ensure no further writes are done to 'w'.
+	RenderHealth(w http.ResponseWriter, breakers []*client.CircuitBreaker)
+}
@@ -51,6 +51,9 @@ func (p jobPresenter) convertJobToAPI(j job.Job) api.Job {
 		EmailsSendingStartedAt:         j.EmailsSendingStartedAt.String(),
 		EmailsSendingFinishedAt:        j.EmailsSendingFinishedAt.String(),
 		FinalStatus:                    j.FinalStatus,
+		Warnings:                       j.Warnings,
+		TicketsDownloadMode:            j.TicketsDownloadMode,
+		ExcludedRecipients:             j.ExcludedRecipients,
 	}
 
 	return apiJob
@@ -0,0 +1,161 @@
+package presenters
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	"go.uber.org/zap"
+)
+
+// NewPreferencesPresenter creates new preferences presentation service
+func NewPreferencesPresenter(logger *zap.SugaredLogger, serverAddr string) PreferencesPresenter {
+	return &preferencesPresenter{
+		BasicPresenter:  NewBasicPresenter(logger, serverAddr),
+		tmpl:            template.Must(template.New("preferences").Parse(preferencesTemplate)),
+		unsubscribeTmpl: template.Must(template.New("unsubscribe").Parse(unsubscribeTemplate)),
+	}
+}
+
+type preferencesPresenter struct {
+	*BasicPresenter
+	tmpl            *template.Template
+	unsubscribeTmpl *template.Template
+}
+
+type option struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+type preferencesPage struct {
+	Email       string
+	Message     string
+	Weekly      bool
+	OptedOut    bool
+	Weekdays    []option
+	Channels    []option
+	RecordTypes []option
+	Languages   []option
+}
+
+func (p preferencesPresenter) RenderPreferences(
+	w http.ResponseWriter, prefs preferences.Preferences, choices preferences.Choices, message string,
+) {
+	page := preferencesPage{
+		Email:    prefs.Email,
+		Message:  message,
+		Weekly:   prefs.Frequency == preferences.FrequencyWeekly,
+		OptedOut: prefs.OptedOut,
+	}
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		page.Weekdays = append(page.Weekdays, option{
+			Value:    strconv.Itoa(int(d)),
+			Label:    d.String(),
+			Selected: d == prefs.Weekday,
+		})
+	}
+
+	for _, ch := range choices.Channels {
+		page.Channels = append(page.Channels, option{
+			Value:    ch.ChannelID,
+			Label:    ch.Name,
+			Selected: contains(prefs.Channels, ch.ChannelID),
+		})
+	}
+
+	for _, recordType := range choices.RecordTypes {
+		label := recordType.DisplayName
+		if label == "" {
+			label = recordType.Name
+		}
+		page.RecordTypes = append(page.RecordTypes, option{
+			Value:    recordType.Name,
+			Label:    label,
+			Selected: contains(prefs.RecordTypes, recordType.Name),
+		})
+	}
+
+	for _, language := range choices.Languages {
+		page.Languages = append(page.Languages, option{
+			Value:    language,
+			Label:    language,
+			Selected: language == prefs.Language,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := p.tmpl.Execute(w, page); err != nil {
+		p.logger.Errorw("rendering preferences page", "error", err)
+	}
+}
+
+func (p preferencesPresenter) RenderUnsubscribeConfirmation(w http.ResponseWriter, prefs preferences.Preferences) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := p.unsubscribeTmpl.Execute(w, prefs); err != nil {
+		p.logger.Errorw("rendering unsubscribe page", "error", err)
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+const preferencesTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Report preferences</title>
+</head>
+<body style="font-family: Arial, sans-serif;">
+<h2>Report preferences of {{.Email}}</h2>
+{{if .Message}}<p><strong>{{.Message}}</strong></p>{{end}}
+<form method="post">
+<p>
+Frequency:
+<label><input type="radio" name="frequency" value="daily"{{if not .Weekly}} checked{{end}}> daily</label>
+<label><input type="radio" name="frequency" value="weekly"{{if .Weekly}} checked{{end}}> weekly on</label>
+<select name="weekday">{{range .Weekdays}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Label}}</option>{{end}}</select>
+</p>
+{{if .Channels}}<p>Channels (none selected means all):<br>
+{{range .Channels}}<label><input type="checkbox" name="channels" value="{{.Value}}"{{if .Selected}} checked{{end}}> {{.Label}}</label><br>
+{{end}}</p>{{end}}
+{{if .RecordTypes}}<p>Ticket types (none selected means all):<br>
+{{range .RecordTypes}}<label><input type="checkbox" name="record_types" value="{{.Value}}"{{if .Selected}} checked{{end}}> {{.Label}}</label><br>
+{{end}}</p>{{end}}
+<p>
+Language:
+<select name="language">{{range .Languages}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Label}}</option>{{end}}</select>
+</p>
+<p><label><input type="checkbox" name="opted_out" value="true"{{if .OptedOut}} checked{{end}}> Do not send me any reports</label></p>
+<p><input type="submit" value="Save"></p>
+</form>
+</body>
+</html>
+`
+
+// unsubscribeTemplate posts the same one-click form as the email clients supporting List-Unsubscribe-Post (RFC 8058)
+const unsubscribeTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Unsubscribe from reports</title>
+</head>
+<body style="font-family: Arial, sans-serif;">
+<h2>Unsubscribe {{.Email}} from all reports?</h2>
+<form method="post">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<p><input type="submit" value="Unsubscribe"></p>
+</form>
+</body>
+</html>
+`
@@ -3,6 +3,8 @@ package rest
 import (
 	"net/http"
 
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+
 	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/presenters"
 )
 
@@ -11,6 +13,30 @@ func (s *Server) registerRoutes() {
 	s.router.GET("/jobs/:id", s.GetJob())
 	s.router.GET("/jobs", s.ListJobs())
 
+	s.router.GET("/health", s.GetHealth())
+
+	// user cache is managed only when the user downloader is set
+	if s.userDownloader != nil {
+		s.router.DELETE("/users/cache", s.InvalidateUserCache())
+		s.router.POST("/users/cache/refresh", s.RefreshUserCache())
+	}
+
+	// channel configurations are managed only when the channel configuration service is set
+	if s.channelConfigService != nil {
+		s.router.GET(channelConfigsRoute, s.ListChannelConfigs())
+		s.router.GET(channelConfigsRoute+"/:channel_id", s.GetChannelConfig())
+		s.router.PUT(channelConfigsRoute+"/:channel_id", s.SaveChannelConfig())
+		s.router.DELETE(channelConfigsRoute+"/:channel_id", s.DeleteChannelConfig())
+	}
+
+	// preferences pages are served only when the preferences service is set
+	if s.preferencesService != nil {
+		s.router.GET(prefsvc.PreferencesRoute+"/:token", s.GetPreferences())
+		s.router.POST(prefsvc.PreferencesRoute+"/:token", s.UpdatePreferences())
+		s.router.GET(prefsvc.PreferencesRoute+"/:token/unsubscribe", s.ConfirmUnsubscribe())
+		s.router.POST(prefsvc.PreferencesRoute+"/:token/unsubscribe", s.Unsubscribe())
+	}
+
 	// default Not Found handler
 	s.router.NotFound = http.HandlerFunc(s.JSONNotFoundError)
 }
@@ -4,26 +4,42 @@ import (
 	"net/http"
 	"time"
 
+	chansvc "github.com/KompiTech/itsm-reporting-service/internal/domain/channel/service"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/client"
 	jobprocessor "github.com/KompiTech/itsm-reporting-service/internal/domain/job/processor"
 	jobsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/job/service"
+	prefsvc "github.com/KompiTech/itsm-reporting-service/internal/domain/preferences/service"
+	userdownloader "github.com/KompiTech/itsm-reporting-service/internal/domain/user/downloader"
 	converters "github.com/KompiTech/itsm-reporting-service/internal/http/rest/api/input_converters"
 	"github.com/KompiTech/itsm-reporting-service/internal/http/rest/presenters"
+	"github.com/KompiTech/itsm-reporting-service/internal/tracing"
 	"github.com/julienschmidt/httprouter"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	"go.uber.org/zap"
 )
 
 // Server is a http.Handler with dependencies
 type Server struct {
-	Addr                     string
-	URISchema                string
-	router                   *httprouter.Router
-	logger                   *zap.SugaredLogger
-	jobsService              jobsvc.JobService
-	jobsPresenter            presenters.JobPresenter
-	jobInputPayloadConverter converters.JobPayloadConverter
-	jobsProcessor            jobprocessor.JobProcessor
-	ExternalLocationAddress  string
+	Addr                          string
+	URISchema                     string
+	router                        *httprouter.Router
+	logger                        *zap.SugaredLogger
+	jobsService                   jobsvc.JobService
+	jobsPresenter                 presenters.JobPresenter
+	jobInputPayloadConverter      converters.JobPayloadConverter
+	jobsProcessor                 jobprocessor.JobProcessor
+	userDownloader                userdownloader.UserDownloader
+	preferencesService            prefsvc.PreferencesService
+	channelConfigService          chansvc.ChannelConfigService
+	channelConfigPresenter        presenters.ChannelConfigPresenter
+	channelConfigPayloadConverter converters.ChannelConfigPayloadConverter
+	preferencesPresenter          presenters.PreferencesPresenter
+	preferencesFormConverter      converters.PreferencesFormConverter
+	circuitBreakers               []*client.CircuitBreaker
+	healthPresenter               presenters.HealthPresenter
+	ExternalLocationAddress       string
 }
 
 // Config contains server configuration and dependencies
@@ -33,6 +49,10 @@ type Config struct {
 	Logger                  *zap.SugaredLogger
 	JobsService             jobsvc.JobService
 	JobsProcessor           jobprocessor.JobProcessor
+	UserDownloader          userdownloader.UserDownloader
+	PreferencesService      prefsvc.PreferencesService
+	ChannelConfigService    chansvc.ChannelConfigService
+	CircuitBreakers         []*client.CircuitBreaker // reported by the health check
 	ExternalLocationAddress string
 }
 
@@ -52,6 +72,10 @@ func NewServer(cfg Config) *Server {
 		logger:                  cfg.Logger,
 		jobsService:             cfg.JobsService,
 		jobsProcessor:           cfg.JobsProcessor,
+		userDownloader:          cfg.UserDownloader,
+		preferencesService:      cfg.PreferencesService,
+		channelConfigService:    cfg.ChannelConfigService,
+		circuitBreakers:         cfg.CircuitBreakers,
 		ExternalLocationAddress: cfg.ExternalLocationAddress,
 	}
 	if s.jobsProcessor == nil {
@@ -75,7 +99,31 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"url", r.URL.String(),
 	)
 
-	s.router.ServeHTTP(w, r)
+	// each request is traced, it continues the trace of the caller if there is one
+	ctx, span := tracing.Start(tracing.Extract(r.Context(), r.Header), "HTTP "+r.Method,
+		semconv.HTTPMethodKey.String(r.Method),
+		semconv.HTTPTargetKey.String(r.URL.Path),
+	)
+	defer span.End()
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	s.router.ServeHTTP(sw, r.WithContext(ctx))
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(sw.status))
+	if sw.status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(sw.status))
+	}
+}
+
+// statusWriter remembers the status code of the response for the request span
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
 }
 
 // PaginationParams parses request query and returns params with information about requested page number and items per page to be displayed
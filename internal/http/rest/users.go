@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// swagger:route DELETE /users/cache users InvalidateUserCache
+// Invalidates cached users, they are downloaded again by the next job
+// responses:
+//	204: noContentResponse
+
+// InvalidateUserCache returns handler for invalidating the user cache
+func (s *Server) InvalidateUserCache() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		channelID := r.URL.Query().Get("channel_id")
+
+		if err := s.userDownloader.InvalidateCache(r.Context(), channelID); err != nil {
+			s.logger.Errorw("InvalidateUserCache handler failed", "channel", channelID, "error", err)
+			s.jobsPresenter.RenderError(w, "", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// swagger:route POST /users/cache/refresh users RefreshUserCache
+// Downloads users from the ITSM service immediately
+// responses:
+//	204: noContentResponse
+//	404: errorResponse404
+
+// RefreshUserCache returns handler for refreshing the user cache
+func (s *Server) RefreshUserCache() func(http.ResponseWriter, *http.Request, httprouter.Params) {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		channelID := r.URL.Query().Get("channel_id")
+
+		if err := s.userDownloader.RefreshCache(r.Context(), channelID); err != nil {
+			s.logger.Errorw("RefreshUserCache handler failed", "channel", channelID, "error", err)
+			s.jobsPresenter.RenderError(w, "", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
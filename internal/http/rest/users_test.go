@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCacheHandlers(t *testing.T) {
+	logger, _ := testutils.NewTestLogger()
+	defer func() { _ = logger.Sync() }()
+
+	channelID := "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01"
+
+	userDownloader := new(mocks.UserDownloaderMock)
+	userDownloader.On("InvalidateCache", "").Return(nil).Once()
+	userDownloader.On("RefreshCache", channelID).Return(nil).Once()
+	userDownloader.On("RefreshCache", "nonexistentID").
+		Return(domain.NewErrorf(domain.ErrorCodeNotFound, "channel 'nonexistentID' not found")).Once()
+
+	server := NewServer(Config{
+		Addr:                    "service.url",
+		Logger:                  logger,
+		JobsProcessor:           new(mocks.JobProcessorMock),
+		UserDownloader:          userDownloader,
+		ExternalLocationAddress: "http://service.url",
+	})
+
+	t.Run("invalidate all channels", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/users/cache", nil)
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Status code")
+	})
+
+	t.Run("refresh the channel", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users/cache/refresh?channel_id="+channelID, nil)
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Status code")
+	})
+
+	t.Run("refresh nonexistent channel", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users/cache/refresh?channel_id=nonexistentID", nil)
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		resp := w.Result()
+
+		defer func() { _ = resp.Body.Close() }()
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("could not read response: %v", err)
+		}
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Status code")
+		assert.JSONEq(t, `{"error":"channel 'nonexistentID' not found"}`, string(b), "response does not match")
+	})
+
+	userDownloader.AssertExpectations(t)
+}
@@ -24,3 +24,9 @@ func (m *EmailSenderMock) SendEmailsForServiceDesk(_ context.Context) error {
 	args := m.Called()
 	return args.Error(0)
 }
+
+func (m *EmailSenderMock) SendEmailsForChannelOwners(_ context.Context) error {
+	defer m.Wg.Done()
+	args := m.Called()
+	return args.Error(0)
+}
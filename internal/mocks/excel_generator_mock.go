@@ -21,6 +21,13 @@ func (m *ExcelGeneratorMock) GenerateExcelFilesForServiceDesk(_ context.Context)
 	return args.Error(0)
 }
 
+func (m *ExcelGeneratorMock) GenerateExcelFilesForChannelOwners(_ context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *ExcelGeneratorMock) ExcludedRecipients() []string { return nil }
+
 func (m *ExcelGeneratorMock) FEDirPath() string {
 	//TODO implement me
 	panic("implement me")
@@ -30,3 +37,8 @@ func (m *ExcelGeneratorMock) SDDirPath() string {
 	//TODO implement me
 	panic("implement me")
 }
+
+func (m *ExcelGeneratorMock) ChannelDirPath() string {
+	//TODO implement me
+	panic("implement me")
+}
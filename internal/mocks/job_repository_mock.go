@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
@@ -33,6 +34,21 @@ func (m *JobRepositoryMock) GetLastJob(_ context.Context) (job.Job, error) {
 	return args.Get(0).(job.Job), args.Error(1)
 }
 
+func (m *JobRepositoryMock) GetLastSuccessfulJob(_ context.Context) (job.Job, error) {
+	args := m.Called()
+	return args.Get(0).(job.Job), args.Error(1)
+}
+
+func (m *JobRepositoryMock) GetLastSuccessfulJobBefore(_ context.Context, before time.Time) (job.Job, error) {
+	args := m.Called(before)
+	return args.Get(0).(job.Job), args.Error(1)
+}
+
+func (m *JobRepositoryMock) GetLastFullDownloadJob(_ context.Context) (job.Job, error) {
+	args := m.Called()
+	return args.Get(0).(job.Job), args.Error(1)
+}
+
 func (m *JobRepositoryMock) ListJobs(_ context.Context, _, _ uint) ([]job.Job, error) {
 	//TODO implement me
 	panic("implement me")
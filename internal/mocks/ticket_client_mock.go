@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
@@ -15,16 +16,49 @@ type TicketClientMock struct {
 	Wg sync.WaitGroup
 }
 
-func (m *TicketClientMock) GetIncidents(_ context.Context, channel channel.Channel) (ticket.List, error) {
+func (m *TicketClientMock) RecordTypes() []ticket.RecordType {
+	args := m.Called()
+	return args.Get(0).([]ticket.RecordType)
+}
+
+// GetTickets returns the tickets of the expectation that are open in the state model, with the state names
+// of the state model, as the ticket client does
+func (m *TicketClientMock) GetTickets(
+	_ context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel,
+) (ticket.List, error) {
 	defer m.Wg.Done()
-	args := m.Called(channel)
-	return args.Get(0).(ticket.List), args.Error(1)
+	args := m.Called(recordType, channel)
+	return withStates(args.Get(0).(ticket.List), states, true), args.Error(1)
 }
 
-func (m *TicketClientMock) GetRequests(_ context.Context, channel channel.Channel) (ticket.List, error) {
+// GetUpdatedTickets returns the tickets of the expectation in any state, with the state names of the state model
+func (m *TicketClientMock) GetUpdatedTickets(
+	_ context.Context, recordType ticket.RecordType, states ticket.StateModel, channel channel.Channel, since time.Time,
+) (ticket.List, error) {
 	defer m.Wg.Done()
-	args := m.Called(channel)
-	return args.Get(0).(ticket.List), args.Error(1)
+	args := m.Called(recordType, channel, since)
+	return withStates(args.Get(0).(ticket.List), states, false), args.Error(1)
 }
 
 func (m *TicketClientMock) Close() error { return nil }
+
+// withStates returns copy of the tickets with the state names resolved from the state model,
+// only the open ones if openOnly is set
+func withStates(tickets ticket.List, states ticket.StateModel, openOnly bool) ticket.List {
+	if tickets == nil {
+		return nil
+	}
+
+	list := ticket.List{}
+	for _, t := range tickets {
+		if openOnly && !states.IsOpen(t.TicketData.StateID) {
+			continue
+		}
+		if t.TicketData.State == "" {
+			t.TicketData.State = states.Name(t.TicketData.StateID)
+		}
+		list = append(list, t)
+	}
+
+	return list
+}
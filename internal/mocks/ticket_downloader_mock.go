@@ -3,6 +3,8 @@ package mocks
 import (
 	"context"
 
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,11 +13,18 @@ type TicketDownloaderMock struct {
 	mock.Mock
 }
 
-func (m *TicketDownloaderMock) DownloadTickets(_ context.Context) error {
+func (m *TicketDownloaderMock) DownloadTickets(_ context.Context, _ bool) (string, error) {
 	args := m.Called()
+	return job.TicketsDownloadFull, args.Error(0)
+}
+
+func (m *TicketDownloaderMock) SaveSnapshot(_ context.Context, jobID ref.UUID) error {
+	args := m.Called(jobID)
 	return args.Error(0)
 }
 
+func (m *TicketDownloaderMock) Warnings() []string { return nil }
+
 func (m *TicketDownloaderMock) Reset(_ context.Context) error { return nil }
 
 func (m *TicketDownloaderMock) Close() error { return nil }
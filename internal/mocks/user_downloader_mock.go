@@ -16,6 +16,16 @@ func (m *UserDownloaderMock) DownloadUsers(_ context.Context) error {
 	return args.Error(0)
 }
 
+func (m *UserDownloaderMock) InvalidateCache(_ context.Context, channelID string) error {
+	args := m.Called(channelID)
+	return args.Error(0)
+}
+
+func (m *UserDownloaderMock) RefreshCache(_ context.Context, channelID string) error {
+	args := m.Called(channelID)
+	return args.Error(0)
+}
+
 func (m *UserDownloaderMock) Reset(_ context.Context) error { return nil }
 
 func (m *UserDownloaderMock) Close() error { return nil }
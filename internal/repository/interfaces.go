@@ -6,6 +6,7 @@ import (
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/types"
@@ -35,6 +36,15 @@ type JobRepository interface {
 	// GetLastJob returns the last inserted job from the repository
 	GetLastJob(ctx context.Context) (job.Job, error)
 
+	// GetLastSuccessfulJob returns the last inserted job that finished successfully from the repository
+	GetLastSuccessfulJob(ctx context.Context) (job.Job, error)
+
+	// GetLastSuccessfulJobBefore returns the last job created before the time that finished successfully
+	GetLastSuccessfulJobBefore(ctx context.Context, before time.Time) (job.Job, error)
+
+	// GetLastFullDownloadJob returns the last inserted job that finished successfully with the full ticket download
+	GetLastFullDownloadJob(ctx context.Context) (job.Job, error)
+
 	// ListJobs returns the list of jobs from the repository
 	ListJobs(ctx context.Context, page, perPage uint) ([]job.Job, error)
 }
@@ -53,9 +63,16 @@ type UserRepository interface {
 	// AddUserList adds list of users to the repository
 	AddUserList(ctx context.Context, userList user.List) error
 
+	// ReplaceChannelUsers replaces all users of the channel with the list of users,
+	// empty list removes the users of the channel
+	ReplaceChannelUsers(ctx context.Context, channelID string, userList user.List) error
+
 	// GetUserInChannel returns user from specified channel from the repository
 	GetUserInChannel(ctx context.Context, channelID, userID string) (user.User, error)
 
+	// GetUser returns user from any channel from the repository, users with email address are preferred
+	GetUser(ctx context.Context, userID string) (user.User, error)
+
 	// Truncate removes all items from repository
 	Truncate(ctx context.Context) error
 }
@@ -66,11 +83,12 @@ type TicketRepository interface {
 	AddTicketList(ctx context.Context, ticketList ticket.List) error
 
 	// GetTicketsByEmailAddress returns tickets for the specified user's email address from the repository.
-	// It sorts the returned list, first are Incidents, then Requests.
+	// It sorts the returned list by the record type sort order (e.g. first are Incidents, then Requests).
 	GetTicketsByEmailAddress(ctx context.Context, userEmail string) (ticket.List, error)
 
 	// GetTicketsByChannelID returns tickets for the specified channel from the repository.
-	// It groups the returned list by user email address and sorts it, first are Incidents, then Requests.
+	// It groups the returned list by user email address and sorts it by the record type sort order.
+	// Tickets with unknown assignee (missing in the user directory) are grouped at the end of the list.
 	GetTicketsByChannelID(ctx context.Context, channelID string) (ticket.List, error)
 
 	// GetDistinctEmailAddresses returns distinct email addresses from the repository
@@ -79,6 +97,55 @@ type TicketRepository interface {
 	// GetDistinctChannelIDs returns distinct channel IDs from the repository
 	GetDistinctChannelIDs(ctx context.Context) ([]string, error)
 
+	// GetTicketList returns all tickets from the repository
+	GetTicketList(ctx context.Context) (ticket.List, error)
+
 	// Truncate removes all items from the repository
 	Truncate(ctx context.Context) error
 }
+
+// TicketSnapshotRepository provides access to the history of tickets downloaded by the jobs
+type TicketSnapshotRepository interface {
+	// SaveSnapshot stores the list of tickets downloaded by the job together with the scopes (channels and record
+	// types) the download covered
+	SaveSnapshot(ctx context.Context, jobID ref.UUID, ticketList ticket.List, scopes []ticket.Scope) error
+
+	// GetSnapshotScopes returns the scopes (channels and record types) covered by the snapshot of the job
+	GetSnapshotScopes(ctx context.Context, jobID ref.UUID) ([]ticket.Scope, error)
+
+	// GetSnapshot returns all tickets downloaded by the job
+	GetSnapshot(ctx context.Context, jobID ref.UUID) (ticket.List, error)
+
+	// GetSnapshotByEmailAddress returns tickets downloaded by the job that were assigned to the specified user's email address
+	GetSnapshotByEmailAddress(ctx context.Context, jobID ref.UUID, userEmail string) (ticket.List, error)
+
+	// GetSnapshotByChannelID returns tickets downloaded by the job from the specified channel
+	GetSnapshotByChannelID(ctx context.Context, jobID ref.UUID, channelID string) (ticket.List, error)
+
+	// DeleteExpiredSnapshots removes snapshots older than the retention period, it returns number of removed tickets
+	DeleteExpiredSnapshots(ctx context.Context) (int64, error)
+}
+
+// PreferencesRepository provides access to the preferences of the report recipients
+type PreferencesRepository interface {
+	// GetPreferences returns preferences of the recipient with the email address, ErrNotFound if there are none
+	GetPreferences(ctx context.Context, email string) (preferences.Preferences, error)
+
+	// SavePreferences stores preferences of the recipient, the previous ones are replaced
+	SavePreferences(ctx context.Context, p preferences.Preferences) error
+}
+
+// ChannelConfigRepository provides access to the channel configurations managed through the API
+type ChannelConfigRepository interface {
+	// ListChannelConfigs returns configurations of all configured channels ordered by channel ID
+	ListChannelConfigs(ctx context.Context) ([]channel.Config, error)
+
+	// GetChannelConfig returns configuration of the channel, ErrNotFound if the channel is not configured
+	GetChannelConfig(ctx context.Context, channelID string) (channel.Config, error)
+
+	// SaveChannelConfig stores configuration of the channel, the previous one is replaced
+	SaveChannelConfig(ctx context.Context, c channel.Config) error
+
+	// DeleteChannelConfig removes configuration of the channel, ErrNotFound if the channel is not configured
+	DeleteChannelConfig(ctx context.Context, channelID string) error
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// NewChannelConfigRepositoryMemory returns new initialized channel configuration repository that keeps data in memory
+func NewChannelConfigRepositoryMemory() repository.ChannelConfigRepository {
+	return &channelConfigRepositoryMemory{
+		configs: make(map[string]channel.Config),
+	}
+}
+
+type channelConfigRepositoryMemory struct {
+	configs map[string]channel.Config
+	mu      sync.Mutex
+}
+
+func (r *channelConfigRepositoryMemory) ListChannelConfigs(_ context.Context) ([]channel.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	configs := make([]channel.Config, 0, len(r.configs))
+	for _, c := range r.configs {
+		configs = append(configs, copyChannelConfig(c))
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].ChannelID < configs[j].ChannelID
+	})
+
+	return configs, nil
+}
+
+func (r *channelConfigRepositoryMemory) GetChannelConfig(_ context.Context, channelID string) (channel.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.configs[channelID]
+	if !ok {
+		return channel.Config{}, repository.ErrNotFound
+	}
+
+	return copyChannelConfig(c), nil
+}
+
+func (r *channelConfigRepositoryMemory) SaveChannelConfig(_ context.Context, c channel.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.configs[c.ChannelID] = copyChannelConfig(c)
+
+	return nil
+}
+
+func (r *channelConfigRepositoryMemory) DeleteChannelConfig(_ context.Context, channelID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.configs[channelID]; !ok {
+		return repository.ErrNotFound
+	}
+
+	delete(r.configs, channelID)
+
+	return nil
+}
+
+func copyChannelConfig(c channel.Config) channel.Config {
+	c.OwnerEmails = append([]string(nil), c.OwnerEmails...)
+	return c
+}
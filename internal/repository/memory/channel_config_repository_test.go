@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	repotests "github.com/KompiTech/itsm-reporting-service/internal/repository/tests"
+)
+
+func TestChannelConfigRepositoryMemory_SavingAndGettingConfigs(t *testing.T) {
+	repo := NewChannelConfigRepositoryMemory()
+
+	repotests.TestChannelConfigRepositorySavingAndGettingConfigs(t, repo)
+}
@@ -29,4 +29,10 @@ type Job struct {
 	EmailsSendingFinishedAt string
 
 	FinalStatus string
+
+	Warnings []string
+
+	TicketsDownloadMode string
+
+	ExcludedRecipients []string
 }
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
@@ -35,9 +36,10 @@ func (r *jobRepositoryMemory) AddJob(_ context.Context, job job.Job) (ref.UUID,
 	}
 
 	storedJob := Job{
-		ID:        jobID.String(),
-		Type:      job.Type.String(),
-		CreatedAt: now,
+		ID:                  jobID.String(),
+		Type:                job.Type.String(),
+		CreatedAt:           now,
+		TicketsDownloadMode: job.TicketsDownloadMode,
 	}
 
 	r.jobs = append(r.jobs, storedJob)
@@ -61,6 +63,9 @@ func (r *jobRepositoryMemory) UpdateJob(_ context.Context, job job.Job) (ref.UUI
 		EmailsSendingStartedAt:         job.EmailsSendingStartedAt.String(),
 		EmailsSendingFinishedAt:        job.EmailsSendingFinishedAt.String(),
 		FinalStatus:                    job.FinalStatus,
+		Warnings:                       append([]string(nil), job.Warnings...),
+		TicketsDownloadMode:            job.TicketsDownloadMode,
+		ExcludedRecipients:             append([]string(nil), job.ExcludedRecipients...),
 	}
 
 	for i, origJob := range r.jobs {
@@ -107,6 +112,43 @@ func (r jobRepositoryMemory) GetLastJob(_ context.Context) (job.Job, error) {
 	return r.convertStoredToDomainIncident(storedJob)
 }
 
+// GetLastSuccessfulJob returns the last inserted job that finished successfully from the repository
+func (r jobRepositoryMemory) GetLastSuccessfulJob(_ context.Context) (job.Job, error) {
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		if r.jobs[i].FinalStatus == job.StatusSuccess {
+			return r.convertStoredToDomainIncident(r.jobs[i])
+		}
+	}
+
+	return job.Job{}, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "no successful job in repository")
+}
+
+// GetLastSuccessfulJobBefore returns the last job created before the time that finished successfully
+func (r jobRepositoryMemory) GetLastSuccessfulJobBefore(_ context.Context, before time.Time) (job.Job, error) {
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		createdAt, err := types.DateTime(r.jobs[i].CreatedAt).ToTime()
+		if err != nil {
+			return job.Job{}, err
+		}
+		if r.jobs[i].FinalStatus == job.StatusSuccess && createdAt.Before(before) {
+			return r.convertStoredToDomainIncident(r.jobs[i])
+		}
+	}
+
+	return job.Job{}, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "no successful job before %s in repository", before)
+}
+
+// GetLastFullDownloadJob returns the last inserted job that finished successfully with the full ticket download
+func (r jobRepositoryMemory) GetLastFullDownloadJob(_ context.Context) (job.Job, error) {
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		if r.jobs[i].FinalStatus == job.StatusSuccess && r.jobs[i].TicketsDownloadMode == job.TicketsDownloadFull {
+			return r.convertStoredToDomainIncident(r.jobs[i])
+		}
+	}
+
+	return job.Job{}, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "no successful job with full ticket download in repository")
+}
+
 // ListJobs returns the list of jobs from the repository (last one as first)
 func (r jobRepositoryMemory) ListJobs(_ context.Context, page, perPage uint) ([]job.Job, error) {
 	var list []job.Job
@@ -165,6 +207,9 @@ func (r jobRepositoryMemory) convertStoredToDomainIncident(storedJob Job) (job.J
 	j.EmailsSendingStartedAt = types.DateTime(storedJob.EmailsSendingStartedAt)
 	j.EmailsSendingFinishedAt = types.DateTime(storedJob.EmailsSendingFinishedAt)
 	j.FinalStatus = storedJob.FinalStatus
+	j.Warnings = append([]string(nil), storedJob.Warnings...)
+	j.TicketsDownloadMode = storedJob.TicketsDownloadMode
+	j.ExcludedRecipients = append([]string(nil), storedJob.ExcludedRecipients...)
 
 	return j, nil
 }
@@ -34,3 +34,24 @@ func TestJobRepositoryMemory_GetLastJob(t *testing.T) {
 
 	repotests.TestJobRepositoryGetLastJob(t, repo, clock)
 }
+
+func TestJobRepositoryMemory_GetLastSuccessfulJob(t *testing.T) {
+	clock := mocks.NewFixedClock()
+	repo := NewJobRepositoryMemory(clock)
+
+	repotests.TestJobRepositoryGetLastSuccessfulJob(t, repo, clock)
+}
+
+func TestJobRepositoryMemory_GetLastSuccessfulJobBefore(t *testing.T) {
+	clock := mocks.NewFixedClock()
+	repo := NewJobRepositoryMemory(clock)
+
+	repotests.TestJobRepositoryGetLastSuccessfulJobBefore(t, repo, clock)
+}
+
+func TestJobRepositoryMemory_GetLastFullDownloadJob(t *testing.T) {
+	clock := mocks.NewFixedClock()
+	repo := NewJobRepositoryMemory(clock)
+
+	repotests.TestJobRepositoryGetLastFullDownloadJob(t, repo, clock)
+}
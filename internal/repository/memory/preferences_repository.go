@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// NewPreferencesRepositoryMemory returns new initialized preferences repository that keeps data in memory
+func NewPreferencesRepositoryMemory() repository.PreferencesRepository {
+	return &preferencesRepositoryMemory{
+		preferences: make(map[string]preferences.Preferences),
+	}
+}
+
+type preferencesRepositoryMemory struct {
+	preferences map[string]preferences.Preferences
+	mu          sync.Mutex
+}
+
+func (r *preferencesRepositoryMemory) GetPreferences(_ context.Context, email string) (preferences.Preferences, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.preferences[strings.ToLower(email)]
+	if !ok {
+		return preferences.Preferences{}, repository.ErrNotFound
+	}
+
+	return copyPreferences(p), nil
+}
+
+func (r *preferencesRepositoryMemory) SavePreferences(_ context.Context, p preferences.Preferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.Email = strings.ToLower(p.Email)
+	r.preferences[p.Email] = copyPreferences(p)
+
+	return nil
+}
+
+func copyPreferences(p preferences.Preferences) preferences.Preferences {
+	p.Channels = append([]string(nil), p.Channels...)
+	p.RecordTypes = append([]string(nil), p.RecordTypes...)
+	return p
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	repotests "github.com/KompiTech/itsm-reporting-service/internal/repository/tests"
+)
+
+func TestPreferencesRepositoryMemory_SavingAndGettingPreferences(t *testing.T) {
+	repo := NewPreferencesRepositoryMemory()
+
+	repotests.TestPreferencesRepositorySavingAndGettingPreferences(t, repo)
+}
@@ -36,10 +36,8 @@ func (r *ticketRepositoryMemory) GetTicketsByEmailAddress(_ context.Context, use
 
 	var list ticket.List
 
-	// first will be Incidents, then Requests
-	sort.SliceStable(r.tickets, func(i, j int) bool {
-		return r.tickets[i].TicketType < r.tickets[j].TicketType
-	})
+	// sort by record type order (e.g. first will be Incidents, then Requests)
+	sortByTicketType(r.tickets)
 
 	for _, t := range r.tickets {
 		if t.UserEmail == userEmail {
@@ -56,13 +54,14 @@ func (r *ticketRepositoryMemory) GetTicketsByChannelID(_ context.Context, channe
 
 	var list ticket.List
 
-	// sort by ticket type - first will be Incidents, then Requests
-	sort.SliceStable(r.tickets, func(i, j int) bool {
-		return r.tickets[i].TicketType < r.tickets[j].TicketType
-	})
+	// sort by record type order (e.g. first will be Incidents, then Requests)
+	sortByTicketType(r.tickets)
 
-	// sort (i.e. group) by email addresses
+	// sort (i.e. group) by email addresses, tickets with unknown assignee are grouped at the end
 	sort.SliceStable(r.tickets, func(i, j int) bool {
+		if r.tickets[i].HasUnknownAssignee() != r.tickets[j].HasUnknownAssignee() {
+			return r.tickets[j].HasUnknownAssignee()
+		}
 		return r.tickets[i].UserEmail < r.tickets[j].UserEmail
 	})
 
@@ -129,6 +128,16 @@ func (r *ticketRepositoryMemory) GetDistinctChannelIDs(_ context.Context) ([]str
 	return channelIDs, nil
 }
 
+func (r *ticketRepositoryMemory) GetTicketList(_ context.Context) (ticket.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make(ticket.List, len(r.tickets))
+	copy(list, r.tickets)
+
+	return list, nil
+}
+
 func (r *ticketRepositoryMemory) Truncate(_ context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -137,3 +146,13 @@ func (r *ticketRepositoryMemory) Truncate(_ context.Context) error {
 
 	return nil
 }
+
+// sortByTicketType sorts tickets by the sort order of their record type and then by the ticket type name
+func sortByTicketType(tickets []ticket.Ticket) {
+	sort.SliceStable(tickets, func(i, j int) bool {
+		if tickets[i].TicketTypeOrder != tickets[j].TicketTypeOrder {
+			return tickets[i].TicketTypeOrder < tickets[j].TicketTypeOrder
+		}
+		return tickets[i].TicketType < tickets[j].TicketType
+	})
+}
@@ -171,6 +171,12 @@ func TestTicketRepositoryMemory_AddingAndGettingTickets(t *testing.T) {
 	assert.Equal(t, req1, retTicketListByChannel2[7])
 	assert.Equal(t, req3, retTicketListByChannel2[8])
 
+	// GetTicketList
+	retTicketList, err := repo.GetTicketList(ctx)
+	require.NoError(t, err)
+
+	assert.Len(t, retTicketList, 10)
+
 	// GetDistinctEmailAddresses
 	retEmails, err := repo.GetDistinctEmailAddresses(ctx)
 	require.NoError(t, err)
@@ -189,3 +195,40 @@ func TestTicketRepositoryMemory_AddingAndGettingTickets(t *testing.T) {
 	assert.Equal(t, channel2ID, retChannels[0])
 	assert.Equal(t, channel1ID, retChannels[1])
 }
+
+func TestTicketRepositoryMemory_GetTicketsByChannelIDGroupsUnknownAssignees(t *testing.T) {
+	ctx := context.Background()
+	repo := NewTicketRepositoryMemory()
+
+	channelID := "6abf417c-52e3-4340-9713-df2f37e78176"
+
+	unknown := ticket.Ticket{
+		UserID:     "c8d1b9fb-35f1-46cb-aa37-a16b96937734",
+		ChannelID:  channelID,
+		TicketType: "INCIDENT",
+		TicketData: ticket.Data{Number: "INC1"},
+	}
+	unassigned := ticket.Ticket{
+		ChannelID:  channelID,
+		TicketType: "INCIDENT",
+		TicketData: ticket.Data{Number: "INC2"},
+	}
+	assigned := ticket.Ticket{
+		UserID:     "b599fdbe-09df-47f9-9b08-c08caccab3b1",
+		UserEmail:  "first@user.com",
+		UserName:   "First User",
+		ChannelID:  channelID,
+		TicketType: "INCIDENT",
+		TicketData: ticket.Data{Number: "INC3"},
+	}
+
+	err := repo.AddTicketList(ctx, ticket.List{unknown, assigned, unassigned})
+	require.NoError(t, err)
+
+	list, err := repo.GetTicketsByChannelID(ctx, channelID)
+	require.NoError(t, err)
+
+	assert.Equal(t, ticket.List{unassigned, assigned, unknown}, list, "tickets with unknown assignee are at the end")
+	assert.True(t, list[2].HasUnknownAssignee())
+	assert.False(t, list[0].HasUnknownAssignee())
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// NewTicketSnapshotRepositoryMemory returns new initialized ticket snapshot repository that keeps data in memory.
+// Snapshots older than retention are removed by DeleteExpiredSnapshots, zero retention keeps snapshots forever.
+func NewTicketSnapshotRepositoryMemory(clock repository.Clock, retention time.Duration) repository.TicketSnapshotRepository {
+	return &ticketSnapshotRepositoryMemory{
+		clock:     clock,
+		retention: retention,
+		snapshots: make(map[ref.UUID]ticketSnapshot),
+	}
+}
+
+type ticketSnapshot struct {
+	createdAt time.Time
+	tickets   ticket.List
+	scopes    []ticket.Scope
+}
+
+type ticketSnapshotRepositoryMemory struct {
+	clock     repository.Clock
+	retention time.Duration
+	snapshots map[ref.UUID]ticketSnapshot
+	mu        sync.Mutex
+}
+
+func (r *ticketSnapshotRepositoryMemory) SaveSnapshot(_ context.Context, jobID ref.UUID, ticketList ticket.List, scopes []ticket.Scope) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tickets := make(ticket.List, len(ticketList))
+	copy(tickets, ticketList)
+
+	r.snapshots[jobID] = ticketSnapshot{
+		createdAt: r.clock.Now(),
+		tickets:   tickets,
+		scopes:    append([]ticket.Scope(nil), scopes...),
+	}
+
+	return nil
+}
+
+func (r *ticketSnapshotRepositoryMemory) GetSnapshotScopes(_ context.Context, jobID ref.UUID) ([]ticket.Scope, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.snapshots[jobID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return append([]ticket.Scope(nil), s.scopes...), nil
+}
+
+func (r *ticketSnapshotRepositoryMemory) GetSnapshot(_ context.Context, jobID ref.UUID) (ticket.List, error) {
+	return r.filterSnapshot(jobID, func(ticket.Ticket) bool { return true })
+}
+
+func (r *ticketSnapshotRepositoryMemory) GetSnapshotByEmailAddress(_ context.Context, jobID ref.UUID, userEmail string) (ticket.List, error) {
+	return r.filterSnapshot(jobID, func(t ticket.Ticket) bool { return t.UserEmail == userEmail })
+}
+
+func (r *ticketSnapshotRepositoryMemory) GetSnapshotByChannelID(_ context.Context, jobID ref.UUID, channelID string) (ticket.List, error) {
+	return r.filterSnapshot(jobID, func(t ticket.Ticket) bool { return t.ChannelID == channelID })
+}
+
+func (r *ticketSnapshotRepositoryMemory) DeleteExpiredSnapshots(_ context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.retention == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	expiration := r.clock.Now().Add(-r.retention)
+
+	for jobID, s := range r.snapshots {
+		if s.createdAt.Before(expiration) {
+			deleted += int64(len(s.tickets))
+			delete(r.snapshots, jobID)
+		}
+	}
+
+	return deleted, nil
+}
+
+func (r *ticketSnapshotRepositoryMemory) filterSnapshot(jobID ref.UUID, filter func(ticket.Ticket) bool) (ticket.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.snapshots[jobID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	var list ticket.List
+	for _, t := range s.tickets {
+		if filter(t) {
+			list = append(list, t)
+		}
+	}
+
+	sortByTicketType(list)
+
+	return list, nil
+}
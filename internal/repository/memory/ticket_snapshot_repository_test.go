@@ -0,0 +1,22 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	repotests "github.com/KompiTech/itsm-reporting-service/internal/repository/tests"
+)
+
+func TestTicketSnapshotRepositoryMemory_SavingAndGettingSnapshot(t *testing.T) {
+	repo := NewTicketSnapshotRepositoryMemory(mocks.NewFixedClock(), 0)
+
+	repotests.TestTicketSnapshotRepositorySavingAndGettingSnapshot(t, repo)
+}
+
+func TestTicketSnapshotRepositoryMemory_DeleteExpiredSnapshots(t *testing.T) {
+	clock := mocks.NewFixedClock()
+	repo := NewTicketSnapshotRepositoryMemory(clock, 24*time.Hour)
+
+	repotests.TestTicketSnapshotRepositoryDeleteExpiredSnapshots(t, repo, clock)
+}
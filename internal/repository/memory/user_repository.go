@@ -10,12 +10,18 @@ import (
 
 // NewUserRepositoryMemory returns new initialized user repository that keeps data in memory
 func NewUserRepositoryMemory() repository.UserRepository {
-	return &userRepositoryMemory{}
+	return &userRepositoryMemory{
+		byChannel: make(map[string]map[string]user.User),
+		channels:  make(map[string][]string),
+	}
 }
 
+// userRepositoryMemory keeps users indexed by channel ID and user ID
 type userRepositoryMemory struct {
-	users []user.User
-	mu    sync.Mutex
+	byChannel map[string]map[string]user.User
+	// channels contains IDs of the channels of the user keyed by user ID, in the order the user was added to them
+	channels map[string][]string
+	mu       sync.Mutex
 }
 
 func (r *userRepositoryMemory) AddUserList(_ context.Context, userList user.List) error {
@@ -23,7 +29,24 @@ func (r *userRepositoryMemory) AddUserList(_ context.Context, userList user.List
 	defer r.mu.Unlock()
 
 	for _, u := range userList {
-		r.users = append(r.users, u)
+		r.add(u)
+	}
+
+	return nil
+}
+
+func (r *userRepositoryMemory) ReplaceChannelUsers(_ context.Context, channelID string, userList user.List) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for userID := range r.byChannel[channelID] {
+		r.removeChannel(userID, channelID)
+	}
+	delete(r.byChannel, channelID)
+
+	for _, u := range userList {
+		u.ChannelID = channelID
+		r.add(u)
 	}
 
 	return nil
@@ -33,20 +56,68 @@ func (r *userRepositoryMemory) GetUserInChannel(_ context.Context, channelID, us
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for _, u := range r.users {
-		if u.ChannelID == channelID && u.UserID == userID {
+	if u, ok := r.byChannel[channelID][userID]; ok {
+		return u, nil
+	}
+
+	return user.User{}, repository.ErrNotFound
+}
+
+func (r *userRepositoryMemory) GetUser(_ context.Context, userID string) (user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	channelIDs := r.channels[userID]
+	if len(channelIDs) == 0 {
+		return user.User{}, repository.ErrNotFound
+	}
+
+	for _, channelID := range channelIDs {
+		if u := r.byChannel[channelID][userID]; u.Email != "" {
 			return u, nil
 		}
 	}
 
-	return user.User{}, repository.ErrNotFound
+	return r.byChannel[channelIDs[0]][userID], nil
 }
 
 func (r *userRepositoryMemory) Truncate(_ context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.users = nil
+	r.byChannel = make(map[string]map[string]user.User)
+	r.channels = make(map[string][]string)
 
 	return nil
 }
+
+// add stores the user, the user already stored in the same channel is replaced
+func (r *userRepositoryMemory) add(u user.User) {
+	users, ok := r.byChannel[u.ChannelID]
+	if !ok {
+		users = make(map[string]user.User)
+		r.byChannel[u.ChannelID] = users
+	}
+
+	if _, ok := users[u.UserID]; !ok {
+		r.channels[u.UserID] = append(r.channels[u.UserID], u.ChannelID)
+	}
+	users[u.UserID] = u
+}
+
+// removeChannel removes the channel from the index of the user's channels
+func (r *userRepositoryMemory) removeChannel(userID, channelID string) {
+	channelIDs := r.channels[userID]
+	for i, id := range channelIDs {
+		if id == channelID {
+			channelIDs = append(channelIDs[:i:i], channelIDs[i+1:]...)
+			break
+		}
+	}
+
+	if len(channelIDs) == 0 {
+		delete(r.channels, userID)
+		return
+	}
+	r.channels[userID] = channelIDs
+}
@@ -55,3 +55,82 @@ func TestUserRepositoryMemory_AddingAndGettingUsers(t *testing.T) {
 
 	assert.Equal(t, u2, retUser)
 }
+
+func TestUserRepositoryMemory_GetUser(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepositoryMemory()
+
+	userID := "c8d1b9fb-35f1-46cb-aa37-a16b96937734"
+
+	withoutEmail := user.User{
+		ChannelID: "c5bea8d9-1d90-4d90-a445-e6ce74dff4cc",
+		UserID:    userID,
+		Name:      "First User",
+	}
+	withEmail := user.User{
+		ChannelID: "8b6353c3-46ca-485d-87c3-66bc36c70d88",
+		UserID:    userID,
+		Name:      "First User",
+		Email:     "first@user.com",
+	}
+
+	err := repo.AddUserList(ctx, user.List{withoutEmail})
+	require.NoError(t, err)
+
+	retUser, err := repo.GetUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, withoutEmail, retUser)
+
+	err = repo.AddUserList(ctx, user.List{withEmail})
+	require.NoError(t, err)
+
+	retUser, err = repo.GetUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, withEmail, retUser, "user with email address is preferred")
+
+	_, err = repo.GetUser(ctx, "nonexistentID")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestUserRepositoryMemory_ReplaceChannelUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepositoryMemory()
+
+	channelID := "c5bea8d9-1d90-4d90-a445-e6ce74dff4cc"
+	channel2ID := "8b6353c3-46ca-485d-87c3-66bc36c70d88"
+	userID := "c8d1b9fb-35f1-46cb-aa37-a16b96937734"
+
+	inChannel1 := user.User{ChannelID: channelID, UserID: userID, Name: "First User", Email: "first@user.com"}
+	inChannel2 := user.User{ChannelID: channel2ID, UserID: userID, Name: "First User"}
+	other := user.User{ChannelID: channelID, UserID: "b599fdbe-09df-47f9-9b08-c08caccab3b1", Email: "second@user.com"}
+
+	err := repo.AddUserList(ctx, user.List{inChannel1, other, inChannel2})
+	require.NoError(t, err)
+
+	renamed := inChannel1
+	renamed.Name = "Renamed User"
+
+	err = repo.ReplaceChannelUsers(ctx, channelID, user.List{renamed})
+	require.NoError(t, err)
+
+	retUser, err := repo.GetUserInChannel(ctx, channelID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, renamed, retUser)
+
+	_, err = repo.GetUserInChannel(ctx, channelID, other.UserID)
+	require.ErrorIs(t, err, repository.ErrNotFound, "user not in the new list is removed")
+
+	_, err = repo.GetUser(ctx, other.UserID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	err = repo.ReplaceChannelUsers(ctx, channelID, nil)
+	require.NoError(t, err)
+
+	retUser, err = repo.GetUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, inChannel2, retUser, "user from the other channel is kept")
+
+	retUser, err = repo.GetUserInChannel(ctx, channel2ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, inChannel2, retUser)
+}
@@ -0,0 +1,147 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// channelConfigRepositorySQL keeps channel configurations in SQL database
+type channelConfigRepositorySQL struct {
+	clock     repository.Clock
+	db        *sql.DB
+	tableName string
+}
+
+// NewChannelConfigRepositorySQL returns new initialized channel configuration repository that keeps data in SQL database
+func NewChannelConfigRepositorySQL(clock repository.Clock, db *sql.DB) (repository.ChannelConfigRepository, error) {
+	tableName := "channel_configs"
+
+	if _, err := db.Exec(
+		"CREATE TABLE IF NOT EXISTS " + tableName + " (" +
+			"channel_id VARCHAR(100) PRIMARY KEY, " +
+			"enabled BOOLEAN NOT NULL DEFAULT true, " +
+			"display_name TEXT NOT NULL DEFAULT '', " +
+			"timezone VARCHAR(100) NOT NULL DEFAULT '', " +
+			"owner_emails JSONB, " +
+			"updated_at TIMESTAMPTZ NOT NULL" +
+			")",
+	); err != nil {
+		return nil, fmt.Errorf("error creating table %s: %v", tableName, err)
+	}
+
+	return &channelConfigRepositorySQL{
+		clock:     clock,
+		db:        db,
+		tableName: tableName,
+	}, nil
+}
+
+func (r channelConfigRepositorySQL) ListChannelConfigs(ctx context.Context) ([]channel.Config, error) {
+	configs := make([]channel.Config, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT channel_id, enabled, display_name, timezone, owner_emails FROM "+r.tableName+" ORDER BY channel_id",
+	)
+	if err != nil {
+		return configs, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		c, err := scanChannelConfig(rows)
+		if err != nil {
+			return configs, err
+		}
+
+		configs = append(configs, c)
+	}
+	if err := rows.Err(); err != nil {
+		return configs, err
+	}
+
+	return configs, nil
+}
+
+func (r channelConfigRepositorySQL) GetChannelConfig(ctx context.Context, channelID string) (channel.Config, error) {
+	c, err := scanChannelConfig(r.db.QueryRowContext(ctx,
+		"SELECT channel_id, enabled, display_name, timezone, owner_emails FROM "+r.tableName+" WHERE channel_id = $1",
+		channelID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "no configuration of channel '%s'", channelID)
+		}
+		return c, err
+	}
+
+	return c, nil
+}
+
+func (r channelConfigRepositorySQL) SaveChannelConfig(ctx context.Context, c channel.Config) error {
+	ownerEmails, err := json.Marshal(c.OwnerEmails)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO "+r.tableName+" (channel_id, enabled, display_name, timezone, owner_emails, updated_at) "+
+			"VALUES($1, $2, $3, $4, $5, $6) "+
+			"ON CONFLICT (channel_id) DO UPDATE SET "+
+			"enabled = EXCLUDED.enabled, display_name = EXCLUDED.display_name, timezone = EXCLUDED.timezone, "+
+			"owner_emails = EXCLUDED.owner_emails, updated_at = EXCLUDED.updated_at",
+		c.ChannelID,
+		c.Enabled,
+		c.DisplayName,
+		c.Timezone,
+		ownerEmails,
+		r.clock.Now(),
+	)
+
+	return err
+}
+
+func (r channelConfigRepositorySQL) DeleteChannelConfig(ctx context.Context, channelID string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM "+r.tableName+" WHERE channel_id = $1", channelID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "no configuration of channel '%s'", channelID)
+	}
+
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanChannelConfig(row rowScanner) (channel.Config, error) {
+	var c channel.Config
+	var ownerEmails []byte
+
+	if err := row.Scan(&c.ChannelID, &c.Enabled, &c.DisplayName, &c.Timezone, &ownerEmails); err != nil {
+		return c, err
+	}
+
+	if err := decodeStringList(ownerEmails, &c.OwnerEmails); err != nil {
+		return c, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode owners of channel '%s'", c.ChannelID)
+	}
+
+	return c, nil
+}
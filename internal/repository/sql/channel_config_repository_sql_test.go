@@ -0,0 +1,30 @@
+package sql
+
+import (
+	"io"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	repotests "github.com/KompiTech/itsm-reporting-service/internal/repository/tests"
+	"github.com/cockroachdb/copyist"
+	"github.com/stretchr/testify/require"
+)
+
+func newChannelConfigRepositorySQL(t *testing.T) repository.ChannelConfigRepository {
+	openDB()
+
+	repo, err := NewChannelConfigRepositorySQL(mocks.NewFixedClock(), DB)
+	require.NoError(t, err)
+
+	resetDB(DB, "channel_configs")
+
+	return repo
+}
+
+func TestChannelConfigRepositorySQL_SavingAndGettingConfigs(t *testing.T) {
+	defer func(open io.Closer) { _ = open.Close() }(copyist.Open(t))
+
+	repo := newChannelConfigRepositorySQL(t)
+	repotests.TestChannelConfigRepositorySavingAndGettingConfigs(t, repo)
+}
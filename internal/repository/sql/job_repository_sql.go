@@ -3,10 +3,12 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/KompiTech/itsm-reporting-service/internal/domain"
 	"github.com/KompiTech/itsm-reporting-service/internal/domain/job"
@@ -55,7 +57,10 @@ func NewJobRepositorySQL(clock repository.Clock, db *sql.DB, rand io.Reader) (re
 			"excel_files_generation_started_at VARCHAR(30), " +
 			"excel_files_generation_finished_at VARCHAR(30), " +
 			"emails_sending_started_at VARCHAR(30), " +
-			"emails_sending_finished_at VARCHAR(30) " +
+			"emails_sending_finished_at VARCHAR(30), " +
+			"warnings TEXT NOT NULL DEFAULT '', " +
+			"tickets_download_mode VARCHAR(30) NOT NULL DEFAULT '', " +
+			"excluded_recipients TEXT NOT NULL DEFAULT '' " +
 			")",
 	); err != nil {
 		return nil, fmt.Errorf("error creating table %s: %v", tableName, err)
@@ -68,6 +73,24 @@ func NewJobRepositorySQL(clock repository.Clock, db *sql.DB, rand io.Reader) (re
 		return nil, fmt.Errorf("error adding 'type' column to the table %s: %v", tableName, err)
 	}
 
+	if _, err := db.Exec(
+		"ALTER TABLE " + tableName + " ADD COLUMN IF NOT EXISTS warnings TEXT NOT NULL DEFAULT ''",
+	); err != nil {
+		return nil, fmt.Errorf("error adding 'warnings' column to the table %s: %v", tableName, err)
+	}
+
+	if _, err := db.Exec(
+		"ALTER TABLE " + tableName + " ADD COLUMN IF NOT EXISTS tickets_download_mode VARCHAR(30) NOT NULL DEFAULT ''",
+	); err != nil {
+		return nil, fmt.Errorf("error adding 'tickets_download_mode' column to the table %s: %v", tableName, err)
+	}
+
+	if _, err := db.Exec(
+		"ALTER TABLE " + tableName + " ADD COLUMN IF NOT EXISTS excluded_recipients TEXT NOT NULL DEFAULT ''",
+	); err != nil {
+		return nil, fmt.Errorf("error adding 'excluded_recipients' column to the table %s: %v", tableName, err)
+	}
+
 	return &jobRepositorySQL{
 		Rand:      rand,
 		clock:     clock,
@@ -80,6 +103,7 @@ func NewJobRepositorySQL(clock repository.Clock, db *sql.DB, rand io.Reader) (re
 			"tickets_download_started_at", "tickets_download_finished_at",
 			"excel_files_generation_started_at", "excel_files_generation_finished_at",
 			"emails_sending_started_at", "emails_sending_finished_at",
+			"warnings", "tickets_download_mode", "excluded_recipients",
 		},
 	}, nil
 }
@@ -92,8 +116,18 @@ func (r jobRepositorySQL) AddJob(ctx context.Context, job job.Job) (ref.UUID, er
 
 	now := r.clock.NowFormatted().String()
 
+	warnings, err := encodeList(job.Warnings)
+	if err != nil {
+		return jobID, err
+	}
+
+	excludedRecipients, err := encodeList(job.ExcludedRecipients)
+	if err != nil {
+		return jobID, err
+	}
+
 	_, err = r.db.ExecContext(ctx,
-		"INSERT INTO "+r.tableName+" ("+r.tableFields()+") VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+		"INSERT INTO "+r.tableName+" ("+r.tableFields()+") VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)",
 		jobID,
 		job.Type.String(),
 		now,
@@ -108,6 +142,9 @@ func (r jobRepositorySQL) AddJob(ctx context.Context, job job.Job) (ref.UUID, er
 		job.ExcelFilesGenerationFinishedAt,
 		job.EmailsSendingStartedAt,
 		job.EmailsSendingFinishedAt,
+		warnings,
+		job.TicketsDownloadMode,
+		excludedRecipients,
 	)
 	if err != nil {
 		return jobID, err
@@ -124,7 +161,18 @@ func (r jobRepositorySQL) UpdateJob(ctx context.Context, job job.Job) (ref.UUID,
 		"users_download_started_at = $5, users_download_finished_at = $6, " +
 		"tickets_download_started_at = $7, tickets_download_finished_at = $8, " +
 		"excel_files_generation_started_at = $9, excel_files_generation_finished_at = $10, " +
-		"emails_sending_started_at = $11, emails_sending_finished_at = $12"
+		"emails_sending_started_at = $11, emails_sending_finished_at = $12, " +
+		"warnings = $13, tickets_download_mode = $14, excluded_recipients = $15"
+
+	warnings, err := encodeList(job.Warnings)
+	if err != nil {
+		return jobID, err
+	}
+
+	excludedRecipients, err := encodeList(job.ExcludedRecipients)
+	if err != nil {
+		return jobID, err
+	}
 
 	stmt, err := r.db.PrepareContext(ctx, "UPDATE "+r.tableName+" SET "+updateStr+" WHERE uuid = $1")
 	if err != nil {
@@ -144,6 +192,9 @@ func (r jobRepositorySQL) UpdateJob(ctx context.Context, job job.Job) (ref.UUID,
 		job.ExcelFilesGenerationFinishedAt,
 		job.EmailsSendingStartedAt,
 		job.EmailsSendingFinishedAt,
+		warnings,
+		job.TicketsDownloadMode,
+		excludedRecipients,
 	)
 	if err != nil {
 		return jobID, err
@@ -156,6 +207,7 @@ func (r jobRepositorySQL) GetJob(ctx context.Context, ID ref.UUID) (job.Job, err
 	var j job.Job
 	var uuid ref.UUID
 	var typ string
+	var warnings, excludedRecipients string
 	var err error
 
 	if err := r.db.QueryRowContext(ctx, "SELECT "+r.tableFields()+" FROM "+r.tableName+" WHERE uuid = $1", ID).Scan(
@@ -173,6 +225,9 @@ func (r jobRepositorySQL) GetJob(ctx context.Context, ID ref.UUID) (job.Job, err
 		&j.ExcelFilesGenerationFinishedAt,
 		&j.EmailsSendingStartedAt,
 		&j.EmailsSendingFinishedAt,
+		&warnings,
+		&j.TicketsDownloadMode,
+		&excludedRecipients,
 	); err != nil {
 		notFound := errors.Is(err, sql.ErrNoRows)
 		if notFound {
@@ -187,6 +242,14 @@ func (r jobRepositorySQL) GetJob(ctx context.Context, ID ref.UUID) (job.Job, err
 		return j, err
 	}
 
+	if j.Warnings, err = decodeList(warnings); err != nil {
+		return j, err
+	}
+
+	if j.ExcludedRecipients, err = decodeList(excludedRecipients); err != nil {
+		return j, err
+	}
+
 	if err := j.SetUUID(uuid); err != nil {
 		return j, err
 	}
@@ -198,6 +261,7 @@ func (r jobRepositorySQL) GetLastJob(ctx context.Context) (job.Job, error) {
 	var j job.Job
 	var uuid ref.UUID
 	var typ string
+	var warnings, excludedRecipients string
 	var err error
 
 	if err := r.db.QueryRowContext(ctx,
@@ -217,6 +281,9 @@ func (r jobRepositorySQL) GetLastJob(ctx context.Context) (job.Job, error) {
 		&j.ExcelFilesGenerationFinishedAt,
 		&j.EmailsSendingStartedAt,
 		&j.EmailsSendingFinishedAt,
+		&warnings,
+		&j.TicketsDownloadMode,
+		&excludedRecipients,
 	); err != nil {
 		notFound := errors.Is(err, sql.ErrNoRows)
 		if notFound {
@@ -231,6 +298,89 @@ func (r jobRepositorySQL) GetLastJob(ctx context.Context) (job.Job, error) {
 		return j, err
 	}
 
+	if j.Warnings, err = decodeList(warnings); err != nil {
+		return j, err
+	}
+
+	if j.ExcludedRecipients, err = decodeList(excludedRecipients); err != nil {
+		return j, err
+	}
+
+	if err := j.SetUUID(uuid); err != nil {
+		return j, err
+	}
+
+	return j, nil
+}
+
+func (r jobRepositorySQL) GetLastSuccessfulJob(ctx context.Context) (job.Job, error) {
+	return r.getLastJobWhere(ctx, "no successful job in repository", "final_status = $1", job.StatusSuccess)
+}
+
+func (r jobRepositorySQL) GetLastSuccessfulJobBefore(ctx context.Context, before time.Time) (job.Job, error) {
+	// created_at is stored as RFC 3339 text in the clock's time zone, so it is compared in the same format
+	beforeFormatted := before.In(r.clock.Now().Location()).Format(time.RFC3339)
+	return r.getLastJobWhere(ctx, fmt.Sprintf("no successful job before %s in repository", beforeFormatted),
+		"final_status = $1 AND created_at < $2", job.StatusSuccess, beforeFormatted,
+	)
+}
+
+func (r jobRepositorySQL) GetLastFullDownloadJob(ctx context.Context) (job.Job, error) {
+	return r.getLastJobWhere(ctx, "no successful job with full ticket download in repository",
+		"final_status = $1 AND tickets_download_mode = $2", job.StatusSuccess, job.TicketsDownloadFull,
+	)
+}
+
+// getLastJobWhere returns the last inserted job matching the condition, notFoundMsg describes ErrNotFound error
+func (r jobRepositorySQL) getLastJobWhere(ctx context.Context, notFoundMsg, condition string, args ...interface{}) (job.Job, error) {
+	var j job.Job
+	var uuid ref.UUID
+	var typ string
+	var warnings, excludedRecipients string
+	var err error
+
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT "+r.tableFields()+" FROM "+
+			r.tableName+" WHERE "+condition+" ORDER BY created_at DESC LIMIT 1", args...).Scan(
+		&uuid,
+		&typ,
+		&j.CreatedAt,
+		&j.FinalStatus,
+		&j.ChannelsDownloadStartedAt,
+		&j.ChannelsDownloadFinishedAt,
+		&j.UsersDownloadStartedAt,
+		&j.UsersDownloadFinishedAt,
+		&j.TicketsDownloadStartedAt,
+		&j.TicketsDownloadFinishedAt,
+		&j.ExcelFilesGenerationStartedAt,
+		&j.ExcelFilesGenerationFinishedAt,
+		&j.EmailsSendingStartedAt,
+		&j.EmailsSendingFinishedAt,
+		&warnings,
+		&j.TicketsDownloadMode,
+		&excludedRecipients,
+	); err != nil {
+		notFound := errors.Is(err, sql.ErrNoRows)
+		if notFound {
+			return j, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, notFoundMsg)
+		}
+		// Something else went wrong!
+		return j, err
+	}
+
+	j.Type, err = job.NewTypeFromString(typ)
+	if err != nil {
+		return j, err
+	}
+
+	if j.Warnings, err = decodeList(warnings); err != nil {
+		return j, err
+	}
+
+	if j.ExcludedRecipients, err = decodeList(excludedRecipients); err != nil {
+		return j, err
+	}
+
 	if err := j.SetUUID(uuid); err != nil {
 		return j, err
 	}
@@ -255,6 +405,7 @@ func (r jobRepositorySQL) ListJobs(ctx context.Context, page, perPage uint) ([]j
 		var j job.Job
 		var uuid ref.UUID
 		var typ string
+		var warnings, excludedRecipients string
 
 		if err := rows.Scan(
 			&uuid,
@@ -271,6 +422,9 @@ func (r jobRepositorySQL) ListJobs(ctx context.Context, page, perPage uint) ([]j
 			&j.ExcelFilesGenerationFinishedAt,
 			&j.EmailsSendingStartedAt,
 			&j.EmailsSendingFinishedAt,
+			&warnings,
+			&j.TicketsDownloadMode,
+			&excludedRecipients,
 		); err != nil {
 			return list, err
 		}
@@ -280,6 +434,14 @@ func (r jobRepositorySQL) ListJobs(ctx context.Context, page, perPage uint) ([]j
 			return list, err
 		}
 
+		if j.Warnings, err = decodeList(warnings); err != nil {
+			return list, err
+		}
+
+		if j.ExcludedRecipients, err = decodeList(excludedRecipients); err != nil {
+			return list, err
+		}
+
 		if err := j.SetUUID(uuid); err != nil {
 			return list, err
 		}
@@ -296,3 +458,31 @@ func (r jobRepositorySQL) ListJobs(ctx context.Context, page, perPage uint) ([]j
 func (r jobRepositorySQL) tableFields() string {
 	return strings.Join(r.fields, ", ")
 }
+
+// encodeList returns list of the job (e.g. warnings) encoded as JSON array, or empty string if the list is empty
+func encodeList(list []string) (string, error) {
+	if len(list) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// decodeList returns list of the job decoded from the value stored by encodeList
+func decodeList(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
@@ -21,13 +21,8 @@ func init() {
 // DB is a shared database handle
 var DB *sql.DB
 
-func resetDB(db *sql.DB) {
-	if _, err := db.Exec("TRUNCATE jobs"); err != nil {
-		panic(err)
-	}
-}
-
-func newJobRepositorySQL(t *testing.T) (repository.JobRepository, *mocks.FixedClock) {
+// openDB opens the shared database handle if it is not open yet
+func openDB() {
 	connStr := os.Getenv("TEST_DB_CONNECTION_STRING") // postgresql://root@localhost:26257?sslmode=disable
 
 	if DB == nil {
@@ -37,6 +32,19 @@ func newJobRepositorySQL(t *testing.T) (repository.JobRepository, *mocks.FixedCl
 			panic(err)
 		}
 	}
+}
+
+// resetDB removes all rows from the tables, tables referencing other tables go first
+func resetDB(db *sql.DB, tables ...string) {
+	for _, table := range tables {
+		if _, err := db.Exec("DELETE FROM " + table); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func newJobRepositorySQL(t *testing.T) (repository.JobRepository, *mocks.FixedClock) {
+	openDB()
 
 	clock := mocks.NewFixedClock()
 
@@ -49,7 +57,7 @@ func newJobRepositorySQL(t *testing.T) (repository.JobRepository, *mocks.FixedCl
 	repo, err := NewJobRepositorySQL(clock, DB, rand)
 	require.NoError(t, err)
 
-	resetDB(DB)
+	resetDB(DB, "jobs")
 
 	return repo, clock
 }
@@ -81,3 +89,24 @@ func TestJobRepositorySQL_GetLastJob(t *testing.T) {
 	repo, clock := newJobRepositorySQL(t)
 	repotests.TestJobRepositoryGetLastJob(t, repo, clock)
 }
+
+func TestJobRepositorySQL_GetLastSuccessfulJob(t *testing.T) {
+	defer func(open io.Closer) { _ = open.Close() }(copyist.Open(t))
+
+	repo, clock := newJobRepositorySQL(t)
+	repotests.TestJobRepositoryGetLastSuccessfulJob(t, repo, clock)
+}
+
+func TestJobRepositorySQL_GetLastSuccessfulJobBefore(t *testing.T) {
+	defer func(open io.Closer) { _ = open.Close() }(copyist.Open(t))
+
+	repo, clock := newJobRepositorySQL(t)
+	repotests.TestJobRepositoryGetLastSuccessfulJobBefore(t, repo, clock)
+}
+
+func TestJobRepositorySQL_GetLastFullDownloadJob(t *testing.T) {
+	defer func(open io.Closer) { _ = open.Close() }(copyist.Open(t))
+
+	repo, clock := newJobRepositorySQL(t)
+	repotests.TestJobRepositoryGetLastFullDownloadJob(t, repo, clock)
+}
@@ -0,0 +1,135 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// preferencesRepositorySQL keeps preferences of the report recipients in SQL database
+type preferencesRepositorySQL struct {
+	clock     repository.Clock
+	db        *sql.DB
+	tableName string
+	fields    []string
+}
+
+// NewPreferencesRepositorySQL returns new initialized preferences repository that keeps data in SQL database
+func NewPreferencesRepositorySQL(clock repository.Clock, db *sql.DB) (repository.PreferencesRepository, error) {
+	tableName := "recipient_preferences"
+
+	if _, err := db.Exec(
+		"CREATE TABLE IF NOT EXISTS " + tableName + " (" +
+			"email VARCHAR(320) PRIMARY KEY, " +
+			"frequency VARCHAR(30) NOT NULL, " +
+			"weekday INT NOT NULL DEFAULT 1, " +
+			"channels JSONB, " +
+			"record_types JSONB, " +
+			"language VARCHAR(10) NOT NULL, " +
+			"opted_out BOOLEAN NOT NULL DEFAULT false, " +
+			"updated_at TIMESTAMPTZ NOT NULL" +
+			")",
+	); err != nil {
+		return nil, fmt.Errorf("error creating table %s: %v", tableName, err)
+	}
+
+	return &preferencesRepositorySQL{
+		clock:     clock,
+		db:        db,
+		tableName: tableName,
+		fields:    []string{"email", "frequency", "weekday", "channels", "record_types", "language", "opted_out", "updated_at"},
+	}, nil
+}
+
+func (r preferencesRepositorySQL) GetPreferences(ctx context.Context, email string) (preferences.Preferences, error) {
+	var p preferences.Preferences
+	var frequency string
+	var weekday int
+	var channels, recordTypes []byte
+
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT email, frequency, weekday, channels, record_types, language, opted_out FROM "+r.tableName+" WHERE email = $1",
+		strings.ToLower(email),
+	).Scan(
+		&p.Email,
+		&frequency,
+		&weekday,
+		&channels,
+		&recordTypes,
+		&p.Language,
+		&p.OptedOut,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return p, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "no preferences of recipient '%s'", email)
+		}
+		return p, err
+	}
+
+	p.Frequency = preferences.Frequency(frequency)
+	p.Weekday = time.Weekday(weekday)
+
+	if err := decodeStringList(channels, &p.Channels); err != nil {
+		return p, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode channels of recipient '%s'", email)
+	}
+	if err := decodeStringList(recordTypes, &p.RecordTypes); err != nil {
+		return p, domain.WrapErrorf(err, domain.ErrorCodeUnknown, "could not decode record types of recipient '%s'", email)
+	}
+
+	return p, nil
+}
+
+func (r preferencesRepositorySQL) SavePreferences(ctx context.Context, p preferences.Preferences) error {
+	channels, err := json.Marshal(p.Channels)
+	if err != nil {
+		return err
+	}
+
+	recordTypes, err := json.Marshal(p.RecordTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO "+r.tableName+" ("+strings.Join(r.fields, ", ")+") VALUES($1, $2, $3, $4, $5, $6, $7, $8) "+
+			"ON CONFLICT (email) DO UPDATE SET "+
+			"frequency = EXCLUDED.frequency, weekday = EXCLUDED.weekday, channels = EXCLUDED.channels, "+
+			"record_types = EXCLUDED.record_types, language = EXCLUDED.language, opted_out = EXCLUDED.opted_out, "+
+			"updated_at = EXCLUDED.updated_at",
+		strings.ToLower(p.Email),
+		string(p.Frequency),
+		int(p.Weekday),
+		channels,
+		recordTypes,
+		p.Language,
+		p.OptedOut,
+		r.clock.Now(),
+	)
+
+	return err
+}
+
+// decodeStringList decodes JSON array of strings, NULL is decoded as empty list
+func decodeStringList(value []byte, list *[]string) error {
+	if len(value) == 0 {
+		*list = nil
+		return nil
+	}
+
+	if err := json.Unmarshal(value, list); err != nil {
+		return err
+	}
+
+	if len(*list) == 0 {
+		*list = nil
+	}
+
+	return nil
+}
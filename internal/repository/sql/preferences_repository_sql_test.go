@@ -0,0 +1,30 @@
+package sql
+
+import (
+	"io"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	repotests "github.com/KompiTech/itsm-reporting-service/internal/repository/tests"
+	"github.com/cockroachdb/copyist"
+	"github.com/stretchr/testify/require"
+)
+
+func newPreferencesRepositorySQL(t *testing.T) repository.PreferencesRepository {
+	openDB()
+
+	repo, err := NewPreferencesRepositorySQL(mocks.NewFixedClock(), DB)
+	require.NoError(t, err)
+
+	resetDB(DB, "recipient_preferences")
+
+	return repo
+}
+
+func TestPreferencesRepositorySQL_SavingAndGettingPreferences(t *testing.T) {
+	defer func(open io.Closer) { _ = open.Close() }(copyist.Open(t))
+
+	repo := newPreferencesRepositorySQL(t)
+	repotests.TestPreferencesRepositorySavingAndGettingPreferences(t, repo)
+}
@@ -0,0 +1,320 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+)
+
+// ticketSnapshotRepositorySQL keeps tickets downloaded by the jobs in SQL database
+type ticketSnapshotRepositorySQL struct {
+	clock          repository.Clock
+	db             *sql.DB
+	retention      time.Duration
+	tableName      string
+	itemsTableName string
+	fields         []string
+}
+
+// NewTicketSnapshotRepositorySQL returns new initialized ticket snapshot repository that keeps data in SQL database.
+// Snapshots older than retention are removed by DeleteExpiredSnapshots, zero retention keeps snapshots forever.
+func NewTicketSnapshotRepositorySQL(clock repository.Clock, db *sql.DB, retention time.Duration) (repository.TicketSnapshotRepository, error) {
+	tableName := "ticket_snapshots"
+	itemsTableName := "ticket_snapshot_items"
+
+	if _, err := db.Exec(
+		"CREATE TABLE IF NOT EXISTS " + tableName + " (" +
+			"job_id UUID PRIMARY KEY, " +
+			"created_at TIMESTAMPTZ NOT NULL, " +
+			"tickets_count INT NOT NULL, " +
+			"scopes TEXT NOT NULL DEFAULT ''" +
+			")",
+	); err != nil {
+		return nil, fmt.Errorf("error creating table %s: %v", tableName, err)
+	}
+
+	if _, err := db.Exec(
+		"CREATE TABLE IF NOT EXISTS " + itemsTableName + " (" +
+			"job_id UUID NOT NULL REFERENCES " + tableName + " (job_id) ON DELETE CASCADE, " +
+			"position INT NOT NULL, " +
+			"user_id VARCHAR(64), " +
+			"user_email VARCHAR(320), " +
+			"user_name TEXT, " +
+			"user_org_name TEXT, " +
+			"channel_id VARCHAR(64) NOT NULL, " +
+			"channel_name TEXT, " +
+			"ticket_type VARCHAR(64), " +
+			"record_type VARCHAR(64), " +
+			"ticket_type_order INT NOT NULL DEFAULT 0, " +
+			"uuid VARCHAR(64), " +
+			"number VARCHAR(64), " +
+			"short_description TEXT, " +
+			"state_id INT NOT NULL DEFAULT 0, " +
+			"state VARCHAR(64), " +
+			"location TEXT, " +
+			"created_at VARCHAR(30), " +
+			"url TEXT, " +
+			"fields JSONB, " +
+			"PRIMARY KEY (job_id, position)" +
+			")",
+	); err != nil {
+		return nil, fmt.Errorf("error creating table %s: %v", itemsTableName, err)
+	}
+
+	// DB auto-migration if DB was already in use in production
+	if _, err := db.Exec(
+		"ALTER TABLE " + tableName + " ADD COLUMN IF NOT EXISTS scopes TEXT NOT NULL DEFAULT ''",
+	); err != nil {
+		return nil, fmt.Errorf("error adding 'scopes' column to the table %s: %v", tableName, err)
+	}
+
+	if _, err := db.Exec(
+		"ALTER TABLE " + itemsTableName + " ADD COLUMN IF NOT EXISTS record_type VARCHAR(64)",
+	); err != nil {
+		return nil, fmt.Errorf("error adding 'record_type' column to the table %s: %v", itemsTableName, err)
+	}
+
+	if _, err := db.Exec(
+		"CREATE INDEX IF NOT EXISTS " + itemsTableName + "_email_idx ON " + itemsTableName + " (job_id, user_email)",
+	); err != nil {
+		return nil, fmt.Errorf("error creating email index on the table %s: %v", itemsTableName, err)
+	}
+
+	if _, err := db.Exec(
+		"CREATE INDEX IF NOT EXISTS " + itemsTableName + "_channel_idx ON " + itemsTableName + " (job_id, channel_id)",
+	); err != nil {
+		return nil, fmt.Errorf("error creating channel index on the table %s: %v", itemsTableName, err)
+	}
+
+	return &ticketSnapshotRepositorySQL{
+		clock:          clock,
+		db:             db,
+		retention:      retention,
+		tableName:      tableName,
+		itemsTableName: itemsTableName,
+		fields: []string{
+			"job_id", "position",
+			"user_id", "user_email", "user_name", "user_org_name",
+			"channel_id", "channel_name",
+			"ticket_type", "record_type", "ticket_type_order",
+			"uuid", "number", "short_description", "state_id", "state", "location", "created_at", "url", "fields",
+		},
+	}, nil
+}
+
+func (r ticketSnapshotRepositorySQL) SaveSnapshot(ctx context.Context, jobID ref.UUID, ticketList ticket.List, scopes []ticket.Scope) error {
+	encodedScopes, err := json.Marshal(scopes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	// saving the snapshot again replaces the previous one
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+r.tableName+" WHERE job_id = $1", jobID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO "+r.tableName+" (job_id, created_at, tickets_count, scopes) VALUES($1, $2, $3, $4)",
+		jobID, r.clock.Now(), len(ticketList), string(encodedScopes),
+	); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO "+r.itemsTableName+" ("+r.tableFields()+") "+
+			"VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)",
+	)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for i, t := range ticketList {
+		// nil slice would be sent as an empty string, which is not a valid JSONB value, so missing fields are NULL
+		var fields interface{}
+		if t.TicketData.Fields != nil {
+			if fields, err = json.Marshal(t.TicketData.Fields); err != nil {
+				return err
+			}
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			jobID,
+			i,
+			t.UserID,
+			t.UserEmail,
+			t.UserName,
+			t.UserOrgName,
+			t.ChannelID,
+			t.ChannelName,
+			t.TicketType,
+			t.RecordType,
+			t.TicketTypeOrder,
+			t.TicketData.UUID,
+			t.TicketData.Number,
+			t.TicketData.ShortDescription,
+			t.TicketData.StateID,
+			t.TicketData.State,
+			t.TicketData.Location,
+			t.TicketData.CreatedAt,
+			t.TicketData.URL,
+			fields,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r ticketSnapshotRepositorySQL) GetSnapshot(ctx context.Context, jobID ref.UUID) (ticket.List, error) {
+	return r.querySnapshot(ctx, jobID, "", nil)
+}
+
+func (r ticketSnapshotRepositorySQL) GetSnapshotByEmailAddress(ctx context.Context, jobID ref.UUID, userEmail string) (ticket.List, error) {
+	return r.querySnapshot(ctx, jobID, "user_email = $2", userEmail)
+}
+
+func (r ticketSnapshotRepositorySQL) GetSnapshotByChannelID(ctx context.Context, jobID ref.UUID, channelID string) (ticket.List, error) {
+	return r.querySnapshot(ctx, jobID, "channel_id = $2", channelID)
+}
+
+func (r ticketSnapshotRepositorySQL) GetSnapshotScopes(ctx context.Context, jobID ref.UUID) ([]ticket.Scope, error) {
+	var scopes []ticket.Scope
+
+	var encodedScopes string
+	if err := r.db.QueryRowContext(ctx, "SELECT scopes FROM "+r.tableName+" WHERE job_id = $1", jobID).Scan(&encodedScopes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return scopes, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "error loading ticket snapshot from repository")
+		}
+		// Something else went wrong!
+		return scopes, err
+	}
+
+	// snapshots saved before the scopes were recorded cover nothing
+	if encodedScopes == "" {
+		return scopes, nil
+	}
+
+	if err := json.Unmarshal([]byte(encodedScopes), &scopes); err != nil {
+		return scopes, err
+	}
+
+	return scopes, nil
+}
+
+func (r ticketSnapshotRepositorySQL) DeleteExpiredSnapshots(ctx context.Context) (int64, error) {
+	if r.retention == 0 {
+		return 0, nil
+	}
+
+	var deleted sql.NullInt64
+	expiration := r.clock.Now().Add(-r.retention)
+
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT SUM(tickets_count) FROM "+r.tableName+" WHERE created_at < $1", expiration,
+	).Scan(&deleted); err != nil {
+		return 0, err
+	}
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM "+r.tableName+" WHERE created_at < $1", expiration); err != nil {
+		return 0, err
+	}
+
+	return deleted.Int64, nil
+}
+
+// querySnapshot returns tickets of the job snapshot filtered by the optional condition with one parameter
+func (r ticketSnapshotRepositorySQL) querySnapshot(ctx context.Context, jobID ref.UUID, condition string, param interface{}) (ticket.List, error) {
+	var list ticket.List
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT true FROM "+r.tableName+" WHERE job_id = $1", jobID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return list, domain.WrapErrorf(repository.ErrNotFound, domain.ErrorCodeNotFound, "error loading ticket snapshot from repository")
+		}
+		// Something else went wrong!
+		return list, err
+	}
+
+	query := "SELECT " + r.tableFields() + " FROM " + r.itemsTableName + " WHERE job_id = $1"
+	args := []interface{}{jobID}
+	if condition != "" {
+		query += " AND " + condition
+		args = append(args, param)
+	}
+	query += " ORDER BY ticket_type_order, ticket_type, position"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return list, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var t ticket.Ticket
+		var snapshotJobID ref.UUID
+		var position int
+		var fields []byte
+
+		if err := rows.Scan(
+			&snapshotJobID,
+			&position,
+			&t.UserID,
+			&t.UserEmail,
+			&t.UserName,
+			&t.UserOrgName,
+			&t.ChannelID,
+			&t.ChannelName,
+			&t.TicketType,
+			&t.RecordType,
+			&t.TicketTypeOrder,
+			&t.TicketData.UUID,
+			&t.TicketData.Number,
+			&t.TicketData.ShortDescription,
+			&t.TicketData.StateID,
+			&t.TicketData.State,
+			&t.TicketData.Location,
+			&t.TicketData.CreatedAt,
+			&t.TicketData.URL,
+			&fields,
+		); err != nil {
+			return list, err
+		}
+
+		if len(fields) > 0 {
+			if err := json.Unmarshal(fields, &t.TicketData.Fields); err != nil {
+				return list, err
+			}
+		}
+
+		list = append(list, t)
+	}
+	if err := rows.Err(); err != nil {
+		return list, err
+	}
+
+	return list, nil
+}
+
+func (r ticketSnapshotRepositorySQL) tableFields() string {
+	return strings.Join(r.fields, ", ")
+}
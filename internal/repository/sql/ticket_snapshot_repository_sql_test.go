@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	repotests "github.com/KompiTech/itsm-reporting-service/internal/repository/tests"
+	"github.com/cockroachdb/copyist"
+	"github.com/stretchr/testify/require"
+)
+
+func newTicketSnapshotRepositorySQL(t *testing.T, retention time.Duration) (repository.TicketSnapshotRepository, *mocks.FixedClock) {
+	openDB()
+
+	clock := mocks.NewFixedClock()
+
+	repo, err := NewTicketSnapshotRepositorySQL(clock, DB, retention)
+	require.NoError(t, err)
+
+	resetDB(DB, "ticket_snapshot_items", "ticket_snapshots")
+
+	return repo, clock
+}
+
+func TestTicketSnapshotRepositorySQL_SavingAndGettingSnapshot(t *testing.T) {
+	defer func(open io.Closer) { _ = open.Close() }(copyist.Open(t))
+
+	repo, _ := newTicketSnapshotRepositorySQL(t, 0)
+	repotests.TestTicketSnapshotRepositorySavingAndGettingSnapshot(t, repo)
+}
+
+func TestTicketSnapshotRepositorySQL_DeleteExpiredSnapshots(t *testing.T) {
+	defer func(open io.Closer) { _ = open.Close() }(copyist.Open(t))
+
+	repo, clock := newTicketSnapshotRepositorySQL(t, 24*time.Hour)
+	repotests.TestTicketSnapshotRepositoryDeleteExpiredSnapshots(t, repo, clock)
+}
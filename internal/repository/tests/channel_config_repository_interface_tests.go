@@ -0,0 +1,56 @@
+package repotests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/channel"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelConfigRepositorySavingAndGettingConfigs(t *testing.T, repo repository.ChannelConfigRepository) {
+	ctx := context.Background()
+
+	_, err := repo.GetChannelConfig(ctx, "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	first := channel.Config{
+		ChannelID:   "e0ef342a-2d3a-4d1c-8f7a-6b3c2a1d0e01",
+		Enabled:     true,
+		DisplayName: "First channel",
+		Timezone:    "Europe/Prague",
+		OwnerEmails: []string{"owner@email.test", "deputy@email.test"},
+	}
+	second := channel.Config{
+		ChannelID: "6abf417c-52e3-4340-9713-df2f37e78176",
+		Enabled:   false,
+	}
+	require.NoError(t, repo.SaveChannelConfig(ctx, first))
+	require.NoError(t, repo.SaveChannelConfig(ctx, second))
+
+	retConfig, err := repo.GetChannelConfig(ctx, first.ChannelID)
+	require.NoError(t, err)
+	assert.Equal(t, first, retConfig)
+
+	configs, err := repo.ListChannelConfigs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []channel.Config{second, first}, configs, "configs are ordered by channel ID")
+
+	first.OwnerEmails = nil
+	first.Enabled = false
+	require.NoError(t, repo.SaveChannelConfig(ctx, first))
+
+	retConfig, err = repo.GetChannelConfig(ctx, first.ChannelID)
+	require.NoError(t, err)
+	assert.False(t, retConfig.Enabled)
+	assert.Empty(t, retConfig.OwnerEmails)
+
+	require.NoError(t, repo.DeleteChannelConfig(ctx, first.ChannelID))
+	require.ErrorIs(t, repo.DeleteChannelConfig(ctx, first.ChannelID), repository.ErrNotFound)
+
+	configs, err = repo.ListChannelConfigs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []channel.Config{second}, configs)
+}
@@ -50,6 +50,8 @@ func TestJobRepositoryUpdateJob(t *testing.T, repo repository.JobRepository) {
 	require.NoError(t, err)
 
 	retJob.FinalStatus = "success"
+	retJob.Warnings = []string{"channel 'First channel': tickets download failed", "user 'Joe' has no email"}
+	retJob.ExcludedRecipients = []string{"api@user.test"}
 	retJobCreatedAt := retJob.CreatedAt
 	retJob.CreatedAt = "some changed value"
 
@@ -65,6 +67,8 @@ func TestJobRepositoryUpdateJob(t *testing.T, repo repository.JobRepository) {
 
 	assert.Equal(t, jobID, updatedJob.UUID())
 	assert.Equal(t, "success", updatedJob.FinalStatus)
+	assert.Equal(t, retJob.Warnings, updatedJob.Warnings)
+	assert.Equal(t, retJob.ExcludedRecipients, updatedJob.ExcludedRecipients)
 	assert.Equal(t, retJobCreatedAt, updatedJob.CreatedAt) // this should not be changed
 }
 
@@ -130,3 +134,126 @@ func TestJobRepositoryGetLastJob(t *testing.T, repo repository.JobRepository, cl
 	assert.Equal(t, job1.Type, retJob.Type)
 
 }
+
+func TestJobRepositoryGetLastSuccessfulJob(t *testing.T, repo repository.JobRepository, clock *mocks.FixedClock) {
+	ctx := context.Background()
+
+	_, err := repo.GetLastSuccessfulJob(ctx)
+	// there are no jobs yet, it should return error
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	var jobIDs []ref.UUID
+	for i := 0; i < 4; i++ {
+		clock.AddTime(10 * time.Second)
+		jobID, err := repo.AddJob(ctx, job.Job{Type: job.TypeAll, TicketsDownloadMode: job.TicketsDownloadFull})
+		require.NoError(t, err)
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	_, err = repo.GetLastSuccessfulJob(ctx)
+	// no job finished successfully yet, it should return error
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	// 2nd job succeeded, 3rd job failed, 4th job is still running
+	successfulJob, err := repo.GetJob(ctx, jobIDs[1])
+	require.NoError(t, err)
+	assert.Equal(t, job.TicketsDownloadFull, successfulJob.TicketsDownloadMode, "requested download mode is stored")
+	successfulJob.FinalStatus = job.StatusSuccess
+	successfulJob.TicketsDownloadMode = job.TicketsDownloadIncremental
+	successfulJob.Warnings = []string{"assignee 'c8d1b9fb' of 1 ticket(s) in channel 'First channel' not found in the user directory"}
+	successfulJob.ExcludedRecipients = []string{"api@service.test: user type 'api' is not allowed"}
+	_, err = repo.UpdateJob(ctx, successfulJob)
+	require.NoError(t, err)
+
+	j, err := repo.GetJob(ctx, jobIDs[2])
+	require.NoError(t, err)
+	j.FinalStatus = "Error: something went wrong"
+	_, err = repo.UpdateJob(ctx, j)
+	require.NoError(t, err)
+
+	retJob, err := repo.GetLastSuccessfulJob(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, jobIDs[1], retJob.UUID())
+	assert.Equal(t, job.StatusSuccess, retJob.FinalStatus)
+	assert.Equal(t, successfulJob.Warnings, retJob.Warnings)
+	assert.Equal(t, successfulJob.ExcludedRecipients, retJob.ExcludedRecipients)
+	assert.Equal(t, job.TicketsDownloadIncremental, retJob.TicketsDownloadMode)
+}
+
+func TestJobRepositoryGetLastSuccessfulJobBefore(t *testing.T, repo repository.JobRepository, clock *mocks.FixedClock) {
+	ctx := context.Background()
+
+	// jobs created a day apart, the 3rd one failed
+	statuses := []string{job.StatusSuccess, job.StatusSuccess, "Error: something went wrong", job.StatusSuccess}
+
+	var jobIDs []ref.UUID
+	var createdAt []time.Time
+	for _, status := range statuses {
+		clock.AddTime(24 * time.Hour)
+		jobID, err := repo.AddJob(ctx, job.Job{Type: job.TypeAll})
+		require.NoError(t, err)
+		jobIDs = append(jobIDs, jobID)
+
+		j, err := repo.GetJob(ctx, jobID)
+		require.NoError(t, err)
+		created, err := j.CreatedAt.ToTime()
+		require.NoError(t, err)
+		createdAt = append(createdAt, created)
+
+		j.FinalStatus = status
+		_, err = repo.UpdateJob(ctx, j)
+		require.NoError(t, err)
+	}
+
+	_, err := repo.GetLastSuccessfulJobBefore(ctx, createdAt[0])
+	// no job created before the 1st one, it should return error
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	retJob, err := repo.GetLastSuccessfulJobBefore(ctx, createdAt[1].Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, jobIDs[1], retJob.UUID())
+
+	// the failed job is skipped
+	retJob, err = repo.GetLastSuccessfulJobBefore(ctx, createdAt[3])
+	require.NoError(t, err)
+	assert.Equal(t, jobIDs[1], retJob.UUID())
+
+	// the time zone of the time does not matter
+	retJob, err = repo.GetLastSuccessfulJobBefore(ctx, createdAt[3].Add(time.Second).UTC())
+	require.NoError(t, err)
+	assert.Equal(t, jobIDs[3], retJob.UUID())
+}
+
+func TestJobRepositoryGetLastFullDownloadJob(t *testing.T, repo repository.JobRepository, clock *mocks.FixedClock) {
+	ctx := context.Background()
+
+	_, err := repo.GetLastFullDownloadJob(ctx)
+	// there are no jobs yet, it should return error
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	// 1st job downloaded all tickets, 2nd job the updated ones, 3rd job failed during the full download
+	modes := []string{job.TicketsDownloadFull, job.TicketsDownloadIncremental, job.TicketsDownloadFull}
+	statuses := []string{job.StatusSuccess, job.StatusSuccess, "Error: something went wrong"}
+
+	var jobIDs []ref.UUID
+	for i := range modes {
+		clock.AddTime(10 * time.Second)
+		jobID, err := repo.AddJob(ctx, job.Job{Type: job.TypeAll})
+		require.NoError(t, err)
+		jobIDs = append(jobIDs, jobID)
+
+		j, err := repo.GetJob(ctx, jobID)
+		require.NoError(t, err)
+		j.TicketsDownloadMode = modes[i]
+		j.FinalStatus = statuses[i]
+		_, err = repo.UpdateJob(ctx, j)
+		require.NoError(t, err)
+	}
+
+	retJob, err := repo.GetLastFullDownloadJob(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, jobIDs[0], retJob.UUID())
+	assert.Equal(t, job.TicketsDownloadFull, retJob.TicketsDownloadMode)
+}
@@ -0,0 +1,43 @@
+package repotests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/preferences"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferencesRepositorySavingAndGettingPreferences(t *testing.T, repo repository.PreferencesRepository) {
+	ctx := context.Background()
+
+	_, err := repo.GetPreferences(ctx, "first@user.com")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	p := preferences.Preferences{
+		Email:       "first@user.com",
+		Frequency:   preferences.FrequencyWeekly,
+		Weekday:     time.Friday,
+		Channels:    []string{"6abf417c-52e3-4340-9713-df2f37e78176"},
+		RecordTypes: []string{"incident", "k_request"},
+		Language:    "cs",
+	}
+	require.NoError(t, repo.SavePreferences(ctx, p))
+
+	retPrefs, err := repo.GetPreferences(ctx, "First@User.com")
+	require.NoError(t, err)
+	assert.Equal(t, p, retPrefs)
+
+	p.OptedOut = true
+	p.Channels = nil
+	require.NoError(t, repo.SavePreferences(ctx, p))
+
+	retPrefs, err = repo.GetPreferences(ctx, p.Email)
+	require.NoError(t, err)
+	assert.True(t, retPrefs.OptedOut)
+	assert.Empty(t, retPrefs.Channels)
+	assert.Equal(t, p.RecordTypes, retPrefs.RecordTypes)
+}
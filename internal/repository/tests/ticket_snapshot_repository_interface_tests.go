@@ -0,0 +1,136 @@
+package repotests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ref"
+	"github.com/KompiTech/itsm-reporting-service/internal/domain/ticket"
+	"github.com/KompiTech/itsm-reporting-service/internal/mocks"
+	"github.com/KompiTech/itsm-reporting-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketSnapshotRepositorySavingAndGettingSnapshot(t *testing.T, repo repository.TicketSnapshotRepository) {
+	ctx := context.Background()
+
+	jobID := ref.UUID("c0582f65-4c7d-469f-a3a4-42360f287074")
+	channel1ID := "6abf417c-52e3-4340-9713-df2f37e78176"
+	channel2ID := "75412c30-9f88-4b0e-a7c3-acfffe5f128b"
+
+	req1 := ticket.Ticket{
+		UserEmail:       "first@user.com",
+		ChannelID:       channel1ID,
+		ChannelName:     "Some Channel",
+		TicketType:      "REQUEST",
+		RecordType:      "request",
+		TicketTypeOrder: 1,
+		TicketData: ticket.Data{
+			Number:  "REQ987456",
+			StateID: 2,
+			State:   "In progress",
+			Fields:  map[string]string{"priority": "3"},
+		},
+	}
+	inc1 := ticket.Ticket{
+		UserEmail:   "first@user.com",
+		ChannelID:   channel2ID,
+		ChannelName: "Other Channel",
+		TicketType:  "INCIDENT",
+		TicketData: ticket.Data{
+			Number: "INC123456",
+		},
+	}
+	inc2 := ticket.Ticket{
+		UserEmail:   "second@user.com",
+		ChannelID:   channel1ID,
+		ChannelName: "Some Channel",
+		TicketType:  "INCIDENT",
+		TicketData: ticket.Data{
+			Number: "INC999999",
+		},
+	}
+
+	_, err := repo.GetSnapshot(ctx, jobID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = repo.GetSnapshotScopes(ctx, jobID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	scopes := []ticket.Scope{
+		{ChannelID: channel1ID, RecordType: "incident"},
+		{ChannelID: channel1ID, RecordType: "request"},
+		{ChannelID: channel2ID, RecordType: "incident"},
+		{ChannelID: channel2ID, RecordType: "request"},
+	}
+	err = repo.SaveSnapshot(ctx, jobID, ticket.List{req1, inc1, inc2}, scopes)
+	require.NoError(t, err)
+
+	retScopes, err := repo.GetSnapshotScopes(ctx, jobID)
+	require.NoError(t, err)
+	assert.Equal(t, scopes, retScopes)
+
+	all, err := repo.GetSnapshot(ctx, jobID)
+	require.NoError(t, err)
+	// incidents go first
+	assert.Equal(t, ticket.List{inc1, inc2, req1}, all)
+
+	byEmail, err := repo.GetSnapshotByEmailAddress(ctx, jobID, "first@user.com")
+	require.NoError(t, err)
+	assert.Equal(t, ticket.List{inc1, req1}, byEmail)
+
+	byChannel, err := repo.GetSnapshotByChannelID(ctx, jobID, channel1ID)
+	require.NoError(t, err)
+	assert.Equal(t, ticket.List{inc2, req1}, byChannel)
+
+	// empty snapshot is still a snapshot
+	emptyJobID := ref.UUID("d6aa467b-d07d-41e0-9182-aeedb1b02398")
+	err = repo.SaveSnapshot(ctx, emptyJobID, nil, scopes[:1])
+	require.NoError(t, err)
+
+	empty, err := repo.GetSnapshot(ctx, emptyJobID)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	retScopes, err = repo.GetSnapshotScopes(ctx, emptyJobID)
+	require.NoError(t, err)
+	assert.Equal(t, scopes[:1], retScopes)
+}
+
+// TestTicketSnapshotRepositoryDeleteExpiredSnapshots expects repository with retention set to 24 hours
+func TestTicketSnapshotRepositoryDeleteExpiredSnapshots(t *testing.T, repo repository.TicketSnapshotRepository, clock *mocks.FixedClock) {
+	ctx := context.Background()
+
+	oldJobID := ref.UUID("c0582f65-4c7d-469f-a3a4-42360f287074")
+	newJobID := ref.UUID("d6aa467b-d07d-41e0-9182-aeedb1b02398")
+
+	list := ticket.List{
+		{ChannelID: "6abf417c-52e3-4340-9713-df2f37e78176", TicketData: ticket.Data{Number: "INC1"}},
+		{ChannelID: "6abf417c-52e3-4340-9713-df2f37e78176", TicketData: ticket.Data{Number: "INC2"}},
+	}
+
+	err := repo.SaveSnapshot(ctx, oldJobID, list, nil)
+	require.NoError(t, err)
+
+	clock.AddTime(20 * time.Hour)
+	err = repo.SaveSnapshot(ctx, newJobID, list[:1], nil)
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteExpiredSnapshots(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted, "nothing is older than retention yet")
+
+	clock.AddTime(5 * time.Hour)
+	deleted, err = repo.DeleteExpiredSnapshots(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted, "old snapshot should be deleted")
+
+	_, err = repo.GetSnapshot(ctx, oldJobID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	retList, err := repo.GetSnapshot(ctx, newJobID)
+	require.NoError(t, err)
+	assert.Len(t, retList, 1)
+}
@@ -0,0 +1,122 @@
+// Package tracing creates OpenTelemetry spans of the REST requests, jobs, job stages, channels and outbound calls
+// and exports them over OTLP or to stdout.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the name of the tracer of all spans of the service
+	tracerName = "github.com/KompiTech/itsm-reporting-service"
+	// serviceName is the default service name of the spans, it can be changed by OTEL_SERVICE_NAME env var
+	serviceName = "itsm-reporting-service"
+)
+
+// Attribute keys of the spans
+const (
+	JobIDKey      = attribute.Key("job.id")
+	ChannelIDKey  = attribute.Key("channel.id")
+	RecordTypeKey = attribute.Key("ticket.record_type")
+	AttemptKey    = attribute.Key("http.attempt")
+	EmailsKey     = attribute.Key("email.count")
+)
+
+// Exporter selects where the spans are exported
+type Exporter string
+
+// Exporter values
+const (
+	// ExporterNone does not record any spans
+	ExporterNone Exporter = "none"
+	// ExporterOTLP exports the spans over OTLP/HTTP, the collector is configured by the standard
+	// OTEL_EXPORTER_OTLP_* env vars (e.g. OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318)
+	ExporterOTLP Exporter = "otlp"
+	// ExporterStdout writes the spans to stdout, for local runs
+	ExporterStdout Exporter = "stdout"
+)
+
+// Init sets up the global tracer provider exporting the spans by the exporter. The returned shutdown function
+// flushes the spans that have not been exported yet.
+func Init(ctx context.Context, exporterType Exporter) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch exporterType {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter '%s'", exporterType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s trace exporter: %w", exporterType, err)
+	}
+
+	// resource attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES env vars override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceNameKey.String(serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts the span as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, the error marks the span as failed
+func End(span trace.Span, err error) {
+	Fail(span, err)
+	span.End()
+}
+
+// Fail records the error on the span and marks it as failed, nil error is ignored
+func Fail(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Inject adds the trace context of ctx to the headers of the outbound request
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract returns ctx with the trace context of the headers of the inbound request
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}